@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+type aclClient interface {
+	TokenCreate(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenUpdate(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenClone(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenDelete(tokenID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	TokenRead(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error)
+	TokenReadSelf(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error)
+	PolicyCreate(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error)
+	PolicyUpdate(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error)
+	PolicyDelete(policyID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	PolicyReadByName(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error)
+	RoleCreate(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error)
+	RoleUpdate(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error)
+	RoleDelete(roleID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	RoleReadByName(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error)
+	RulesTranslate(rules io.Reader) (string, error)
+}
+
+//ACLLink represents a link to an ACL policy or role, by ID or by name.
+type ACLLink struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+func validateACLLinks(name string, links []ACLLink) []string {
+	errors := []string{}
+	for index, link := range links {
+		if "" == link.ID && "" == link.Name {
+			errors = append(errors, fmt.Sprintf("%s[%d] requires an id or a name", name, index))
+		}
+	}
+	return errors
+}
+
+func toConsulACLLinks(links []ACLLink) []*consul.ACLLink {
+	if nil == links {
+		return nil
+	}
+	retval := make([]*consul.ACLLink, len(links))
+	for index, link := range links {
+		retval[index] = &consul.ACLLink{ID: link.ID, Name: link.Name}
+	}
+	return retval
+}
+
+func toACLLinks(links []*consul.ACLLink) []ACLLink {
+	retval := make([]ACLLink, len(links))
+	for index, link := range links {
+		retval[index] = ACLLink{ID: link.ID, Name: link.Name}
+	}
+	return retval
+}
+
+//sameACLLinks reports whether the links consul returned match the requested links,
+//a requested link only having to match on the fields it sets.
+func sameACLLinks(current []*consul.ACLLink, requested []ACLLink) bool {
+	if len(current) != len(requested) {
+		return false
+	}
+	for index, link := range requested {
+		if "" != link.ID && link.ID != current[index].ID {
+			return false
+		}
+		if "" != link.Name && link.Name != current[index].Name {
+			return false
+		}
+	}
+	return true
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+//isACLNotFound reports whether consul rejected the request because the ACL object does not exist.
+//Consul returns the same error when the token of the request does not exist, see isACLTokenNotFound.
+func isACLNotFound(err error) bool {
+	return strings.Contains(err.Error(), "ACL not found")
+}
+
+//isACLTokenNotFound reports whether the error is about the requested token rather than the token of the request,
+//which is checked by reading the token of the request when consul rejected the request with "ACL not found".
+func (c *consulClient) isACLTokenNotFound(err error, q *consul.QueryOptions) bool {
+	if nil == err || !isACLNotFound(err) {
+		return false
+	}
+	_, _, err = c.aclClient.TokenReadSelf(q)
+	return nil == err
+}
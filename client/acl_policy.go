@@ -0,0 +1,118 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+//ACLPolicyRequest represents an ACL policy to apply, identified by its name.
+//Rules are HCL and the policy is valid in every datacenter when Datacenters is empty.
+type ACLPolicyRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Rules       string   `json:"rules,omitempty"`
+	Datacenters []string `json:"datacenters,omitempty"`
+}
+
+//ACLPolicy represents a consul ACL policy.
+type ACLPolicy struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Rules       string   `json:"rules"`
+	Datacenters []string `json:"datacenters"`
+	CreateIndex uint64   `json:"createIndex"`
+	ModifyIndex uint64   `json:"modifyIndex"`
+}
+
+//UpsertACLPolicy creates the ACL policy or, when a policy with the same name exists, updates it.
+//The rules are validated by consul first, and the policy is only written when it differs from
+//the existing one, in which case the returned bool is true. The consul api version in use validates
+//the rules with the token and datacenter of the pack configuration, not with those of the request.
+func (c *consulClient) UpsertACLPolicy(request ACLPolicyRequest, options WriteOptions) (*ACLPolicy, bool, error) {
+	if "" != strings.TrimSpace(request.Rules) {
+		_, err := c.aclClient.RulesTranslate(strings.NewReader(request.Rules))
+		if nil != err && http.StatusBadRequest == statusCode(err) {
+			return nil, false, fmt.Errorf("rules are not valid: %v", err)
+		}
+		if nil != err {
+			return nil, false, fmt.Errorf("failed to validate rules with the token of the pack (CONSUL_HTTP_TOKEN), not the token of the request: %v", err)
+		}
+	}
+
+	current, _, err := c.aclClient.PolicyReadByName(request.Name, toConsistentQueryOptions(options))
+	if nil != err {
+		return nil, false, fmt.Errorf("failed to read acl policy: %v", err)
+	}
+	if nil != current && sameACLPolicy(current, request) {
+		return toACLPolicy(current), false, nil
+	}
+
+	policy := &consul.ACLPolicy{
+		Name:        request.Name,
+		Description: request.Description,
+		Rules:       request.Rules,
+		Datacenters: request.Datacenters,
+	}
+	var applied *consul.ACLPolicy
+	if nil == current {
+		applied, _, err = c.aclClient.PolicyCreate(policy, toWriteOptions(options))
+	} else {
+		policy.ID = current.ID
+		applied, _, err = c.aclClient.PolicyUpdate(policy, toWriteOptions(options))
+	}
+	if nil != err {
+		return nil, false, fmt.Errorf("failed to apply acl policy: %v", err)
+	}
+	return toACLPolicy(applied), true, nil
+}
+
+//DeleteACLPolicy deletes the ACL policy with the name. It returns false when the policy does not exist.
+func (c *consulClient) DeleteACLPolicy(name string, options WriteOptions) (bool, error) {
+	policy, _, err := c.aclClient.PolicyReadByName(name, toConsistentQueryOptions(options))
+	if nil != err {
+		return false, fmt.Errorf("failed to read acl policy: %v", err)
+	}
+	if nil == policy {
+		return false, nil
+	}
+
+	if _, err := c.aclClient.PolicyDelete(policy.ID, toWriteOptions(options)); nil != err {
+		return false, fmt.Errorf("failed to delete acl policy: %v", err)
+	}
+	return true, nil
+}
+
+func sameACLPolicy(current *consul.ACLPolicy, request ACLPolicyRequest) bool {
+	return current.Description == request.Description &&
+		current.Rules == request.Rules &&
+		sameStrings(current.Datacenters, request.Datacenters)
+}
+
+func toACLPolicy(policy *consul.ACLPolicy) *ACLPolicy {
+	return &ACLPolicy{
+		ID:          policy.ID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Rules:       policy.Rules,
+		Datacenters: policy.Datacenters,
+		CreateIndex: policy.CreateIndex,
+		ModifyIndex: policy.ModifyIndex,
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const readOnlyRules = `key_prefix "" { policy = "read" }`
+
+func TestUpsertACLPolicyCreatesPolicy(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RulesTranslateFunc = func(rules io.Reader) (string, error) {
+		body, _ := ioutil.ReadAll(rules)
+		assert.Equal(t, readOnlyRules, string(body))
+		return string(body), nil
+	}
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		assert.Equal(t, "read-only", policyName)
+		assert.True(t, q.RequireConsistent)
+		return nil, nil, nil
+	}
+	ConsulMockACLClient.PolicyCreateFunc = func(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error) {
+		assert.Equal(t, "dc", q.Datacenter)
+		assert.Equal(t, "", policy.ID)
+		assert.Equal(t, readOnlyRules, policy.Rules)
+		assert.Equal(t, []string{"dc"}, policy.Datacenters)
+		policy.ID = "policy-1"
+		return policy, nil, nil
+	}
+
+	request := ACLPolicyRequest{Name: "read-only", Rules: readOnlyRules, Datacenters: []string{"dc"}}
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(request, WriteOptions{Datacenter: "dc"})
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	assert.True(t, changed)
+	assert.Equal(t, "policy-1", policy.ID)
+}
+
+func TestUpsertACLPolicyUpdatesPolicy(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RulesTranslateFunc = func(rules io.Reader) (string, error) {
+		return "", nil
+	}
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		return getACLPolicy(), nil, nil
+	}
+	ConsulMockACLClient.PolicyUpdateFunc = func(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error) {
+		assert.Equal(t, "policy-1", policy.ID)
+		assert.Equal(t, "read everything", policy.Description)
+		policy.ModifyIndex = 12
+		return policy, nil, nil
+	}
+
+	request := ACLPolicyRequest{Name: "read-only", Description: "read everything", Rules: readOnlyRules}
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(request, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	assert.True(t, changed)
+	assert.Equal(t, uint64(12), policy.ModifyIndex)
+}
+
+func TestUpsertACLPolicyUnchanged(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RulesTranslateFunc = func(rules io.Reader) (string, error) {
+		return "", nil
+	}
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		policy := getACLPolicy()
+		policy.Datacenters = []string{}
+		return policy, nil, nil
+	}
+
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(ACLPolicyRequest{Name: "read-only", Rules: readOnlyRules}, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	assert.False(t, changed)
+	assert.Equal(t, uint64(10), policy.ModifyIndex)
+}
+
+func TestUpsertACLPolicyInvalidRules(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RulesTranslateFunc = func(rules io.Reader) (string, error) {
+		return "", errors.New("Unexpected response code: 400 (Failed to parse ACL rules)")
+	}
+
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(ACLPolicyRequest{Name: "read-only", Rules: "key {"}, WriteOptions{})
+	assert.Nil(t, policy)
+	assert.False(t, changed)
+	require.NotNil(t, err)
+	assert.Equal(t, "rules are not valid: Unexpected response code: 400 (Failed to parse ACL rules)", err.Error())
+}
+
+func TestUpsertACLPolicyRulesValidationFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RulesTranslateFunc = func(rules io.Reader) (string, error) {
+		return "", errors.New("Unexpected response code: 403 (Permission denied)")
+	}
+
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(ACLPolicyRequest{Name: "read-only", Rules: readOnlyRules}, WriteOptions{Token: "deploy"})
+	assert.Nil(t, policy)
+	assert.False(t, changed)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to validate rules with the token of the pack (CONSUL_HTTP_TOKEN), not the token of the request: Unexpected response code: 403 (Permission denied)", err.Error())
+}
+
+func TestUpsertACLPolicyFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+	ConsulMockACLClient.PolicyCreateFunc = func(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	policy, changed, err := ConsulImpl.UpsertACLPolicy(ACLPolicyRequest{Name: "read-only"}, WriteOptions{})
+	assert.Nil(t, policy)
+	assert.False(t, changed)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to apply acl policy: kablammo", err.Error())
+}
+
+func TestDeleteACLPolicy(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		return getACLPolicy(), nil, nil
+	}
+	deleted := ""
+	ConsulMockACLClient.PolicyDeleteFunc = func(policyID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		deleted = policyID
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLPolicy("read-only", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "policy-1", deleted)
+}
+
+func TestDeleteACLPolicyNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.PolicyReadByNameFunc = func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLPolicy("read-only", WriteOptions{})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func getACLPolicy() *consul.ACLPolicy {
+	return &consul.ACLPolicy{
+		ID:          "policy-1",
+		Name:        "read-only",
+		Rules:       readOnlyRules,
+		CreateIndex: 10,
+		ModifyIndex: 10,
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+//ACLRoleRequest represents an ACL role to apply, identified by its name.
+type ACLRoleRequest struct {
+	Name              string               `json:"name"`
+	Description       string               `json:"description,omitempty"`
+	Policies          []ACLLink            `json:"policies,omitempty"`
+	ServiceIdentities []ACLServiceIdentity `json:"serviceIdentities,omitempty"`
+	NodeIdentities    []ACLNodeIdentity    `json:"nodeIdentities,omitempty"`
+}
+
+//ACLServiceIdentity grants the privileges of a service, in every datacenter when Datacenters is empty.
+type ACLServiceIdentity struct {
+	ServiceName string   `json:"serviceName"`
+	Datacenters []string `json:"datacenters,omitempty"`
+}
+
+//ACLNodeIdentity grants the privileges of a node of a datacenter.
+type ACLNodeIdentity struct {
+	NodeName   string `json:"nodeName"`
+	Datacenter string `json:"datacenter"`
+}
+
+//ACLRole represents a consul ACL role.
+type ACLRole struct {
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	Description       string               `json:"description"`
+	Policies          []ACLLink            `json:"policies"`
+	ServiceIdentities []ACLServiceIdentity `json:"serviceIdentities"`
+	NodeIdentities    []ACLNodeIdentity    `json:"nodeIdentities"`
+	CreateIndex       uint64               `json:"createIndex"`
+	ModifyIndex       uint64               `json:"modifyIndex"`
+}
+
+//Validate returns the list of problems with the role request.
+func (r ACLRoleRequest) Validate() []string {
+	errors := validateACLLinks("policies", r.Policies)
+	for index, identity := range r.ServiceIdentities {
+		if "" == identity.ServiceName {
+			errors = append(errors, fmt.Sprintf("serviceIdentities[%d] requires a serviceName", index))
+		}
+	}
+	for index, identity := range r.NodeIdentities {
+		if "" == identity.NodeName || "" == identity.Datacenter {
+			errors = append(errors, fmt.Sprintf("nodeIdentities[%d] requires a nodeName and a datacenter", index))
+		}
+	}
+	return errors
+}
+
+//UpsertACLRole creates the ACL role or, when a role with the same name exists, updates it.
+//The role is only written when it differs from the existing one, in which case the returned bool is true.
+func (c *consulClient) UpsertACLRole(request ACLRoleRequest, options WriteOptions) (*ACLRole, bool, error) {
+	current, _, err := c.aclClient.RoleReadByName(request.Name, toConsistentQueryOptions(options))
+	if nil != err {
+		return nil, false, fmt.Errorf("failed to read acl role: %v", err)
+	}
+	if nil != current && sameACLRole(current, request) {
+		return toACLRole(current), false, nil
+	}
+
+	role := &consul.ACLRole{
+		Name:              request.Name,
+		Description:       request.Description,
+		Policies:          toConsulACLLinks(request.Policies),
+		ServiceIdentities: toConsulServiceIdentities(request.ServiceIdentities),
+		NodeIdentities:    toConsulNodeIdentities(request.NodeIdentities),
+	}
+	var applied *consul.ACLRole
+	if nil == current {
+		applied, _, err = c.aclClient.RoleCreate(role, toWriteOptions(options))
+	} else {
+		role.ID = current.ID
+		applied, _, err = c.aclClient.RoleUpdate(role, toWriteOptions(options))
+	}
+	if nil != err {
+		return nil, false, fmt.Errorf("failed to apply acl role: %v", err)
+	}
+	return toACLRole(applied), true, nil
+}
+
+//DeleteACLRole deletes the ACL role with the name. It returns false when the role does not exist.
+func (c *consulClient) DeleteACLRole(name string, options WriteOptions) (bool, error) {
+	role, _, err := c.aclClient.RoleReadByName(name, toConsistentQueryOptions(options))
+	if nil != err {
+		return false, fmt.Errorf("failed to read acl role: %v", err)
+	}
+	if nil == role {
+		return false, nil
+	}
+
+	if _, err := c.aclClient.RoleDelete(role.ID, toWriteOptions(options)); nil != err {
+		return false, fmt.Errorf("failed to delete acl role: %v", err)
+	}
+	return true, nil
+}
+
+func sameACLRole(current *consul.ACLRole, request ACLRoleRequest) bool {
+	if current.Description != request.Description ||
+		!sameACLLinks(current.Policies, request.Policies) ||
+		len(current.ServiceIdentities) != len(request.ServiceIdentities) ||
+		len(current.NodeIdentities) != len(request.NodeIdentities) {
+		return false
+	}
+	for index, identity := range request.ServiceIdentities {
+		if identity.ServiceName != current.ServiceIdentities[index].ServiceName ||
+			!sameStrings(identity.Datacenters, current.ServiceIdentities[index].Datacenters) {
+			return false
+		}
+	}
+	for index, identity := range request.NodeIdentities {
+		if identity.NodeName != current.NodeIdentities[index].NodeName ||
+			identity.Datacenter != current.NodeIdentities[index].Datacenter {
+			return false
+		}
+	}
+	return true
+}
+
+func toConsulServiceIdentities(identities []ACLServiceIdentity) []*consul.ACLServiceIdentity {
+	if nil == identities {
+		return nil
+	}
+	retval := make([]*consul.ACLServiceIdentity, len(identities))
+	for index, identity := range identities {
+		retval[index] = &consul.ACLServiceIdentity{ServiceName: identity.ServiceName, Datacenters: identity.Datacenters}
+	}
+	return retval
+}
+
+func toConsulNodeIdentities(identities []ACLNodeIdentity) []*consul.ACLNodeIdentity {
+	if nil == identities {
+		return nil
+	}
+	retval := make([]*consul.ACLNodeIdentity, len(identities))
+	for index, identity := range identities {
+		retval[index] = &consul.ACLNodeIdentity{NodeName: identity.NodeName, Datacenter: identity.Datacenter}
+	}
+	return retval
+}
+
+func toACLRole(role *consul.ACLRole) *ACLRole {
+	retval := &ACLRole{
+		ID:                role.ID,
+		Name:              role.Name,
+		Description:       role.Description,
+		Policies:          toACLLinks(role.Policies),
+		ServiceIdentities: make([]ACLServiceIdentity, len(role.ServiceIdentities)),
+		NodeIdentities:    make([]ACLNodeIdentity, len(role.NodeIdentities)),
+		CreateIndex:       role.CreateIndex,
+		ModifyIndex:       role.ModifyIndex,
+	}
+	for index, identity := range role.ServiceIdentities {
+		retval.ServiceIdentities[index] = ACLServiceIdentity{ServiceName: identity.ServiceName, Datacenters: identity.Datacenters}
+	}
+	for index, identity := range role.NodeIdentities {
+		retval.NodeIdentities[index] = ACLNodeIdentity{NodeName: identity.NodeName, Datacenter: identity.Datacenter}
+	}
+	return retval
+}
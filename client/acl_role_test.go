@@ -0,0 +1,171 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLRoleRequestValidate(t *testing.T) {
+	request := ACLRoleRequest{
+		Name:              "deployer",
+		Policies:          []ACLLink{{}},
+		ServiceIdentities: []ACLServiceIdentity{{}},
+		NodeIdentities:    []ACLNodeIdentity{{NodeName: "node-1"}},
+	}
+
+	assert.Equal(t, []string{
+		"policies[0] requires an id or a name",
+		"serviceIdentities[0] requires a serviceName",
+		"nodeIdentities[0] requires a nodeName and a datacenter",
+	}, request.Validate())
+	assert.Empty(t, ACLRoleRequest{Name: "deployer"}.Validate())
+}
+
+func TestUpsertACLRoleCreatesRole(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		assert.Equal(t, "deployer", roleName)
+		return nil, nil, nil
+	}
+	ConsulMockACLClient.RoleCreateFunc = func(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error) {
+		assert.Equal(t, []*consul.ACLLink{{Name: "read-only"}}, role.Policies)
+		assert.Equal(t, []*consul.ACLServiceIdentity{{ServiceName: "web"}}, role.ServiceIdentities)
+		role.ID = "role-1"
+		return role, nil, nil
+	}
+
+	request := ACLRoleRequest{
+		Name:              "deployer",
+		Policies:          []ACLLink{{Name: "read-only"}},
+		ServiceIdentities: []ACLServiceIdentity{{ServiceName: "web"}},
+	}
+	role, changed, err := ConsulImpl.UpsertACLRole(request, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, role)
+	assert.True(t, changed)
+	assert.Equal(t, "role-1", role.ID)
+	assert.Equal(t, []ACLServiceIdentity{{ServiceName: "web"}}, role.ServiceIdentities)
+	assert.Equal(t, []ACLNodeIdentity{}, role.NodeIdentities)
+}
+
+func TestUpsertACLRoleUpdatesRole(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		return getACLRole(), nil, nil
+	}
+	ConsulMockACLClient.RoleUpdateFunc = func(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error) {
+		assert.Equal(t, "role-1", role.ID)
+		assert.Equal(t, []*consul.ACLNodeIdentity{{NodeName: "node-1", Datacenter: "dc"}}, role.NodeIdentities)
+		return role, nil, nil
+	}
+
+	request := ACLRoleRequest{
+		Name:              "deployer",
+		Policies:          []ACLLink{{Name: "read-only"}},
+		ServiceIdentities: []ACLServiceIdentity{{ServiceName: "web", Datacenters: []string{"dc"}}},
+		NodeIdentities:    []ACLNodeIdentity{{NodeName: "node-1", Datacenter: "dc"}},
+	}
+	role, changed, err := ConsulImpl.UpsertACLRole(request, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, role)
+	assert.True(t, changed)
+}
+
+func TestUpsertACLRoleUnchanged(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		return getACLRole(), nil, nil
+	}
+
+	request := ACLRoleRequest{
+		Name:              "deployer",
+		Policies:          []ACLLink{{ID: "policy-1"}},
+		ServiceIdentities: []ACLServiceIdentity{{ServiceName: "web", Datacenters: []string{"dc"}}},
+	}
+	role, changed, err := ConsulImpl.UpsertACLRole(request, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, role)
+	assert.False(t, changed)
+	assert.Equal(t, []ACLLink{{ID: "policy-1", Name: "read-only"}}, role.Policies)
+}
+
+func TestUpsertACLRoleFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	role, changed, err := ConsulImpl.UpsertACLRole(ACLRoleRequest{Name: "deployer"}, WriteOptions{})
+	assert.Nil(t, role)
+	assert.False(t, changed)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to read acl role: kablammo", err.Error())
+}
+
+func TestDeleteACLRole(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		return getACLRole(), nil, nil
+	}
+	deleted := ""
+	ConsulMockACLClient.RoleDeleteFunc = func(roleID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		deleted = roleID
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLRole("deployer", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "role-1", deleted)
+}
+
+func TestDeleteACLRoleNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.RoleReadByNameFunc = func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLRole("deployer", WriteOptions{})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func getACLRole() *consul.ACLRole {
+	return &consul.ACLRole{
+		ID:                "role-1",
+		Name:              "deployer",
+		Policies:          []*consul.ACLLink{{ID: "policy-1", Name: "read-only"}},
+		ServiceIdentities: []*consul.ACLServiceIdentity{{ServiceName: "web", Datacenters: []string{"dc"}}},
+		CreateIndex:       10,
+		ModifyIndex:       10,
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+)
+
+var errACLNotFound = errors.New("Unexpected response code: 403 (ACL not found)")
+
+func TestSameACLLinks(t *testing.T) {
+	current := []*consul.ACLLink{{ID: "1", Name: "read-only"}, {ID: "2", Name: "deployer"}}
+
+	assert.True(t, sameACLLinks(current, []ACLLink{{Name: "read-only"}, {ID: "2"}}))
+	assert.True(t, sameACLLinks(nil, nil))
+	assert.False(t, sameACLLinks(current, []ACLLink{{Name: "deployer"}, {Name: "read-only"}}))
+	assert.False(t, sameACLLinks(current, []ACLLink{{Name: "read-only"}}))
+	assert.False(t, sameACLLinks(current, []ACLLink{{ID: "1", Name: "admin"}, {ID: "2"}}))
+}
+
+func TestIsACLNotFound(t *testing.T) {
+	assert.True(t, isACLNotFound(errACLNotFound))
+	assert.False(t, isACLNotFound(errors.New("Unexpected response code: 403 (Permission denied)")))
+}
+
+type MockACLClient struct {
+	t                    *testing.T
+	TokenCreateFunc      func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenUpdateFunc      func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenCloneFunc       func(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error)
+	TokenDeleteFunc      func(tokenID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	TokenReadFunc        func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error)
+	TokenReadSelfFunc    func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error)
+	PolicyCreateFunc     func(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error)
+	PolicyUpdateFunc     func(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error)
+	PolicyDeleteFunc     func(policyID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	PolicyReadByNameFunc func(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error)
+	RoleCreateFunc       func(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error)
+	RoleUpdateFunc       func(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error)
+	RoleDeleteFunc       func(roleID string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	RoleReadByNameFunc   func(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error)
+	RulesTranslateFunc   func(rules io.Reader) (string, error)
+}
+
+func NewMockACLClient(t *testing.T) *MockACLClient {
+	return &MockACLClient{t: t}
+}
+
+func (m *MockACLClient) TokenCreate(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+	return m.TokenCreateFunc(token, q)
+}
+
+func (m *MockACLClient) TokenUpdate(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+	return m.TokenUpdateFunc(token, q)
+}
+
+func (m *MockACLClient) TokenClone(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+	return m.TokenCloneFunc(tokenID, description, q)
+}
+
+func (m *MockACLClient) TokenDelete(tokenID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.TokenDeleteFunc(tokenID, q)
+}
+
+func (m *MockACLClient) TokenRead(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+	return m.TokenReadFunc(tokenID, q)
+}
+
+func (m *MockACLClient) TokenReadSelf(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+	return m.TokenReadSelfFunc(q)
+}
+
+func (m *MockACLClient) PolicyCreate(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error) {
+	return m.PolicyCreateFunc(policy, q)
+}
+
+func (m *MockACLClient) PolicyUpdate(policy *consul.ACLPolicy, q *consul.WriteOptions) (*consul.ACLPolicy, *consul.WriteMeta, error) {
+	return m.PolicyUpdateFunc(policy, q)
+}
+
+func (m *MockACLClient) PolicyDelete(policyID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.PolicyDeleteFunc(policyID, q)
+}
+
+func (m *MockACLClient) PolicyReadByName(policyName string, q *consul.QueryOptions) (*consul.ACLPolicy, *consul.QueryMeta, error) {
+	return m.PolicyReadByNameFunc(policyName, q)
+}
+
+func (m *MockACLClient) RoleCreate(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error) {
+	return m.RoleCreateFunc(role, q)
+}
+
+func (m *MockACLClient) RoleUpdate(role *consul.ACLRole, q *consul.WriteOptions) (*consul.ACLRole, *consul.WriteMeta, error) {
+	return m.RoleUpdateFunc(role, q)
+}
+
+func (m *MockACLClient) RoleDelete(roleID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.RoleDeleteFunc(roleID, q)
+}
+
+func (m *MockACLClient) RoleReadByName(roleName string, q *consul.QueryOptions) (*consul.ACLRole, *consul.QueryMeta, error) {
+	return m.RoleReadByNameFunc(roleName, q)
+}
+
+func (m *MockACLClient) RulesTranslate(rules io.Reader) (string, error) {
+	return m.RulesTranslateFunc(rules)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+//ACLTokenRequest represents the fields of an ACL token to create or update.
+//Description and Local keep their current value on update when not set.
+//ExpirationTTL is a duration and can only be set when the token is created.
+type ACLTokenRequest struct {
+	Description   *string   `json:"description,omitempty"`
+	Policies      []ACLLink `json:"policies,omitempty"`
+	Roles         []ACLLink `json:"roles,omitempty"`
+	Local         *bool     `json:"local,omitempty"`
+	ExpirationTTL string    `json:"expirationTTL,omitempty"`
+}
+
+//ACLToken represents a consul ACL token.
+type ACLToken struct {
+	AccessorID     string     `json:"accessorId"`
+	SecretID       string     `json:"secretId"`
+	Description    string     `json:"description"`
+	Policies       []ACLLink  `json:"policies"`
+	Roles          []ACLLink  `json:"roles"`
+	Local          bool       `json:"local"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	CreateTime     time.Time  `json:"createTime"`
+	CreateIndex    uint64     `json:"createIndex"`
+	ModifyIndex    uint64     `json:"modifyIndex"`
+}
+
+//Validate returns the list of problems with the token request.
+func (r ACLTokenRequest) Validate() []string {
+	errors := []string{}
+	if "" != r.ExpirationTTL {
+		ttl, err := time.ParseDuration(r.ExpirationTTL)
+		if nil != err {
+			errors = append(errors, fmt.Sprintf("expirationTTL %v is not a valid duration", r.ExpirationTTL))
+		} else if ttl <= 0 {
+			errors = append(errors, "expirationTTL must be positive")
+		}
+	}
+	errors = append(errors, validateACLLinks("policies", r.Policies)...)
+	errors = append(errors, validateACLLinks("roles", r.Roles)...)
+	return errors
+}
+
+//CreateACLToken creates an ACL token.
+func (c *consulClient) CreateACLToken(request ACLTokenRequest, options WriteOptions) (*ACLToken, error) {
+	token := &consul.ACLToken{
+		Policies: toConsulACLLinks(request.Policies),
+		Roles:    toConsulACLLinks(request.Roles),
+	}
+	if nil != request.Description {
+		token.Description = *request.Description
+	}
+	if nil != request.Local {
+		token.Local = *request.Local
+	}
+	if "" != request.ExpirationTTL {
+		ttl, err := time.ParseDuration(request.ExpirationTTL)
+		if nil != err {
+			return nil, fmt.Errorf("failed to create acl token: %v", err)
+		}
+		token.ExpirationTTL = ttl
+	}
+
+	created, _, err := c.aclClient.TokenCreate(token, toWriteOptions(options))
+	if nil != err {
+		return nil, fmt.Errorf("failed to create acl token: %v", err)
+	}
+	return toACLToken(created), nil
+}
+
+//ReadACLToken returns the ACL token. It returns nil when the token does not exist.
+func (c *consulClient) ReadACLToken(accessorID string, options QueryOptions) (*ACLToken, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	token, _, err := c.aclClient.TokenRead(accessorID, q)
+	if c.isACLTokenNotFound(err, q) {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, fmt.Errorf("failed to read acl token: %v", err)
+	}
+	return toACLToken(token), nil
+}
+
+//UpdateACLToken replaces the policies and roles of the ACL token, and its description when set.
+//It returns nil when the token does not exist.
+func (c *consulClient) UpdateACLToken(accessorID string, request ACLTokenRequest, options WriteOptions) (*ACLToken, error) {
+	if "" != request.ExpirationTTL {
+		return nil, fmt.Errorf("failed to update acl token: the expiration cannot be changed")
+	}
+
+	q := toConsistentQueryOptions(options)
+	token, _, err := c.aclClient.TokenRead(accessorID, q)
+	if c.isACLTokenNotFound(err, q) {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, fmt.Errorf("failed to update acl token: %v", err)
+	}
+	if nil != request.Local && token.Local != *request.Local {
+		return nil, fmt.Errorf("failed to update acl token: a token cannot be changed between local and global")
+	}
+
+	if nil != request.Description {
+		token.Description = *request.Description
+	}
+	token.Policies = toConsulACLLinks(request.Policies)
+	token.Roles = toConsulACLLinks(request.Roles)
+	updated, _, err := c.aclClient.TokenUpdate(token, toWriteOptions(options))
+	if nil != err {
+		return nil, fmt.Errorf("failed to update acl token: %v", err)
+	}
+	return toACLToken(updated), nil
+}
+
+//DeleteACLToken deletes the ACL token. It returns false when the token does not exist.
+func (c *consulClient) DeleteACLToken(accessorID string, options WriteOptions) (bool, error) {
+	// consul does not report deleting a token that does not exist
+	q := toConsistentQueryOptions(options)
+	_, _, err := c.aclClient.TokenRead(accessorID, q)
+	if c.isACLTokenNotFound(err, q) {
+		return false, nil
+	}
+	if nil != err {
+		return false, fmt.Errorf("failed to delete acl token: %v", err)
+	}
+
+	if _, err := c.aclClient.TokenDelete(accessorID, toWriteOptions(options)); nil != err {
+		return false, fmt.Errorf("failed to delete acl token: %v", err)
+	}
+	return true, nil
+}
+
+//CloneACLToken creates a token with the policies and roles of the ACL token.
+//It returns nil when the token does not exist.
+func (c *consulClient) CloneACLToken(accessorID, description string, options WriteOptions) (*ACLToken, error) {
+	token, _, err := c.aclClient.TokenClone(accessorID, description, toWriteOptions(options))
+	if c.isACLTokenNotFound(err, toConsistentQueryOptions(options)) {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, fmt.Errorf("failed to clone acl token: %v", err)
+	}
+	return toACLToken(token), nil
+}
+
+func toACLToken(token *consul.ACLToken) *ACLToken {
+	return &ACLToken{
+		AccessorID:     token.AccessorID,
+		SecretID:       token.SecretID,
+		Description:    token.Description,
+		Policies:       toACLLinks(token.Policies),
+		Roles:          toACLLinks(token.Roles),
+		Local:          token.Local,
+		ExpirationTime: token.ExpirationTime,
+		CreateTime:     token.CreateTime,
+		CreateIndex:    token.CreateIndex,
+		ModifyIndex:    token.ModifyIndex,
+	}
+}
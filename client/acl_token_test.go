@@ -0,0 +1,342 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLTokenRequestValidate(t *testing.T) {
+	request := ACLTokenRequest{
+		Policies:      []ACLLink{{Name: "read-only"}, {}},
+		Roles:         []ACLLink{{}},
+		ExpirationTTL: "tomorrow",
+	}
+
+	assert.Equal(t, []string{
+		"expirationTTL tomorrow is not a valid duration",
+		"policies[1] requires an id or a name",
+		"roles[0] requires an id or a name",
+	}, request.Validate())
+	assert.Equal(t, []string{"expirationTTL must be positive"}, ACLTokenRequest{ExpirationTTL: "-1h"}.Validate())
+	assert.Empty(t, ACLTokenRequest{ExpirationTTL: "1h", Policies: []ACLLink{{ID: "1"}}}.Validate())
+}
+
+func TestCreateACLToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCreateFunc = func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		assert.Equal(t, "dc", q.Datacenter)
+		assert.Equal(t, "deploy", token.Description)
+		assert.Equal(t, []*consul.ACLLink{{Name: "read-only"}}, token.Policies)
+		assert.Equal(t, []*consul.ACLLink{{ID: "role-1"}}, token.Roles)
+		assert.Equal(t, time.Hour, token.ExpirationTTL)
+		token.AccessorID = "accessor"
+		token.SecretID = "secret"
+		token.Policies[0].ID = "policy-1"
+		return token, nil, nil
+	}
+
+	description := "deploy"
+	request := ACLTokenRequest{
+		Description:   &description,
+		Policies:      []ACLLink{{Name: "read-only"}},
+		Roles:         []ACLLink{{ID: "role-1"}},
+		ExpirationTTL: "1h",
+	}
+	token, err := ConsulImpl.CreateACLToken(request, WriteOptions{Datacenter: "dc"})
+	require.Nil(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "accessor", token.AccessorID)
+	assert.Equal(t, "secret", token.SecretID)
+	assert.Equal(t, []ACLLink{{ID: "policy-1", Name: "read-only"}}, token.Policies)
+	assert.Equal(t, []ACLLink{{ID: "role-1"}}, token.Roles)
+}
+
+func TestCreateACLTokenFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCreateFunc = func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	token, err := ConsulImpl.CreateACLToken(ACLTokenRequest{}, WriteOptions{})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to create acl token: kablammo", err.Error())
+}
+
+func TestReadACLToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		assert.Equal(t, "accessor", tokenID)
+		assert.Equal(t, "management", q.Token)
+		return getACLToken(), nil, nil
+	}
+
+	token, err := ConsulImpl.ReadACLToken("accessor", QueryOptions{Token: "management"})
+	require.Nil(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "deploy", token.Description)
+	assert.Equal(t, []ACLLink{{ID: "policy-1", Name: "read-only"}}, token.Policies)
+	assert.Equal(t, []ACLLink{}, token.Roles)
+}
+
+func TestReadACLTokenNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+
+	token, err := ConsulImpl.ReadACLToken("accessor", QueryOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+}
+
+func TestReadACLTokenInvalidRequestToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		assert.Equal(t, "expired", q.Token)
+		return nil, nil, errACLNotFound
+	}
+
+	token, err := ConsulImpl.ReadACLToken("accessor", QueryOptions{Token: "expired"})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to read acl token: Unexpected response code: 403 (ACL not found)", err.Error())
+}
+
+func TestUpdateACLToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		assert.True(t, q.RequireConsistent)
+		return getACLToken(), nil, nil
+	}
+	ConsulMockACLClient.TokenUpdateFunc = func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		assert.Equal(t, "accessor", token.AccessorID)
+		assert.Equal(t, "secret", token.SecretID)
+		assert.Equal(t, "release", token.Description)
+		assert.Nil(t, token.Policies)
+		assert.Equal(t, []*consul.ACLLink{{Name: "deployer"}}, token.Roles)
+		token.ModifyIndex = 12
+		return token, nil, nil
+	}
+
+	description := "release"
+	request := ACLTokenRequest{Description: &description, Roles: []ACLLink{{Name: "deployer"}}}
+	token, err := ConsulImpl.UpdateACLToken("accessor", request, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "release", token.Description)
+	assert.Equal(t, uint64(12), token.ModifyIndex)
+}
+
+func TestUpdateACLTokenKeepsOmittedFields(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		token := getACLToken()
+		token.Local = true
+		return token, nil, nil
+	}
+	ConsulMockACLClient.TokenUpdateFunc = func(token *consul.ACLToken, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		assert.Equal(t, "deploy", token.Description)
+		assert.True(t, token.Local)
+		assert.Equal(t, []*consul.ACLLink{{Name: "deployer"}}, token.Roles)
+		return token, nil, nil
+	}
+
+	token, err := ConsulImpl.UpdateACLToken("accessor", ACLTokenRequest{Roles: []ACLLink{{Name: "deployer"}}}, WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "deploy", token.Description)
+	assert.True(t, token.Local)
+}
+
+func TestUpdateACLTokenNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+
+	token, err := ConsulImpl.UpdateACLToken("accessor", ACLTokenRequest{}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+}
+
+func TestUpdateACLTokenExpiration(t *testing.T) {
+	Before(t)
+	defer After()
+
+	token, err := ConsulImpl.UpdateACLToken("accessor", ACLTokenRequest{ExpirationTTL: "1h"}, WriteOptions{})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to update acl token: the expiration cannot be changed", err.Error())
+}
+
+func TestUpdateACLTokenLocal(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+
+	local := true
+	token, err := ConsulImpl.UpdateACLToken("accessor", ACLTokenRequest{Local: &local}, WriteOptions{})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to update acl token: a token cannot be changed between local and global", err.Error())
+}
+
+func TestDeleteACLToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+	deleted := ""
+	ConsulMockACLClient.TokenDeleteFunc = func(tokenID string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		deleted = tokenID
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLToken("accessor", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "accessor", deleted)
+}
+
+func TestDeleteACLTokenNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenReadFunc = func(tokenID string, q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteACLToken("accessor", WriteOptions{})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestCloneACLToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCloneFunc = func(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		assert.Equal(t, "accessor", tokenID)
+		assert.Equal(t, "copy", description)
+		token := getACLToken()
+		token.AccessorID = "clone"
+		token.Description = description
+		return token, nil, nil
+	}
+
+	token, err := ConsulImpl.CloneACLToken("accessor", "copy", WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "clone", token.AccessorID)
+	assert.Equal(t, "copy", token.Description)
+}
+
+func TestCloneACLTokenFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCloneFunc = func(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	token, err := ConsulImpl.CloneACLToken("accessor", "copy", WriteOptions{})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to clone acl token: kablammo", err.Error())
+}
+
+func TestCloneACLTokenNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCloneFunc = func(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return getACLToken(), nil, nil
+	}
+
+	token, err := ConsulImpl.CloneACLToken("accessor", "copy", WriteOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+}
+
+func TestCloneACLTokenInvalidRequestToken(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockACLClient.TokenCloneFunc = func(tokenID string, description string, q *consul.WriteOptions) (*consul.ACLToken, *consul.WriteMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+	ConsulMockACLClient.TokenReadSelfFunc = func(q *consul.QueryOptions) (*consul.ACLToken, *consul.QueryMeta, error) {
+		return nil, nil, errACLNotFound
+	}
+
+	token, err := ConsulImpl.CloneACLToken("accessor", "copy", WriteOptions{Token: "expired"})
+	assert.Nil(t, token)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to clone acl token: Unexpected response code: 403 (ACL not found)", err.Error())
+}
+
+func getACLToken() *consul.ACLToken {
+	return &consul.ACLToken{
+		AccessorID:  "accessor",
+		SecretID:    "secret",
+		Description: "deploy",
+		Policies:    []*consul.ACLLink{{ID: "policy-1", Name: "read-only"}},
+		CreateIndex: 10,
+		ModifyIndex: 10,
+	}
+}
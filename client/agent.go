@@ -0,0 +1,198 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+const (
+	maxMetaPairs       = 64
+	maxMetaKeyLength   = 128
+	maxMetaValueLength = 512
+	reservedMetaPrefix = "consul-"
+)
+
+var metaKeyFormat = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type agentClient interface {
+	ServiceRegister(service *consul.AgentServiceRegistration) error
+	ServiceDeregister(serviceID string) error
+}
+
+//ServiceRegistration represents a service to register.
+type ServiceRegistration struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Address string            `json:"address"`
+	Port    int               `json:"port"`
+	Tags    []string          `json:"tags"`
+	Meta    map[string]string `json:"meta"`
+	Checks  []CheckDefinition `json:"checks"`
+}
+
+//CheckDefinition represents a service health check definition.
+//Exactly one of HTTP, TCP and TTL must be set.
+type CheckDefinition struct {
+	CheckID                        string `json:"checkId"`
+	Name                           string `json:"name"`
+	HTTP                           string `json:"http,omitempty"`
+	Method                         string `json:"method,omitempty"`
+	TCP                            string `json:"tcp,omitempty"`
+	TTL                            string `json:"ttl,omitempty"`
+	Interval                       string `json:"interval,omitempty"`
+	Timeout                        string `json:"timeout,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"deregisterCriticalServiceAfter,omitempty"`
+	Status                         string `json:"status,omitempty"`
+	Notes                          string `json:"notes,omitempty"`
+}
+
+//Validate returns the problems found in the service registration.
+func (s ServiceRegistration) Validate() []string {
+	errors := []string{}
+	if "" == s.Name {
+		errors = append(errors, "name is missing")
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		errors = append(errors, fmt.Sprintf("port %d is out of range", s.Port))
+	}
+	for _, tag := range s.Tags {
+		if "" == strings.TrimSpace(tag) {
+			errors = append(errors, "tags cannot be empty")
+			break
+		}
+	}
+	errors = append(errors, validateMeta(s.Meta)...)
+	for index, check := range s.Checks {
+		for _, err := range check.Validate() {
+			errors = append(errors, fmt.Sprintf("check %d: %s", index, err))
+		}
+	}
+	return errors
+}
+
+//Validate returns the problems found in the check definition.
+func (c CheckDefinition) Validate() []string {
+	errors := []string{}
+	kinds := 0
+	if "" != c.HTTP {
+		kinds++
+		if u, err := url.Parse(c.HTTP); nil != err || "" == u.Scheme || "" == u.Host {
+			errors = append(errors, fmt.Sprintf("%v is not a valid http url", c.HTTP))
+		}
+	}
+	if "" != c.TCP {
+		kinds++
+		if _, _, err := net.SplitHostPort(c.TCP); nil != err {
+			errors = append(errors, fmt.Sprintf("%v is not a valid tcp address", c.TCP))
+		}
+	}
+	if "" != c.TTL {
+		kinds++
+		errors = append(errors, validateDuration("ttl", c.TTL)...)
+	}
+	if 1 != kinds {
+		errors = append(errors, "exactly one of http, tcp and ttl must be set")
+	}
+	if ("" != c.HTTP || "" != c.TCP) && "" == c.Interval {
+		errors = append(errors, "interval is missing")
+	}
+	errors = append(errors, validateDuration("interval", c.Interval)...)
+	errors = append(errors, validateDuration("timeout", c.Timeout)...)
+	errors = append(errors, validateDuration("deregisterCriticalServiceAfter", c.DeregisterCriticalServiceAfter)...)
+	switch c.Status {
+	case "", consul.HealthPassing, consul.HealthWarning, consul.HealthCritical:
+	default:
+		errors = append(errors, fmt.Sprintf("%v status is not valid", c.Status))
+	}
+	return errors
+}
+
+func (c *consulClient) RegisterService(service ServiceRegistration) error {
+	registration := &consul.AgentServiceRegistration{
+		ID:      service.ID,
+		Name:    service.Name,
+		Address: service.Address,
+		Port:    service.Port,
+		Tags:    service.Tags,
+		Meta:    service.Meta,
+	}
+	for _, check := range service.Checks {
+		registration.Checks = append(registration.Checks, toAgentServiceCheck(check))
+	}
+
+	if err := c.agentClient.ServiceRegister(registration); nil != err {
+		return fmt.Errorf("failed to register service: %v", err)
+	}
+	return nil
+}
+
+func (c *consulClient) DeregisterService(serviceID string) error {
+	if err := c.agentClient.ServiceDeregister(serviceID); nil != err {
+		return fmt.Errorf("failed to deregister service: %v", err)
+	}
+	return nil
+}
+
+func toAgentServiceCheck(check CheckDefinition) *consul.AgentServiceCheck {
+	return &consul.AgentServiceCheck{
+		CheckID:                        check.CheckID,
+		Name:                           check.Name,
+		HTTP:                           check.HTTP,
+		Method:                         check.Method,
+		TCP:                            check.TCP,
+		TTL:                            check.TTL,
+		Interval:                       check.Interval,
+		Timeout:                        check.Timeout,
+		DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		Status:                         check.Status,
+		Notes:                          check.Notes,
+	}
+}
+
+func validateMeta(meta map[string]string) []string {
+	errors := []string{}
+	if len(meta) > maxMetaPairs {
+		errors = append(errors, fmt.Sprintf("meta cannot have more than %d pairs", maxMetaPairs))
+	}
+	for key, value := range meta {
+		if !metaKeyFormat.MatchString(key) || len(key) > maxMetaKeyLength {
+			errors = append(errors, fmt.Sprintf("meta key %q is not valid", key))
+		}
+		if strings.HasPrefix(key, reservedMetaPrefix) {
+			errors = append(errors, fmt.Sprintf("meta key %q uses the reserved %q prefix", key, reservedMetaPrefix))
+		}
+		if len(value) > maxMetaValueLength {
+			errors = append(errors, fmt.Sprintf("meta value of %q is longer than %d characters", key, maxMetaValueLength))
+		}
+	}
+	return errors
+}
+
+func validateDuration(name, value string) []string {
+	if "" == value {
+		return nil
+	}
+	if _, err := time.ParseDuration(value); nil != err {
+		return []string{fmt.Sprintf("%s %v is not a valid duration", name, value)}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterService(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockAgentClient.ServiceRegisterFunc = func(service *consul.AgentServiceRegistration) error {
+		assert.Equal(t, "db-1", service.ID)
+		assert.Equal(t, "db", service.Name)
+		assert.Equal(t, "db.example.com", service.Address)
+		assert.Equal(t, 5432, service.Port)
+		assert.Equal(t, []string{"external"}, service.Tags)
+		require.Equal(t, 1, len(service.Checks))
+		assert.Equal(t, "db.example.com:5432", service.Checks[0].TCP)
+		assert.Equal(t, "10s", service.Checks[0].Interval)
+		return nil
+	}
+
+	err := ConsulImpl.RegisterService(getServiceRegistration())
+	assert.Nil(t, err)
+}
+
+func TestRegisterServiceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockAgentClient.ServiceRegisterFunc = func(service *consul.AgentServiceRegistration) error {
+		return errors.New("kablammo")
+	}
+
+	err := ConsulImpl.RegisterService(getServiceRegistration())
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to register service: kablammo", err.Error())
+}
+
+func TestDeregisterService(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockAgentClient.ServiceDeregisterFunc = func(serviceID string) error {
+		assert.Equal(t, "db-1", serviceID)
+		return nil
+	}
+
+	assert.Nil(t, ConsulImpl.DeregisterService("db-1"))
+}
+
+func TestDeregisterServiceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockAgentClient.ServiceDeregisterFunc = func(serviceID string) error {
+		return errors.New("kablammo")
+	}
+
+	err := ConsulImpl.DeregisterService("db-1")
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to deregister service: kablammo", err.Error())
+}
+
+func TestServiceRegistrationValidate(t *testing.T) {
+	assert.Equal(t, 0, len(getServiceRegistration().Validate()))
+
+	service := ServiceRegistration{
+		Port: 70000,
+		Tags: []string{"ok", " "},
+		Meta: map[string]string{"consul-version": "1"},
+		Checks: []CheckDefinition{
+			{HTTP: "http://db.example.com/health"},
+		},
+	}
+	assert.Equal(t, []string{
+		"name is missing",
+		"port 70000 is out of range",
+		"tags cannot be empty",
+		"meta key \"consul-version\" uses the reserved \"consul-\" prefix",
+		"check 0: interval is missing",
+	}, service.Validate())
+}
+
+func TestServiceRegistrationValidateMeta(t *testing.T) {
+	service := ServiceRegistration{
+		Name: "db",
+		Meta: map[string]string{"not valid": "1"},
+	}
+	assert.Equal(t, []string{"meta key \"not valid\" is not valid"}, service.Validate())
+}
+
+func TestCheckDefinitionValidate(t *testing.T) {
+	assert.Equal(t, 0, len(CheckDefinition{TTL: "30s"}.Validate()))
+	assert.Equal(t, 0, len(CheckDefinition{HTTP: "https://db.example.com/health", Interval: "10s", Timeout: "1s"}.Validate()))
+
+	assert.Equal(t, []string{"exactly one of http, tcp and ttl must be set"}, CheckDefinition{}.Validate())
+	assert.Equal(t, []string{"exactly one of http, tcp and ttl must be set"}, CheckDefinition{TCP: "db:5432", TTL: "10s", Interval: "10s"}.Validate())
+	assert.Equal(t, []string{"db is not a valid tcp address"}, CheckDefinition{TCP: "db", Interval: "10s"}.Validate())
+	assert.Equal(t, []string{"db/health is not a valid http url"}, CheckDefinition{HTTP: "db/health", Interval: "10s"}.Validate())
+	assert.Equal(t, []string{"ttl soon is not a valid duration"}, CheckDefinition{TTL: "soon"}.Validate())
+	assert.Equal(t, []string{"timeout later is not a valid duration"}, CheckDefinition{TCP: "db:5432", Interval: "10s", Timeout: "later"}.Validate())
+	assert.Equal(t, []string{"maintenance status is not valid"}, CheckDefinition{TTL: "10s", Status: "maintenance"}.Validate())
+}
+
+func getServiceRegistration() ServiceRegistration {
+	return ServiceRegistration{
+		ID:      "db-1",
+		Name:    "db",
+		Address: "db.example.com",
+		Port:    5432,
+		Tags:    []string{"external"},
+		Meta:    map[string]string{"owner": "team"},
+		Checks: []CheckDefinition{
+			{
+				CheckID:  "db-tcp",
+				Name:     "db tcp",
+				TCP:      "db.example.com:5432",
+				Interval: "10s",
+			},
+		},
+	}
+}
+
+type MockAgentClient struct {
+	t                     *testing.T
+	ServiceRegisterFunc   func(service *consul.AgentServiceRegistration) error
+	ServiceDeregisterFunc func(serviceID string) error
+}
+
+func NewMockAgentClient(t *testing.T) *MockAgentClient {
+	return &MockAgentClient{t: t}
+}
+
+func (m *MockAgentClient) ServiceRegister(service *consul.AgentServiceRegistration) error {
+	return m.ServiceRegisterFunc(service)
+}
+
+func (m *MockAgentClient) ServiceDeregister(serviceID string) error {
+	return m.ServiceDeregisterFunc(serviceID)
+}
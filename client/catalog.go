@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+type catalogClient interface {
+	Services(q *consul.QueryOptions) (map[string][]string, *consul.QueryMeta, error)
+	ServiceMultipleTags(service string, tags []string, q *consul.QueryOptions) ([]*consul.CatalogService, *consul.QueryMeta, error)
+	Nodes(q *consul.QueryOptions) ([]*consul.Node, *consul.QueryMeta, error)
+	Register(reg *consul.CatalogRegistration, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	Deregister(dereg *consul.CatalogDeregistration, q *consul.WriteOptions) (*consul.WriteMeta, error)
+}
+
+//ServiceInstance represents a service instance registered in the consul catalog.
+type ServiceInstance struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Node        string            `json:"node"`
+	NodeAddress string            `json:"nodeAddress"`
+	Datacenter  string            `json:"dc"`
+	Address     string            `json:"address"`
+	Port        int               `json:"port"`
+	Tags        []string          `json:"tags"`
+	Meta        map[string]string `json:"meta"`
+	NodeMeta    map[string]string `json:"nodeMeta"`
+}
+
+//Node represents a node registered in the consul catalog.
+type Node struct {
+	ID              string            `json:"id"`
+	Node            string            `json:"node"`
+	Address         string            `json:"address"`
+	Datacenter      string            `json:"dc"`
+	TaggedAddresses map[string]string `json:"taggedAddresses"`
+	Meta            map[string]string `json:"meta"`
+}
+
+//CatalogEntity represents an external node, and optionally one of its services, to register in the catalog.
+//Service checks are stored in the catalog as they are, consul agents do not run them.
+type CatalogEntity struct {
+	Node     string               `json:"node"`
+	Address  string               `json:"address"`
+	NodeMeta map[string]string    `json:"nodeMeta"`
+	Service  *ServiceRegistration `json:"service,omitempty"`
+}
+
+//Validate returns the problems found in the catalog entity.
+func (e CatalogEntity) Validate() []string {
+	errors := []string{}
+	if "" == e.Node {
+		errors = append(errors, "node is missing")
+	}
+	if "" == e.Address {
+		errors = append(errors, "address is missing")
+	}
+	errors = append(errors, validateMeta(e.NodeMeta)...)
+	if nil != e.Service {
+		errors = append(errors, e.Service.Validate()...)
+		for index, check := range e.Service.Checks {
+			if "" != check.TTL {
+				errors = append(errors, fmt.Sprintf("check %d: ttl checks cannot be registered in the catalog", index))
+			}
+		}
+	}
+	return errors
+}
+
+func (c *consulClient) ListServices(options QueryOptions) (map[string][]string, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	services, _, err := c.catalogClient.Services(q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	return services, nil
+}
+
+func (c *consulClient) GetService(name string, tags []string, options QueryOptions) ([]ServiceInstance, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	services, _, err := c.catalogClient.ServiceMultipleTags(name, tags, q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to get service: %v", err)
+	}
+
+	retval := make([]ServiceInstance, len(services))
+	for index, service := range services {
+		retval[index] = toServiceInstance(service)
+	}
+	return retval, nil
+}
+
+func (c *consulClient) ListNodes(options QueryOptions) ([]Node, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	nodes, _, err := c.catalogClient.Nodes(q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	retval := make([]Node, len(nodes))
+	for index, node := range nodes {
+		retval[index] = toNode(node)
+	}
+	return retval, nil
+}
+
+func toServiceInstance(service *consul.CatalogService) ServiceInstance {
+	address := service.ServiceAddress
+	if "" == address {
+		address = service.Address
+	}
+
+	return ServiceInstance{
+		ID:          service.ServiceID,
+		Name:        service.ServiceName,
+		Node:        service.Node,
+		NodeAddress: service.Address,
+		Datacenter:  service.Datacenter,
+		Address:     address,
+		Port:        service.ServicePort,
+		Tags:        service.ServiceTags,
+		Meta:        service.ServiceMeta,
+		NodeMeta:    service.NodeMeta,
+	}
+}
+
+func toNode(node *consul.Node) Node {
+	return Node{
+		ID:              node.ID,
+		Node:            node.Node,
+		Address:         node.Address,
+		Datacenter:      node.Datacenter,
+		TaggedAddresses: node.TaggedAddresses,
+		Meta:            node.Meta,
+	}
+}
+
+func (c *consulClient) RegisterCatalogEntity(entity CatalogEntity, options WriteOptions) error {
+	registration := &consul.CatalogRegistration{
+		Node:       entity.Node,
+		Address:    entity.Address,
+		NodeMeta:   entity.NodeMeta,
+		Datacenter: options.Datacenter,
+	}
+	if nil != entity.Service {
+		registration.Service = &consul.AgentService{
+			ID:      entity.Service.ID,
+			Service: entity.Service.Name,
+			Address: entity.Service.Address,
+			Port:    entity.Service.Port,
+			Tags:    entity.Service.Tags,
+			Meta:    entity.Service.Meta,
+		}
+		serviceID := entity.Service.ID
+		if "" == serviceID {
+			serviceID = entity.Service.Name
+		}
+		for _, check := range entity.Service.Checks {
+			registration.Checks = append(registration.Checks, toCatalogHealthCheck(entity.Node, serviceID, check))
+		}
+	}
+
+	if _, err := c.catalogClient.Register(registration, toWriteOptions(options)); nil != err {
+		return fmt.Errorf("failed to register catalog entity: %v", err)
+	}
+	return nil
+}
+
+func (c *consulClient) DeregisterCatalogEntity(node, serviceID string, options WriteOptions) error {
+	deregistration := &consul.CatalogDeregistration{
+		Node:       node,
+		ServiceID:  serviceID,
+		Datacenter: options.Datacenter,
+	}
+
+	if _, err := c.catalogClient.Deregister(deregistration, toWriteOptions(options)); nil != err {
+		return fmt.Errorf("failed to deregister catalog entity: %v", err)
+	}
+	return nil
+}
+
+func toCatalogHealthCheck(node, serviceID string, check CheckDefinition) *consul.HealthCheck {
+	// durations are validated beforehand, unparseable values fall back to consul defaults
+	interval, _ := time.ParseDuration(check.Interval)
+	timeout, _ := time.ParseDuration(check.Timeout)
+	deregisterAfter, _ := time.ParseDuration(check.DeregisterCriticalServiceAfter)
+
+	status := check.Status
+	if "" == status {
+		status = consul.HealthCritical
+	}
+
+	return &consul.HealthCheck{
+		Node:      node,
+		CheckID:   check.CheckID,
+		Name:      check.Name,
+		Status:    status,
+		Notes:     check.Notes,
+		ServiceID: serviceID,
+		Definition: consul.HealthCheckDefinition{
+			HTTP:                                   check.HTTP,
+			Method:                                 check.Method,
+			TCP:                                    check.TCP,
+			IntervalDuration:                       interval,
+			TimeoutDuration:                        timeout,
+			DeregisterCriticalServiceAfterDuration: deregisterAfter,
+		},
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListServices(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.ServicesFunc = func(q *consul.QueryOptions) (map[string][]string, *consul.QueryMeta, error) {
+		require.NotNil(t, q)
+		assert.Equal(t, "dc", q.Datacenter)
+		assert.Equal(t, "ServiceTags contains \"prod\"", q.Filter)
+		return map[string][]string{"web": {"prod"}}, nil, nil
+	}
+
+	services, err := ConsulImpl.ListServices(QueryOptions{Datacenter: "dc", Filter: "ServiceTags contains \"prod\""})
+	require.Nil(t, err)
+	assert.Equal(t, map[string][]string{"web": {"prod"}}, services)
+}
+
+func TestListServicesFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.ServicesFunc = func(q *consul.QueryOptions) (map[string][]string, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	services, err := ConsulImpl.ListServices(QueryOptions{})
+	assert.Nil(t, services)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to list services: kablammo", err.Error())
+}
+
+func TestGetService(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.ServiceMultipleTagsFunc = func(service string, tags []string, q *consul.QueryOptions) ([]*consul.CatalogService, *consul.QueryMeta, error) {
+		assert.Equal(t, "web", service)
+		assert.Equal(t, []string{"prod"}, tags)
+		require.NotNil(t, q)
+		assert.Equal(t, "_agent", q.Near)
+		return []*consul.CatalogService{
+			{
+				ServiceID:      "web-1",
+				ServiceName:    "web",
+				Node:           "node-1",
+				Address:        "10.0.0.1",
+				Datacenter:     "dc",
+				ServiceAddress: "10.0.1.1",
+				ServicePort:    8080,
+				ServiceTags:    []string{"prod"},
+				ServiceMeta:    map[string]string{"version": "1"},
+				NodeMeta:       map[string]string{"rack": "a"},
+			},
+			{
+				ServiceID:   "web-2",
+				ServiceName: "web",
+				Node:        "node-2",
+				Address:     "10.0.0.2",
+				ServicePort: 8080,
+			},
+		}, nil, nil
+	}
+
+	instances, err := ConsulImpl.GetService("web", []string{"prod"}, QueryOptions{Near: "_agent"})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(instances))
+	assert.Equal(t, ServiceInstance{
+		ID:          "web-1",
+		Name:        "web",
+		Node:        "node-1",
+		NodeAddress: "10.0.0.1",
+		Datacenter:  "dc",
+		Address:     "10.0.1.1",
+		Port:        8080,
+		Tags:        []string{"prod"},
+		Meta:        map[string]string{"version": "1"},
+		NodeMeta:    map[string]string{"rack": "a"},
+	}, instances[0])
+	assert.Equal(t, "10.0.0.2", instances[1].Address)
+}
+
+func TestGetServiceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.ServiceMultipleTagsFunc = func(service string, tags []string, q *consul.QueryOptions) ([]*consul.CatalogService, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	instances, err := ConsulImpl.GetService("web", nil, QueryOptions{})
+	assert.Nil(t, instances)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to get service: kablammo", err.Error())
+}
+
+func TestListNodes(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.NodesFunc = func(q *consul.QueryOptions) ([]*consul.Node, *consul.QueryMeta, error) {
+		return []*consul.Node{
+			{
+				ID:         "id",
+				Node:       "node-1",
+				Address:    "10.0.0.1",
+				Datacenter: "dc",
+				Meta:       map[string]string{"rack": "a"},
+			},
+		}, nil, nil
+	}
+
+	nodes, err := ConsulImpl.ListNodes(QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(nodes))
+	assert.Equal(t, "node-1", nodes[0].Node)
+	assert.Equal(t, "10.0.0.1", nodes[0].Address)
+	assert.Equal(t, map[string]string{"rack": "a"}, nodes[0].Meta)
+}
+
+func TestListNodesFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.NodesFunc = func(q *consul.QueryOptions) ([]*consul.Node, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	nodes, err := ConsulImpl.ListNodes(QueryOptions{})
+	assert.Nil(t, nodes)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to list nodes: kablammo", err.Error())
+}
+
+func TestRegisterCatalogEntity(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.RegisterFunc = func(reg *consul.CatalogRegistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "external", reg.Node)
+		assert.Equal(t, "db.example.com", reg.Address)
+		assert.Equal(t, "dc", reg.Datacenter)
+		require.NotNil(t, reg.Service)
+		assert.Equal(t, "db", reg.Service.Service)
+		require.Equal(t, 1, len(reg.Checks))
+		assert.Equal(t, "external", reg.Checks[0].Node)
+		assert.Equal(t, "db-1", reg.Checks[0].ServiceID)
+		assert.Equal(t, consul.HealthCritical, reg.Checks[0].Status)
+		assert.Equal(t, "db.example.com:5432", reg.Checks[0].Definition.TCP)
+		assert.Equal(t, 10*time.Second, reg.Checks[0].Definition.IntervalDuration)
+		require.NotNil(t, q)
+		assert.Equal(t, "dc", q.Datacenter)
+		return nil, nil
+	}
+
+	service := getServiceRegistration()
+	err := ConsulImpl.RegisterCatalogEntity(CatalogEntity{
+		Node:    "external",
+		Address: "db.example.com",
+		Service: &service,
+	}, WriteOptions{Datacenter: "dc"})
+	assert.Nil(t, err)
+}
+
+func TestRegisterCatalogEntityFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.RegisterFunc = func(reg *consul.CatalogRegistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Nil(t, reg.Service)
+		return nil, errors.New("kablammo")
+	}
+
+	err := ConsulImpl.RegisterCatalogEntity(CatalogEntity{Node: "external", Address: "db.example.com"}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to register catalog entity: kablammo", err.Error())
+}
+
+func TestDeregisterCatalogEntity(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.DeregisterFunc = func(dereg *consul.CatalogDeregistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "external", dereg.Node)
+		assert.Equal(t, "db-1", dereg.ServiceID)
+		assert.Equal(t, "dc", dereg.Datacenter)
+		return nil, nil
+	}
+
+	assert.Nil(t, ConsulImpl.DeregisterCatalogEntity("external", "db-1", WriteOptions{Datacenter: "dc"}))
+}
+
+func TestDeregisterCatalogEntityFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockCatalogClient.DeregisterFunc = func(dereg *consul.CatalogDeregistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		return nil, errors.New("kablammo")
+	}
+
+	err := ConsulImpl.DeregisterCatalogEntity("external", "", WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to deregister catalog entity: kablammo", err.Error())
+}
+
+func TestCatalogEntityValidate(t *testing.T) {
+	service := getServiceRegistration()
+	assert.Equal(t, 0, len(CatalogEntity{Node: "external", Address: "db.example.com", Service: &service}.Validate()))
+
+	service.Checks = []CheckDefinition{{TTL: "10s"}}
+	assert.Equal(t, []string{
+		"node is missing",
+		"address is missing",
+		"check 0: ttl checks cannot be registered in the catalog",
+	}, CatalogEntity{Service: &service}.Validate())
+}
+
+type MockCatalogClient struct {
+	t                       *testing.T
+	ServicesFunc            func(q *consul.QueryOptions) (map[string][]string, *consul.QueryMeta, error)
+	ServiceMultipleTagsFunc func(service string, tags []string, q *consul.QueryOptions) ([]*consul.CatalogService, *consul.QueryMeta, error)
+	NodesFunc               func(q *consul.QueryOptions) ([]*consul.Node, *consul.QueryMeta, error)
+	RegisterFunc            func(reg *consul.CatalogRegistration, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	DeregisterFunc          func(dereg *consul.CatalogDeregistration, q *consul.WriteOptions) (*consul.WriteMeta, error)
+}
+
+func NewMockCatalogClient(t *testing.T) *MockCatalogClient {
+	return &MockCatalogClient{t: t}
+}
+
+func (m *MockCatalogClient) Services(q *consul.QueryOptions) (map[string][]string, *consul.QueryMeta, error) {
+	return m.ServicesFunc(q)
+}
+
+func (m *MockCatalogClient) ServiceMultipleTags(service string, tags []string, q *consul.QueryOptions) ([]*consul.CatalogService, *consul.QueryMeta, error) {
+	return m.ServiceMultipleTagsFunc(service, tags, q)
+}
+
+func (m *MockCatalogClient) Nodes(q *consul.QueryOptions) ([]*consul.Node, *consul.QueryMeta, error) {
+	return m.NodesFunc(q)
+}
+
+func (m *MockCatalogClient) Register(reg *consul.CatalogRegistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.RegisterFunc(reg, q)
+}
+
+func (m *MockCatalogClient) Deregister(dereg *consul.CatalogDeregistration, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.DeregisterFunc(dereg, q)
+}
@@ -14,8 +14,6 @@ limitations under the License.
 package client
 
 import (
-	"fmt"
-
 	"github.com/HotelsDotCom/go-logger"
 	consul "github.com/hashicorp/consul/api"
 )
@@ -26,52 +24,124 @@ type txnClient interface {
 
 //Consul represents the consul client.
 type Consul interface {
-	KVTransact(datacenter string, operations []KVOperation) ([]KVTransactionResult, []KVTransactionError, error)
+	KVTransact(operations []KVOperation, options WriteOptions) ([]KVTransactionResult, []KVTransactionError, error)
+	KVTransactBatches(operations []KVOperation, compensate bool, options WriteOptions) ([]KVTransactionResult, []KVTransactionError, *KVPartialTransaction, error)
+	KVTransactPlan(operations []KVOperation, options WriteOptions) (*KVTransactionPlan, error)
 	IsVerbSupported(verb string) bool
+	Transact(operations []TxnOperation, options WriteOptions) ([]TxnResult, []TxnError, error)
+	GetKV(key, encoding string, options QueryOptions) (*KVPair, error)
+	ListKV(prefix, encoding string, options QueryOptions) ([]KVPair, error)
+	ListKVKeys(prefix string, options QueryOptions) ([]string, error)
+	WatchKV(key string, recurse bool, options QueryOptions) ([]KVPair, uint64, error)
+	PutKV(put KVPut, options WriteOptions) (bool, error)
+	DeleteKV(del KVDelete, options WriteOptions) (bool, error)
+	ListServices(options QueryOptions) (map[string][]string, error)
+	GetService(name string, tags []string, options QueryOptions) ([]ServiceInstance, error)
+	ListNodes(options QueryOptions) ([]Node, error)
+	GetHealthyService(name string, tags []string, passingOnly bool, options QueryOptions) ([]ServiceInstanceHealth, error)
+	WatchHealthChecks(service string, options QueryOptions) ([]HealthCheck, uint64, error)
+	RegisterService(service ServiceRegistration) error
+	DeregisterService(serviceID string) error
+	RegisterCatalogEntity(entity CatalogEntity, options WriteOptions) error
+	DeregisterCatalogEntity(node, serviceID string, options WriteOptions) error
+	EnableMaintenance(serviceID, reason string) (*HealthCheck, error)
+	DisableMaintenance(serviceID string) error
+	GetMaintenance(serviceID string) (*HealthCheck, error)
+	CreateSession(request SessionRequest, options WriteOptions) (string, error)
+	RenewSession(id string, options WriteOptions) (*Session, error)
+	DestroySession(id string, options WriteOptions) (bool, error)
+	GetSession(id string, options QueryOptions) (*Session, error)
+	ListSessions(node string, options QueryOptions) ([]Session, error)
+	AcquireLock(request LockRequest, options WriteOptions) (bool, Lock, error)
+	ReleaseLock(key, session string, options WriteOptions) (bool, Lock, error)
+	RenewLock(key, session string, options WriteOptions) (bool, Lock, error)
+	AcquireSemaphore(request SemaphoreRequest, options WriteOptions) (bool, Semaphore, error)
+	ReleaseSemaphore(prefix, session string, options WriteOptions) (bool, Semaphore, error)
+	CreateACLToken(request ACLTokenRequest, options WriteOptions) (*ACLToken, error)
+	ReadACLToken(accessorID string, options QueryOptions) (*ACLToken, error)
+	UpdateACLToken(accessorID string, request ACLTokenRequest, options WriteOptions) (*ACLToken, error)
+	DeleteACLToken(accessorID string, options WriteOptions) (bool, error)
+	CloneACLToken(accessorID, description string, options WriteOptions) (*ACLToken, error)
+	UpsertACLPolicy(request ACLPolicyRequest, options WriteOptions) (*ACLPolicy, bool, error)
+	DeleteACLPolicy(name string, options WriteOptions) (bool, error)
+	UpsertACLRole(request ACLRoleRequest, options WriteOptions) (*ACLRole, bool, error)
+	DeleteACLRole(name string, options WriteOptions) (bool, error)
+	FireEvent(request UserEventRequest, options WriteOptions) (string, error)
+	WatchUserEvents(name string, options QueryOptions) ([]UserEvent, uint64, error)
 }
 
 type consulClient struct {
-	txnClient txnClient
+	txnClient         txnClient
+	sessionClient     sessionClient
+	kvClient          kvClient
+	catalogClient     catalogClient
+	healthClient      healthClient
+	agentClient       agentClient
+	maintenanceClient maintenanceClient
+	aclClient         aclClient
+	eventClient       eventClient
 }
 
 //NewConsul produces a new consul client
 func NewConsul() (Consul, error) {
-	client, err := consul.NewClient(consul.DefaultConfig())
+	client, err := newClient(consul.DefaultConfig())
 	if nil != err {
-		logger.Error("failed to initialize consul: %v", err)
+		logger.Errorf("failed to initialize consul: %v", err)
 		return nil, err
 	}
 
 	consul := &consulClient{
-		txnClient: client.Txn(),
+		txnClient:         client.Txn(),
+		sessionClient:     client.Session(),
+		kvClient:          client.KV(),
+		catalogClient:     client.Catalog(),
+		healthClient:      client.Health(),
+		agentClient:       client.Agent(),
+		maintenanceClient: client.Agent(),
+		aclClient:         client.ACL(),
+		eventClient:       client.Event(),
 	}
 
 	logger.Info("initialized consul")
 	return consul, nil
 }
 
-func (c *consulClient) KVTransact(datacenter string, operations []KVOperation) ([]KVTransactionResult, []KVTransactionError, error) {
-	var q *consul.QueryOptions = nil
-	if "" != datacenter {
-		q = &consul.QueryOptions{
-			Datacenter: datacenter,
-		}
+//newClient creates the consul api client and wraps the transport of the http client it ends up with,
+//which consul replaces for unix socket addresses, to send the partition of the requests.
+func newClient(config *consul.Config) (*consul.Client, error) {
+	client, err := consul.NewClient(config)
+	if nil != err {
+		return nil, err
 	}
+	config.HttpClient.Transport = &partitionTransport{next: config.HttpClient.Transport}
+	return client, nil
+}
+
+func (c *consulClient) KVTransact(operations []KVOperation, options WriteOptions) ([]KVTransactionResult, []KVTransactionError, error) {
+	q := toTxnQueryOptions(options)
 	input := consul.TxnOps{}
-	for _, op := range operations {
-		kvOp := toTxnKVOp(op)
+	errors := []KVTransactionError{}
+	for index, op := range operations {
+		kvOp, err := toTxnKVOp(op)
+		if nil != err {
+			errors = append(errors, KVTransactionError{Index: index, Error: err.Error()})
+			continue
+		}
 		input = append(input, &consul.TxnOp{KV: &kvOp})
 	}
+	if 0 != len(errors) {
+		return nil, errors, nil
+	}
 
 	ok, response, _, err := c.txnClient.Txn(input, q)
 	if nil != err {
-		return nil, nil, fmt.Errorf("failed to make transaction request: %v", err)
+		return nil, nil, newRequestError("failed to make transaction request", err)
 	}
 	if !ok {
 		return nil, mapTxnErrorsToKVTransactionErrors(response.Errors, toKVTransactionError), nil
 	}
 
-	return mapTxnResultsToKVTransactionResults(response.Results), nil, nil
+	return mapTxnResultsToKVTransactionResults(response.Results, kvEncodings(operations)), nil, nil
 }
 
 func (c *consulClient) IsVerbSupported(verb string) bool {
@@ -87,13 +157,18 @@ func mapTxnErrorsToKVTransactionErrors(source consul.TxnErrors, f func(*consul.T
 	return target
 }
 
-func mapTxnResultsToKVTransactionResults(source consul.TxnResults) []KVTransactionResult {
+func mapTxnResultsToKVTransactionResults(source consul.TxnResults, encodings kvEncodings) []KVTransactionResult {
 	target := make([]KVTransactionResult, len(source))
 	for index, value := range source {
 		target[index] = KVTransactionResult{
-			Index: index,
-			Key:   value.KV.Key,
-			Value: value.KV.Value,
+			Index:       index,
+			Key:         value.KV.Key,
+			Value:       decodeValue(value.KV.Value, encodings.get(value.KV.Key)),
+			Flags:       value.KV.Flags,
+			CreateIndex: value.KV.CreateIndex,
+			ModifyIndex: value.KV.ModifyIndex,
+			LockIndex:   value.KV.LockIndex,
+			Session:     value.KV.Session,
 		}
 	}
 	return target
@@ -25,12 +25,36 @@ import (
 
 var ConsulImpl Consul
 var ConsulMockClient *MockClient
+var ConsulMockSessionClient *MockSessionClient
+var ConsulMockKVClient *MockKVClient
+var ConsulMockCatalogClient *MockCatalogClient
+var ConsulMockHealthClient *MockHealthClient
+var ConsulMockAgentClient *MockAgentClient
+var ConsulMockMaintenanceClient *MockMaintenanceClient
+var ConsulMockACLClient *MockACLClient
+var ConsulMockEventClient *MockEventClient
 
 func Before(t *testing.T) {
 	loggertest.Init("DEBUG")
 	ConsulImpl, _ = NewConsul()
 	ConsulMockClient = NewMockClient(t)
+	ConsulMockSessionClient = NewMockSessionClient(t)
+	ConsulMockKVClient = NewMockKVClient(t)
+	ConsulMockCatalogClient = NewMockCatalogClient(t)
+	ConsulMockHealthClient = NewMockHealthClient(t)
+	ConsulMockAgentClient = NewMockAgentClient(t)
+	ConsulMockMaintenanceClient = NewMockMaintenanceClient(t)
+	ConsulMockACLClient = NewMockACLClient(t)
+	ConsulMockEventClient = NewMockEventClient(t)
 	ConsulImpl.(*consulClient).txnClient = ConsulMockClient
+	ConsulImpl.(*consulClient).sessionClient = ConsulMockSessionClient
+	ConsulImpl.(*consulClient).kvClient = ConsulMockKVClient
+	ConsulImpl.(*consulClient).catalogClient = ConsulMockCatalogClient
+	ConsulImpl.(*consulClient).healthClient = ConsulMockHealthClient
+	ConsulImpl.(*consulClient).agentClient = ConsulMockAgentClient
+	ConsulImpl.(*consulClient).maintenanceClient = ConsulMockMaintenanceClient
+	ConsulImpl.(*consulClient).aclClient = ConsulMockACLClient
+	ConsulImpl.(*consulClient).eventClient = ConsulMockEventClient
 }
 
 func After() {
@@ -52,7 +76,7 @@ func TestKVTransactWithDC(t *testing.T) {
 		return true, &response, nil, nil
 	}
 
-	result, rollback, err := ConsulImpl.KVTransact("dc", ops)
+	result, rollback, err := ConsulImpl.KVTransact(ops, WriteOptions{Datacenter: "dc"})
 	assert.Nil(t, err)
 	assert.Nil(t, rollback)
 	require.Equal(t, 1, len(result))
@@ -76,7 +100,7 @@ func TestKVTransact(t *testing.T) {
 		return true, &response, nil, nil
 	}
 
-	result, rollback, err := ConsulImpl.KVTransact("", ops)
+	result, rollback, err := ConsulImpl.KVTransact(ops, WriteOptions{})
 	assert.Nil(t, err)
 	assert.Nil(t, rollback)
 	require.Equal(t, 1, len(result))
@@ -98,7 +122,7 @@ func TestKVTransactFailed(t *testing.T) {
 		return false, nil, nil, errors.New("kablammo")
 	}
 
-	result, rollback, err := ConsulImpl.KVTransact("", ops)
+	result, rollback, err := ConsulImpl.KVTransact(ops, WriteOptions{})
 	assert.Nil(t, result)
 	assert.Nil(t, rollback)
 	require.NotNil(t, err)
@@ -119,7 +143,7 @@ func TestKVTransactRollback(t *testing.T) {
 		return false, &response, nil, nil
 	}
 
-	result, rollback, err := ConsulImpl.KVTransact("", ops)
+	result, rollback, err := ConsulImpl.KVTransact(ops, WriteOptions{})
 	assert.Nil(t, result)
 	assert.Nil(t, err)
 	require.Equal(t, 1, len(rollback))
@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+//Value encodings define how JSON values of operations are stored in consul and how stored values are returned.
+const (
+	//EncodingJSON stores the JSON value as is. This is the default.
+	EncodingJSON = "json"
+	//EncodingString stores the content of a JSON string.
+	EncodingString = "string"
+	//EncodingBase64 stores the bytes of a base64 encoded JSON string.
+	EncodingBase64 = "base64"
+	//EncodingYAML stores the JSON value converted to YAML.
+	EncodingYAML = "yaml"
+)
+
+//IsEncodingSupported returns whether the value encoding is valid, an empty encoding being the default.
+func IsEncodingSupported(encoding string) bool {
+	switch encoding {
+	case "", EncodingJSON, EncodingString, EncodingBase64, EncodingYAML:
+		return true
+	}
+	return false
+}
+
+//encodeValue converts a JSON value to the bytes stored in consul.
+func encodeValue(value json.RawMessage, encoding string) ([]byte, error) {
+	if 0 == len(value) {
+		return nil, nil
+	}
+
+	switch encoding {
+	case "", EncodingJSON:
+		return value, nil
+	case EncodingString, EncodingBase64:
+		var text string
+		if err := json.Unmarshal(value, &text); nil != err {
+			return nil, fmt.Errorf("value must be a string with %v encoding", encoding)
+		}
+		if EncodingString == encoding {
+			return []byte(text), nil
+		}
+		data, err := base64.StdEncoding.DecodeString(text)
+		if nil != err {
+			return nil, fmt.Errorf("value is not valid base64: %v", err)
+		}
+		return data, nil
+	case EncodingYAML:
+		var data interface{}
+		if err := json.Unmarshal(value, &data); nil != err {
+			return nil, fmt.Errorf("value is not valid json: %v", err)
+		}
+		return yaml.Marshal(data)
+	}
+	return nil, fmt.Errorf("%v encoding is not valid", encoding)
+}
+
+//decodeValue converts the bytes stored in consul to a JSON value.
+//Values that cannot be decoded are returned as a JSON string so events can always be marshalled.
+func decodeValue(data []byte, encoding string) json.RawMessage {
+	if nil == data {
+		return nil
+	}
+
+	switch encoding {
+	case "", EncodingJSON:
+		if json.Valid(data) {
+			return data
+		}
+	case EncodingBase64:
+		return quoteValue(base64.StdEncoding.EncodeToString(data))
+	case EncodingYAML:
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); nil == err {
+			if retval, err := json.Marshal(fromYAML(value)); nil == err {
+				return retval
+			}
+		}
+	}
+	return quoteValue(string(data))
+}
+
+func quoteValue(text string) json.RawMessage {
+	retval, _ := json.Marshal(text)
+	return retval
+}
+
+//fromYAML converts the maps produced by the yaml decoder to maps that can be marshalled to JSON.
+func fromYAML(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		retval := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			retval[fmt.Sprintf("%v", key)] = fromYAML(item)
+		}
+		return retval
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = fromYAML(item)
+		}
+	}
+	return value
+}
+
+//kvEncodings resolves the encoding of the keys returned by key-value operations.
+type kvEncodings []KVOperation
+
+//get returns the encoding of the operation on the key or,
+//for keys returned by get-tree, of the operation with the longest matching prefix.
+func (e kvEncodings) get(key string) string {
+	retval := ""
+	prefix := -1
+	for _, operation := range e {
+		switch {
+		case key == operation.Key:
+			return operation.Encoding
+		case "get-tree" == operation.Verb && strings.HasPrefix(key, operation.Key) && len(operation.Key) > prefix:
+			retval = operation.Encoding
+			prefix = len(operation.Key)
+		}
+	}
+	return retval
+}
@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsEncodingSupported(t *testing.T) {
+	for _, encoding := range []string{"", "json", "string", "base64", "yaml"} {
+		assert.True(t, IsEncodingSupported(encoding), encoding)
+	}
+	assert.False(t, IsEncodingSupported("xml"))
+}
+
+func TestEncodeValue(t *testing.T) {
+	cases := []struct {
+		value    string
+		encoding string
+		expected string
+	}{
+		{`{"a":1}`, "", `{"a":1}`},
+		{`{"a":1}`, "json", `{"a":1}`},
+		{`"joe mama"`, "string", "joe mama"},
+		{`"aGk="`, "base64", "hi"},
+		{`{"a":[1,2]}`, "yaml", "a:\n- 1\n- 2\n"},
+	}
+	for _, c := range cases {
+		data, err := encodeValue(json.RawMessage(c.value), c.encoding)
+		require.Nil(t, err, c.encoding)
+		assert.Equal(t, c.expected, string(data), c.encoding)
+	}
+}
+
+func TestEncodeValueWithoutValue(t *testing.T) {
+	data, err := encodeValue(nil, "base64")
+	require.Nil(t, err)
+	assert.Nil(t, data)
+}
+
+func TestEncodeValueFailsWithInvalidValues(t *testing.T) {
+	_, err := encodeValue(json.RawMessage(`1`), "string")
+	assert.EqualError(t, err, "value must be a string with string encoding")
+
+	_, err = encodeValue(json.RawMessage(`"!!"`), "base64")
+	assert.Contains(t, err.Error(), "value is not valid base64")
+
+	_, err = encodeValue(json.RawMessage(`"a"`), "xml")
+	assert.EqualError(t, err, "xml encoding is not valid")
+}
+
+func TestDecodeValue(t *testing.T) {
+	cases := []struct {
+		data     string
+		encoding string
+		expected string
+	}{
+		{`{"a":1}`, "", `{"a":1}`},
+		{"not json", "json", `"not json"`},
+		{"joe mama", "string", `"joe mama"`},
+		{"hi", "base64", `"aGk="`},
+		{"a:\n  b: [1, 2]\n", "yaml", `{"a":{"b":[1,2]}}`},
+		{"a: [", "yaml", `"a: ["`},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, string(decodeValue([]byte(c.data), c.encoding)), c.encoding)
+	}
+	assert.Nil(t, decodeValue(nil, "string"))
+}
+
+func TestKVEncodingsUsesExactKeyOrLongestTreePrefix(t *testing.T) {
+	encodings := kvEncodings{
+		{Verb: "get-tree", Key: "joe/", Encoding: "string"},
+		{Verb: "get-tree", Key: "joe/mama/", Encoding: "yaml"},
+		{Verb: "get", Key: "joe/mama/so", Encoding: "base64"},
+		{Verb: "get", Key: "other/", Encoding: "string"},
+	}
+
+	assert.Equal(t, "base64", encodings.get("joe/mama/so"))
+	assert.Equal(t, "yaml", encodings.get("joe/mama/fat"))
+	assert.Equal(t, "string", encodings.get("joe/papa"))
+	assert.Equal(t, "", encodings.get("other/key"))
+}
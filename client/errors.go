@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//Error classes of failed consul requests.
+const (
+	//ErrorForbidden is the class of requests denied by the ACL system.
+	ErrorForbidden = "forbidden"
+	//ErrorUnavailable is the class of requests consul could not serve, e.g. without a leader or when rate limited.
+	ErrorUnavailable = "unavailable"
+	//ErrorInvalid is the class of requests consul rejected, e.g. for an unknown datacenter.
+	ErrorInvalid = "invalid"
+)
+
+var statusCodePattern = regexp.MustCompile(`Unexpected response code: (\d{3})`)
+
+//RequestError is a failed consul request classified by its cause.
+//The class is empty and the status code 0 when they cannot be told from the error.
+type RequestError struct {
+	Class      string
+	StatusCode int
+	Retryable  bool
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+//statusCode returns the status code of a failed consul request, 0 when it cannot be told from the error.
+func statusCode(err error) int {
+	return newRequestError("", err).StatusCode
+}
+
+//newRequestError classifies the error of a consul request.
+func newRequestError(message string, err error) *RequestError {
+	retval := &RequestError{Err: fmt.Errorf("%v: %w", message, err)}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		retval.Class = ErrorUnavailable
+		retval.Retryable = true
+		return retval
+	}
+
+	text := strings.ToLower(err.Error())
+	if match := statusCodePattern.FindStringSubmatch(err.Error()); nil != match {
+		retval.StatusCode, _ = strconv.Atoi(match[1])
+	}
+	switch {
+	case strings.Contains(text, "permission denied"), strings.Contains(text, "acl not found"):
+		retval.StatusCode = http.StatusForbidden
+	case strings.Contains(text, "no cluster leader"), strings.Contains(text, "no path to datacenter"):
+		retval.StatusCode = http.StatusInternalServerError
+	case strings.Contains(text, "rate limit exceeded"):
+		retval.StatusCode = http.StatusTooManyRequests
+	case strings.Contains(text, "too many operations"):
+		retval.StatusCode = http.StatusRequestEntityTooLarge
+	}
+
+	switch {
+	case strings.Contains(text, "no path to datacenter"):
+		retval.Class = ErrorInvalid
+	case http.StatusUnauthorized == retval.StatusCode, http.StatusForbidden == retval.StatusCode:
+		retval.Class = ErrorForbidden
+	case http.StatusTooManyRequests == retval.StatusCode, http.StatusInternalServerError <= retval.StatusCode:
+		retval.Class = ErrorUnavailable
+		retval.Retryable = http.StatusNotImplemented != retval.StatusCode
+	case http.StatusBadRequest <= retval.StatusCode:
+		retval.Class = ErrorInvalid
+	}
+	return retval
+}
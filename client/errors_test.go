@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequestErrorClassifiesErrors(t *testing.T) {
+	cases := []struct {
+		err        error
+		class      string
+		statusCode int
+		retryable  bool
+	}{
+		{fmt.Errorf("Failed request: Permission denied"), ErrorForbidden, 403, false},
+		{fmt.Errorf("Unexpected response code: 403 (ACL not found)"), ErrorForbidden, 403, false},
+		{fmt.Errorf("Failed request: No path to datacenter"), ErrorInvalid, 500, false},
+		{fmt.Errorf("Failed request: rate limit exceeded"), ErrorUnavailable, 429, true},
+		{fmt.Errorf("Failed request: No cluster leader"), ErrorUnavailable, 500, true},
+		{fmt.Errorf("Unexpected response code: 503 (unavailable)"), ErrorUnavailable, 503, true},
+		{fmt.Errorf("Unexpected response code: 501 (not implemented)"), ErrorUnavailable, 501, false},
+		{fmt.Errorf("Failed request: Transaction contains too many operations"), ErrorInvalid, 413, false},
+		{fmt.Errorf("Unexpected response code: 400 (bad request)"), ErrorInvalid, 400, false},
+		{&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, ErrorUnavailable, 0, true},
+		{fmt.Errorf("kablammo"), "", 0, false},
+	}
+
+	for _, c := range cases {
+		err := newRequestError("failed to make request", c.err)
+		assert.Equal(t, c.class, err.Class, c.err.Error())
+		assert.Equal(t, c.statusCode, err.StatusCode, c.err.Error())
+		assert.Equal(t, c.retryable, err.Retryable, c.err.Error())
+		assert.Equal(t, "failed to make request: "+c.err.Error(), err.Error())
+		assert.True(t, errors.Is(err, c.err))
+	}
+}
+
+func TestKVTransactReturnsClassifiedError(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		return false, nil, nil, fmt.Errorf("Failed request: Permission denied")
+	}
+
+	_, _, err := ConsulImpl.KVTransact([]KVOperation{{Verb: "get", Key: "joe/mama"}}, WriteOptions{})
+	var requestErr *RequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, ErrorForbidden, requestErr.Class)
+	assert.Equal(t, "failed to make transaction request: Failed request: Permission denied", err.Error())
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+type eventClient interface {
+	Fire(params *consul.UserEvent, q *consul.WriteOptions) (string, *consul.WriteMeta, error)
+	List(name string, q *consul.QueryOptions) ([]*consul.UserEvent, *consul.QueryMeta, error)
+}
+
+//UserEventRequest represents a consul user event to fire.
+//The filters are regular expressions restricting the agents the event is delivered to,
+//TagFilter requires ServiceFilter. Encoding defines how Payload is sent, as for key-value operations.
+type UserEventRequest struct {
+	Name          string          `json:"name"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	Encoding      string          `json:"encoding,omitempty"`
+	NodeFilter    string          `json:"node,omitempty"`
+	ServiceFilter string          `json:"service,omitempty"`
+	TagFilter     string          `json:"tag,omitempty"`
+}
+
+//UserEvent represents a consul user event.
+type UserEvent struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	NodeFilter    string          `json:"node,omitempty"`
+	ServiceFilter string          `json:"service,omitempty"`
+	TagFilter     string          `json:"tag,omitempty"`
+	Version       int             `json:"version"`
+	LTime         uint64          `json:"ltime"`
+}
+
+//Validate returns the list of problems with the event request.
+func (r UserEventRequest) Validate() []string {
+	errors := []string{}
+	if "" != r.TagFilter && "" == r.ServiceFilter {
+		errors = append(errors, "tag filter requires a service filter")
+	}
+	if !IsEncodingSupported(r.Encoding) {
+		errors = append(errors, fmt.Sprintf("%v encoding is not valid", r.Encoding))
+	} else if _, err := encodeValue(r.Payload, r.Encoding); nil != err {
+		errors = append(errors, err.Error())
+	}
+	return errors
+}
+
+//FireEvent fires a user event and returns its ID.
+func (c *consulClient) FireEvent(request UserEventRequest, options WriteOptions) (string, error) {
+	payload, err := encodeValue(request.Payload, request.Encoding)
+	if nil != err {
+		return "", fmt.Errorf("failed to fire event: %v", err)
+	}
+
+	event := &consul.UserEvent{
+		Name:          request.Name,
+		Payload:       payload,
+		NodeFilter:    request.NodeFilter,
+		ServiceFilter: request.ServiceFilter,
+		TagFilter:     request.TagFilter,
+	}
+	id, _, err := c.eventClient.Fire(event, toWriteOptions(options))
+	if nil != err {
+		return "", fmt.Errorf("failed to fire event: %v", err)
+	}
+	return id, nil
+}
+
+//WatchUserEvents returns the recent user events with the name, or every recent event when name is empty,
+//and the index to block on. Payloads are decoded as JSON, falling back to a JSON string.
+func (c *consulClient) WatchUserEvents(name string, options QueryOptions) ([]UserEvent, uint64, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, 0, err
+	}
+
+	events, meta, err := c.eventClient.List(name, q)
+	if nil != err {
+		return nil, 0, fmt.Errorf("failed to watch events: %v", err)
+	}
+
+	retval := make([]UserEvent, len(events))
+	for index, event := range events {
+		retval[index] = UserEvent{
+			ID:            event.ID,
+			Name:          event.Name,
+			Payload:       decodeValue(event.Payload, EncodingJSON),
+			NodeFilter:    event.NodeFilter,
+			ServiceFilter: event.ServiceFilter,
+			TagFilter:     event.TagFilter,
+			Version:       event.Version,
+			LTime:         event.LTime,
+		}
+	}
+	return retval, meta.LastIndex, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserEventRequestValidate(t *testing.T) {
+	request := UserEventRequest{Name: "deploy", TagFilter: "canary", Payload: json.RawMessage(`{}`), Encoding: EncodingString}
+
+	assert.Equal(t, []string{
+		"tag filter requires a service filter",
+		"value must be a string with string encoding",
+	}, request.Validate())
+	assert.Empty(t, UserEventRequest{Name: "deploy", ServiceFilter: "web", TagFilter: "canary"}.Validate())
+}
+
+func TestFireEvent(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockEventClient.FireFunc = func(params *consul.UserEvent, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		assert.Equal(t, "dc", q.Datacenter)
+		assert.Equal(t, "deploy", params.Name)
+		assert.Equal(t, "v1.2", string(params.Payload))
+		assert.Equal(t, "web", params.ServiceFilter)
+		assert.Equal(t, "canary", params.TagFilter)
+		assert.Equal(t, "node-.*", params.NodeFilter)
+		return "event-id", nil, nil
+	}
+
+	request := UserEventRequest{
+		Name:          "deploy",
+		Payload:       json.RawMessage(`"v1.2"`),
+		Encoding:      EncodingString,
+		NodeFilter:    "node-.*",
+		ServiceFilter: "web",
+		TagFilter:     "canary",
+	}
+	id, err := ConsulImpl.FireEvent(request, WriteOptions{Datacenter: "dc"})
+	require.Nil(t, err)
+	assert.Equal(t, "event-id", id)
+}
+
+func TestFireEventFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockEventClient.FireFunc = func(params *consul.UserEvent, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "", nil, errors.New("kablammo")
+	}
+
+	id, err := ConsulImpl.FireEvent(UserEventRequest{Name: "deploy"}, WriteOptions{})
+	assert.Equal(t, "", id)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to fire event: kablammo", err.Error())
+}
+
+func TestWatchUserEvents(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockEventClient.ListFunc = func(name string, q *consul.QueryOptions) ([]*consul.UserEvent, *consul.QueryMeta, error) {
+		assert.Equal(t, "deploy", name)
+		assert.Equal(t, uint64(7), q.WaitIndex)
+		return []*consul.UserEvent{
+			{ID: "1", Name: "deploy", Payload: []byte(`{"version": "v1.2"}`), LTime: 3},
+			{ID: "2", Name: "deploy", Payload: []byte(`v1.3`), LTime: 4},
+			{ID: "3", Name: "deploy", LTime: 5},
+		}, &consul.QueryMeta{LastIndex: 42}, nil
+	}
+
+	events, index, err := ConsulImpl.WatchUserEvents("deploy", QueryOptions{WaitIndex: 7})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(42), index)
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, `{"version": "v1.2"}`, string(events[0].Payload))
+	assert.Equal(t, `"v1.3"`, string(events[1].Payload))
+	assert.Nil(t, events[2].Payload)
+	assert.Equal(t, uint64(4), events[1].LTime)
+}
+
+func TestWatchUserEventsFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockEventClient.ListFunc = func(name string, q *consul.QueryOptions) ([]*consul.UserEvent, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	events, _, err := ConsulImpl.WatchUserEvents("", QueryOptions{})
+	assert.Nil(t, events)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to watch events: kablammo", err.Error())
+}
+
+type MockEventClient struct {
+	t        *testing.T
+	FireFunc func(params *consul.UserEvent, q *consul.WriteOptions) (string, *consul.WriteMeta, error)
+	ListFunc func(name string, q *consul.QueryOptions) ([]*consul.UserEvent, *consul.QueryMeta, error)
+}
+
+func NewMockEventClient(t *testing.T) *MockEventClient {
+	return &MockEventClient{t: t}
+}
+
+func (m *MockEventClient) Fire(params *consul.UserEvent, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+	return m.FireFunc(params, q)
+}
+
+func (m *MockEventClient) List(name string, q *consul.QueryOptions) ([]*consul.UserEvent, *consul.QueryMeta, error) {
+	return m.ListFunc(name, q)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+type healthClient interface {
+	ServiceMultipleTags(service string, tags []string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error)
+	Checks(service string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error)
+	State(state string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error)
+}
+
+//HealthCheck represents a consul health check.
+type HealthCheck struct {
+	Node        string `json:"node"`
+	CheckID     string `json:"checkId"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Notes       string `json:"notes,omitempty"`
+	Output      string `json:"output"`
+	ServiceID   string `json:"serviceId"`
+	ServiceName string `json:"serviceName"`
+}
+
+//ServiceInstanceHealth represents a service instance along with its health checks.
+//Status is the aggregated status of all checks.
+type ServiceInstanceHealth struct {
+	ServiceInstance
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func (c *consulClient) GetHealthyService(name string, tags []string, passingOnly bool, options QueryOptions) ([]ServiceInstanceHealth, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	entries, _, err := c.healthClient.ServiceMultipleTags(name, tags, passingOnly, q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to get service health: %v", err)
+	}
+
+	retval := make([]ServiceInstanceHealth, len(entries))
+	for index, entry := range entries {
+		retval[index] = toServiceInstanceHealth(entry)
+	}
+	return retval, nil
+}
+
+//WatchHealthChecks returns the checks of the given service, or all checks when service is empty.
+func (c *consulClient) WatchHealthChecks(service string, options QueryOptions) ([]HealthCheck, uint64, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, 0, err
+	}
+
+	var checks consul.HealthChecks
+	var meta *consul.QueryMeta
+	if "" == service {
+		checks, meta, err = c.healthClient.State(consul.HealthAny, q)
+	} else {
+		checks, meta, err = c.healthClient.Checks(service, q)
+	}
+	if nil != err {
+		return nil, 0, fmt.Errorf("failed to watch health checks: %v", err)
+	}
+
+	retval := make([]HealthCheck, len(checks))
+	for index, check := range checks {
+		retval[index] = toHealthCheck(check)
+	}
+	return retval, meta.LastIndex, nil
+}
+
+func toServiceInstanceHealth(entry *consul.ServiceEntry) ServiceInstanceHealth {
+	instance := ServiceInstance{
+		ID:          entry.Service.ID,
+		Name:        entry.Service.Service,
+		Node:        entry.Node.Node,
+		NodeAddress: entry.Node.Address,
+		Datacenter:  entry.Node.Datacenter,
+		Address:     entry.Service.Address,
+		Port:        entry.Service.Port,
+		Tags:        entry.Service.Tags,
+		Meta:        entry.Service.Meta,
+		NodeMeta:    entry.Node.Meta,
+	}
+	if "" == instance.Address {
+		instance.Address = entry.Node.Address
+	}
+
+	checks := make([]HealthCheck, len(entry.Checks))
+	for index, check := range entry.Checks {
+		checks[index] = toHealthCheck(check)
+	}
+
+	return ServiceInstanceHealth{
+		ServiceInstance: instance,
+		Status:          entry.Checks.AggregatedStatus(),
+		Checks:          checks,
+	}
+}
+
+func toHealthCheck(check *consul.HealthCheck) HealthCheck {
+	return HealthCheck{
+		Node:        check.Node,
+		CheckID:     check.CheckID,
+		Name:        check.Name,
+		Status:      check.Status,
+		Notes:       check.Notes,
+		Output:      check.Output,
+		ServiceID:   check.ServiceID,
+		ServiceName: check.ServiceName,
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHealthyService(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockHealthClient.ServiceMultipleTagsFunc = func(service string, tags []string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+		assert.Equal(t, "web", service)
+		assert.Equal(t, []string{"prod"}, tags)
+		assert.True(t, passingOnly)
+		require.NotNil(t, q)
+		assert.Equal(t, "Service.Meta.version == \"1\"", q.Filter)
+		return []*consul.ServiceEntry{
+			{
+				Node: &consul.Node{Node: "node-1", Address: "10.0.0.1", Datacenter: "dc"},
+				Service: &consul.AgentService{
+					ID:      "web-1",
+					Service: "web",
+					Port:    8080,
+					Tags:    []string{"prod"},
+				},
+				Checks: consul.HealthChecks{
+					{CheckID: "serfHealth", Status: consul.HealthPassing},
+					{CheckID: "web-check", Status: consul.HealthWarning, Output: "slow", ServiceID: "web-1"},
+				},
+			},
+		}, nil, nil
+	}
+
+	instances, err := ConsulImpl.GetHealthyService("web", []string{"prod"}, true, QueryOptions{Filter: "Service.Meta.version == \"1\""})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(instances))
+	assert.Equal(t, "web-1", instances[0].ID)
+	assert.Equal(t, "web", instances[0].Name)
+	assert.Equal(t, "node-1", instances[0].Node)
+	assert.Equal(t, "10.0.0.1", instances[0].Address)
+	assert.Equal(t, 8080, instances[0].Port)
+	assert.Equal(t, consul.HealthWarning, instances[0].Status)
+	require.Equal(t, 2, len(instances[0].Checks))
+	assert.Equal(t, "web-check", instances[0].Checks[1].CheckID)
+	assert.Equal(t, "slow", instances[0].Checks[1].Output)
+}
+
+func TestGetHealthyServiceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockHealthClient.ServiceMultipleTagsFunc = func(service string, tags []string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	instances, err := ConsulImpl.GetHealthyService("web", nil, true, QueryOptions{})
+	assert.Nil(t, instances)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to get service health: kablammo", err.Error())
+}
+
+func TestWatchHealthChecksAll(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockHealthClient.StateFunc = func(state string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error) {
+		assert.Equal(t, consul.HealthAny, state)
+		require.NotNil(t, q)
+		assert.Equal(t, uint64(3), q.WaitIndex)
+		return consul.HealthChecks{{Node: "node-1", CheckID: "serfHealth", Status: consul.HealthPassing}}, &consul.QueryMeta{LastIndex: 4}, nil
+	}
+
+	checks, index, err := ConsulImpl.WatchHealthChecks("", QueryOptions{WaitIndex: 3})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(4), index)
+	require.Equal(t, 1, len(checks))
+	assert.Equal(t, "serfHealth", checks[0].CheckID)
+}
+
+func TestWatchHealthChecksService(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockHealthClient.ChecksFunc = func(service string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error) {
+		assert.Equal(t, "web", service)
+		return consul.HealthChecks{{CheckID: "web-check", ServiceID: "web-1", Status: consul.HealthCritical}}, &consul.QueryMeta{LastIndex: 4}, nil
+	}
+
+	checks, index, err := ConsulImpl.WatchHealthChecks("web", QueryOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(4), index)
+	require.Equal(t, 1, len(checks))
+	assert.Equal(t, consul.HealthCritical, checks[0].Status)
+}
+
+func TestWatchHealthChecksFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockHealthClient.ChecksFunc = func(service string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	checks, _, err := ConsulImpl.WatchHealthChecks("web", QueryOptions{})
+	assert.Nil(t, checks)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to watch health checks: kablammo", err.Error())
+}
+
+type MockHealthClient struct {
+	t                       *testing.T
+	ServiceMultipleTagsFunc func(service string, tags []string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error)
+	ChecksFunc              func(service string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error)
+	StateFunc               func(state string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error)
+}
+
+func NewMockHealthClient(t *testing.T) *MockHealthClient {
+	return &MockHealthClient{t: t}
+}
+
+func (m *MockHealthClient) ServiceMultipleTags(service string, tags []string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+	return m.ServiceMultipleTagsFunc(service, tags, passingOnly, q)
+}
+
+func (m *MockHealthClient) Checks(service string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error) {
+	return m.ChecksFunc(service, q)
+}
+
+func (m *MockHealthClient) State(state string, q *consul.QueryOptions) (consul.HealthChecks, *consul.QueryMeta, error) {
+	return m.StateFunc(state, q)
+}
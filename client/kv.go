@@ -15,15 +15,38 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 
 	consul "github.com/hashicorp/consul/api"
 )
 
+type kvClient interface {
+	Get(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error)
+	List(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error)
+	Keys(prefix, separator string, q *consul.QueryOptions) ([]string, *consul.QueryMeta, error)
+	Put(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	CAS(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	Acquire(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	Release(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	Delete(key string, w *consul.WriteOptions) (*consul.WriteMeta, error)
+	DeleteCAS(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	DeleteTree(prefix string, w *consul.WriteOptions) (*consul.WriteMeta, error)
+}
+
 //KVOperation represents a consul key-value operation.
+//Index is required by the cas, delete-cas and check-index verbs,
+//Session by the lock, unlock and check-session verbs.
+//Encoding defines how Value is stored and how the returned value is decoded.
+//Sensitive values, written or read, are redacted from the events of the commands.
 type KVOperation struct {
-	Verb  string          `json:"verb"`
-	Key   string          `json:"key"`
-	Value json.RawMessage `json:"value"`
+	Verb      string          `json:"verb"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Flags     uint64          `json:"flags,omitempty"`
+	Index     *uint64         `json:"index,omitempty"`
+	Session   string          `json:"session,omitempty"`
+	Encoding  string          `json:"encoding,omitempty"`
+	Sensitive bool            `json:"sensitive,omitempty"`
 }
 
 //KVTransactionError represents a key-value transaction error.
@@ -34,17 +57,243 @@ type KVTransactionError struct {
 
 //KVTransactionResult represents a key-value transaction result.
 type KVTransactionResult struct {
-	Index int             `json:"index"`
-	Key   string          `json:"key"`
-	Value json.RawMessage `json:"value"`
+	Index       int             `json:"index"`
+	Key         string          `json:"key"`
+	Value       json.RawMessage `json:"value"`
+	Flags       uint64          `json:"flags"`
+	CreateIndex uint64          `json:"createIndex"`
+	ModifyIndex uint64          `json:"modifyIndex"`
+	LockIndex   uint64          `json:"lockIndex"`
+	Session     string          `json:"session,omitempty"`
+}
+
+//Validate returns the requirements of the operation verb that are not met.
+func (o KVOperation) Validate() []string {
+	errors := []string{}
+	switch getSupportedVerbs()[o.Verb] {
+	case consul.KVCAS, consul.KVDeleteCAS, consul.KVCheckIndex:
+		if nil == o.Index {
+			errors = append(errors, fmt.Sprintf("%v verb requires an index", o.Verb))
+		}
+	case consul.KVLock, consul.KVUnlock, consul.KVCheckSession:
+		if "" == o.Session {
+			errors = append(errors, fmt.Sprintf("%v verb requires a session", o.Verb))
+		}
+	}
+	if !IsEncodingSupported(o.Encoding) {
+		errors = append(errors, fmt.Sprintf("%v encoding is not valid", o.Encoding))
+	} else if _, err := encodeValue(o.Value, o.Encoding); nil != err {
+		errors = append(errors, err.Error())
+	}
+	return errors
+}
+
+//KVPair represents a consul key-value pair.
+type KVPair struct {
+	Key         string          `json:"key"`
+	Value       json.RawMessage `json:"value"`
+	Flags       uint64          `json:"flags"`
+	CreateIndex uint64          `json:"createIndex"`
+	ModifyIndex uint64          `json:"modifyIndex"`
+	LockIndex   uint64          `json:"lockIndex"`
+	Session     string          `json:"session,omitempty"`
+}
+
+//KVPut represents a consul key-value write.
+//CAS, Acquire and Release are mutually exclusive. Encoding defines how Value is stored.
+type KVPut struct {
+	Key      string
+	Value    json.RawMessage
+	Flags    uint64
+	CAS      *uint64
+	Acquire  string
+	Release  string
+	Encoding string
+}
+
+//Validate returns the problems with the encoding of the value.
+func (p KVPut) Validate() []string {
+	if !IsEncodingSupported(p.Encoding) {
+		return []string{fmt.Sprintf("%v encoding is not valid", p.Encoding)}
+	}
+	if _, err := encodeValue(p.Value, p.Encoding); nil != err {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+//KVDelete represents a consul key-value delete.
+//CAS cannot be combined with Recurse.
+type KVDelete struct {
+	Key     string
+	Recurse bool
+	CAS     *uint64
+}
+
+func (c *consulClient) GetKV(key, encoding string, options QueryOptions) (*KVPair, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	pair, _, err := c.kvClient.Get(key, q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to get key: %v", err)
+	}
+	if nil == pair {
+		return nil, nil
+	}
+
+	retval := toKVPair(pair)
+	retval.Value = decodeValue(pair.Value, encoding)
+	return &retval, nil
+}
+
+func (c *consulClient) ListKV(prefix, encoding string, options QueryOptions) ([]KVPair, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	pairs, _, err := c.kvClient.List(prefix, q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+
+	retval := make([]KVPair, len(pairs))
+	for index, pair := range pairs {
+		retval[index] = toKVPair(pair)
+		retval[index].Value = decodeValue(pair.Value, encoding)
+	}
+	return retval, nil
+}
+
+func (c *consulClient) ListKVKeys(prefix string, options QueryOptions) ([]string, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	keys, _, err := c.kvClient.Keys(prefix, "", q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+	return keys, nil
+}
+
+func (c *consulClient) WatchKV(key string, recurse bool, options QueryOptions) ([]KVPair, uint64, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, 0, err
+	}
+
+	var pairs consul.KVPairs
+	var meta *consul.QueryMeta
+	if recurse {
+		pairs, meta, err = c.kvClient.List(key, q)
+	} else {
+		var pair *consul.KVPair
+		pair, meta, err = c.kvClient.Get(key, q)
+		if nil != pair {
+			pairs = consul.KVPairs{pair}
+		}
+	}
+	if nil != err {
+		return nil, 0, fmt.Errorf("failed to watch key: %v", err)
+	}
+
+	retval := make([]KVPair, len(pairs))
+	for index, pair := range pairs {
+		retval[index] = toKVPair(pair)
+	}
+	return retval, meta.LastIndex, nil
 }
 
-func toTxnKVOp(kvOp KVOperation) consul.KVTxnOp {
-	return consul.KVTxnOp{
-		Verb:  getSupportedVerbs()[kvOp.Verb],
-		Key:   kvOp.Key,
-		Value: kvOp.Value,
+func (c *consulClient) PutKV(put KVPut, options WriteOptions) (bool, error) {
+	value, err := encodeValue(put.Value, put.Encoding)
+	if nil != err {
+		return false, err
+	}
+
+	w := toWriteOptions(options)
+	pair := &consul.KVPair{
+		Key:   put.Key,
+		Value: value,
+		Flags: put.Flags,
+	}
+
+	var ok bool
+	switch {
+	case "" != put.Acquire:
+		pair.Session = put.Acquire
+		ok, _, err = c.kvClient.Acquire(pair, w)
+	case "" != put.Release:
+		pair.Session = put.Release
+		ok, _, err = c.kvClient.Release(pair, w)
+	case nil != put.CAS:
+		pair.ModifyIndex = *put.CAS
+		ok, _, err = c.kvClient.CAS(pair, w)
+	default:
+		ok = true
+		_, err = c.kvClient.Put(pair, w)
+	}
+	if nil != err {
+		return false, fmt.Errorf("failed to put key: %v", err)
+	}
+	return ok, nil
+}
+
+func (c *consulClient) DeleteKV(del KVDelete, options WriteOptions) (bool, error) {
+	w := toWriteOptions(options)
+
+	var ok bool
+	var err error
+	switch {
+	case del.Recurse:
+		ok = true
+		_, err = c.kvClient.DeleteTree(del.Key, w)
+	case nil != del.CAS:
+		ok, _, err = c.kvClient.DeleteCAS(&consul.KVPair{Key: del.Key, ModifyIndex: *del.CAS}, w)
+	default:
+		ok = true
+		_, err = c.kvClient.Delete(del.Key, w)
+	}
+	if nil != err {
+		return false, fmt.Errorf("failed to delete key: %v", err)
+	}
+	return ok, nil
+}
+
+//toKVPair converts a consul pair, values that are not JSON being returned as a JSON string.
+func toKVPair(pair *consul.KVPair) KVPair {
+	return KVPair{
+		Key:         pair.Key,
+		Value:       decodeValue(pair.Value, EncodingJSON),
+		Flags:       pair.Flags,
+		CreateIndex: pair.CreateIndex,
+		ModifyIndex: pair.ModifyIndex,
+		LockIndex:   pair.LockIndex,
+		Session:     pair.Session,
+	}
+}
+
+func toTxnKVOp(kvOp KVOperation) (consul.KVTxnOp, error) {
+	value, err := encodeValue(kvOp.Value, kvOp.Encoding)
+	if nil != err {
+		return consul.KVTxnOp{}, err
+	}
+
+	retval := consul.KVTxnOp{
+		Verb:    getSupportedVerbs()[kvOp.Verb],
+		Key:     kvOp.Key,
+		Value:   value,
+		Flags:   kvOp.Flags,
+		Session: kvOp.Session,
+	}
+	if nil != kvOp.Index {
+		retval.Index = *kvOp.Index
 	}
+	return retval, nil
 }
 
 func toKVTransactionError(txnError *consul.TxnError) KVTransactionError {
@@ -65,7 +314,7 @@ func getSupportedVerbs() map[string]consul.KVOp {
 	retval[string(consul.KVUnlock)] = consul.KVUnlock
 	retval[string(consul.KVGet)] = consul.KVGet
 	retval[string(consul.KVGetTree)] = consul.KVGetTree
-	retval[string(consul.KVCheckSession)] = consul.KVCheckIndex
+	retval[string(consul.KVCheckSession)] = consul.KVCheckSession
 	retval[string(consul.KVCheckIndex)] = consul.KVCheckIndex
 	retval[string(consul.KVCheckNotExists)] = consul.KVCheckNotExists
 	return retval
@@ -0,0 +1,216 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+//MaxTxnOperations is the maximum number of operations consul accepts in a single transaction.
+const MaxTxnOperations = 64
+
+//KVBatch represents the operations [Start, End) of a batched key-value transaction.
+type KVBatch struct {
+	Index int `json:"index"`
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+//KVPartialTransaction describes a batched key-value transaction that failed after some batches were applied.
+//Error indexes refer to the whole operation list.
+type KVPartialTransaction struct {
+	AppliedBatches     []KVBatch            `json:"appliedBatches"`
+	FailedBatch        KVBatch              `json:"failedBatch"`
+	Errors             []KVTransactionError `json:"errors"`
+	Compensated        bool                 `json:"compensated"`
+	CompensationErrors []string             `json:"compensationErrors,omitempty"`
+}
+
+//kvSnapshot keeps the values keys had before the first batch changing them was applied,
+//a nil pair meaning the key did not exist.
+type kvSnapshot struct {
+	keys  []string
+	pairs map[string]*consul.KVPair
+}
+
+//KVTransactBatches applies the operations in sequential transactions of at most MaxTxnOperations operations.
+//Each batch is atomic, the whole list is not: when a batch fails after others were applied a partial
+//transaction is returned and, when compensate is set, the keys changed by the applied batches are restored.
+//A failure of the first batch is returned as a regular rollback.
+func (c *consulClient) KVTransactBatches(operations []KVOperation, compensate bool, options WriteOptions) ([]KVTransactionResult, []KVTransactionError, *KVPartialTransaction, error) {
+	results := []KVTransactionResult{}
+	applied := []KVBatch{}
+	snapshot := &kvSnapshot{pairs: map[string]*consul.KVPair{}}
+
+	for start := 0; start < len(operations); start += MaxTxnOperations {
+		end := start + MaxTxnOperations
+		if end > len(operations) {
+			end = len(operations)
+		}
+		batch := KVBatch{Index: len(applied), Start: start, End: end}
+
+		var pending *kvSnapshot
+		var errors []KVTransactionError
+		var err error
+		if compensate {
+			pending, err = c.snapshotKV(operations[start:end], snapshot, options)
+		}
+		var batchResults []KVTransactionResult
+		if nil == err {
+			batchResults, errors, err = c.KVTransact(operations[start:end], options)
+		}
+		if nil != err && 0 == len(applied) {
+			return nil, nil, nil, err
+		}
+		if nil != err {
+			errors = []KVTransactionError{{Error: err.Error()}}
+		}
+		for index := range errors {
+			errors[index].Index += start
+		}
+
+		if 0 < len(errors) {
+			if 0 == len(applied) {
+				return nil, errors, nil, nil
+			}
+			partial := &KVPartialTransaction{
+				AppliedBatches: applied,
+				FailedBatch:    batch,
+				Errors:         errors,
+			}
+			if compensate {
+				partial.CompensationErrors = c.restoreKV(snapshot, options)
+				partial.Compensated = 0 == len(partial.CompensationErrors)
+			}
+			return results, nil, partial, nil
+		}
+
+		offset := len(results)
+		for _, result := range batchResults {
+			result.Index += offset
+			results = append(results, result)
+		}
+		if nil != pending {
+			snapshot.merge(pending)
+		}
+		applied = append(applied, batch)
+	}
+	return results, nil, nil, nil
+}
+
+//snapshotKV reads the current values of the keys the operations change that are not yet in the snapshot.
+func (c *consulClient) snapshotKV(operations []KVOperation, snapshot *kvSnapshot, options WriteOptions) (*kvSnapshot, error) {
+	q := toConsistentQueryOptions(options)
+	pending := &kvSnapshot{pairs: map[string]*consul.KVPair{}}
+	for _, operation := range operations {
+		switch getSupportedVerbs()[operation.Verb] {
+		case consul.KVSet, consul.KVCAS, consul.KVDelete, consul.KVDeleteCAS, consul.KVLock, consul.KVUnlock:
+			pair, _, err := c.kvClient.Get(operation.Key, q)
+			if nil != err {
+				return nil, newRequestError(fmt.Sprintf("failed to read prior value of %v", operation.Key), err)
+			}
+			pending.add(operation.Key, pair, snapshot)
+		case consul.KVDeleteTree:
+			pairs, _, err := c.kvClient.List(operation.Key, q)
+			if nil != err {
+				return nil, newRequestError(fmt.Sprintf("failed to read prior values of %v", operation.Key), err)
+			}
+			for _, pair := range pairs {
+				pending.add(pair.Key, pair, snapshot)
+			}
+		}
+	}
+	return pending, nil
+}
+
+//restoreKV writes the snapshot values back, deleting the keys that did not exist.
+//Locks taken or released by the applied batches are released or acquired again with the snapshot session.
+//It returns the problems encountered.
+func (c *consulClient) restoreKV(snapshot *kvSnapshot, options WriteOptions) []string {
+	ops := consul.TxnOps{}
+	errors := []string{}
+	q := toConsistentQueryOptions(options)
+	for _, key := range snapshot.keys {
+		pair := snapshot.pairs[key]
+		if nil == pair {
+			ops = append(ops, &consul.TxnOp{KV: &consul.KVTxnOp{Verb: consul.KVDelete, Key: key}})
+			continue
+		}
+		current, _, err := c.kvClient.Get(key, q)
+		if nil != err {
+			errors = append(errors, fmt.Sprintf("failed to restore %v: %v", key, err))
+			continue
+		}
+		ops = append(ops, toRestoreKVOps(pair, current)...)
+	}
+
+	for start := 0; start < len(ops); start += MaxTxnOperations {
+		end := start + MaxTxnOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+		ok, response, _, err := c.txnClient.Txn(ops[start:end], toTxnQueryOptions(options))
+		if nil != err {
+			errors = append(errors, fmt.Sprintf("failed to restore keys: %v", err))
+			continue
+		}
+		if !ok {
+			for _, txnError := range response.Errors {
+				errors = append(errors, fmt.Sprintf("failed to restore %v: %v", ops[start+txnError.OpIndex].KV.Key, txnError.What))
+			}
+		}
+	}
+	return errors
+}
+
+//toRestoreKVOps returns the operations restoring the value, flags and lock holder of the prior pair,
+//a set leaving the lock untouched.
+func toRestoreKVOps(prior, current *consul.KVPair) consul.TxnOps {
+	session := ""
+	if nil != current {
+		session = current.Session
+	}
+	if prior.Session == session {
+		return consul.TxnOps{{KV: &consul.KVTxnOp{Verb: consul.KVSet, Key: prior.Key, Value: prior.Value, Flags: prior.Flags}}}
+	}
+
+	ops := consul.TxnOps{}
+	if "" != session {
+		ops = append(ops, &consul.TxnOp{KV: &consul.KVTxnOp{Verb: consul.KVUnlock, Key: prior.Key, Value: prior.Value, Flags: prior.Flags, Session: session}})
+	}
+	if "" != prior.Session {
+		ops = append(ops, &consul.TxnOp{KV: &consul.KVTxnOp{Verb: consul.KVLock, Key: prior.Key, Value: prior.Value, Flags: prior.Flags, Session: prior.Session}})
+	}
+	return ops
+}
+
+func (s *kvSnapshot) add(key string, pair *consul.KVPair, snapshot *kvSnapshot) {
+	if _, ok := snapshot.pairs[key]; ok {
+		return
+	}
+	if _, ok := s.pairs[key]; ok {
+		return
+	}
+	s.keys = append(s.keys, key)
+	s.pairs[key] = pair
+}
+
+func (s *kvSnapshot) merge(other *kvSnapshot) {
+	for _, key := range other.keys {
+		s.keys = append(s.keys, key)
+		s.pairs[key] = other.pairs[key]
+	}
+}
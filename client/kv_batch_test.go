@@ -0,0 +1,229 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVTransactBatches(t *testing.T) {
+	Before(t)
+	defer After()
+
+	sizes := []int{}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		sizes = append(sizes, len(operations))
+		return true, getSetResponse(operations), nil, nil
+	}
+
+	results, rollback, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(130), false, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Nil(t, rollback)
+	assert.Nil(t, partial)
+	assert.Equal(t, []int{64, 64, 2}, sizes)
+	require.Equal(t, 130, len(results))
+	assert.Equal(t, 129, results[129].Index)
+	assert.Equal(t, "key/129", results[129].Key)
+}
+
+func TestKVTransactBatchesFirstBatchRolledBack(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		return false, &consul.TxnResponse{Errors: consul.TxnErrors{{OpIndex: 3, What: "nope"}}}, nil, nil
+	}
+
+	results, rollback, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(100), false, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Nil(t, results)
+	assert.Nil(t, partial)
+	assert.Equal(t, []KVTransactionError{{Index: 3, Error: "nope"}}, rollback)
+}
+
+func TestKVTransactBatchesPartiallyApplied(t *testing.T) {
+	Before(t)
+	defer After()
+
+	calls := 0
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		calls++
+		if 2 == calls {
+			return false, &consul.TxnResponse{Errors: consul.TxnErrors{{OpIndex: 1, What: "nope"}}}, nil, nil
+		}
+		return true, getSetResponse(operations), nil, nil
+	}
+
+	results, rollback, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(130), false, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Nil(t, rollback)
+	assert.Equal(t, 64, len(results))
+	require.NotNil(t, partial)
+	assert.Equal(t, []KVBatch{{Index: 0, Start: 0, End: 64}}, partial.AppliedBatches)
+	assert.Equal(t, KVBatch{Index: 1, Start: 64, End: 128}, partial.FailedBatch)
+	assert.Equal(t, []KVTransactionError{{Index: 65, Error: "nope"}}, partial.Errors)
+	assert.False(t, partial.Compensated)
+	assert.Equal(t, 2, calls)
+}
+
+func TestKVTransactBatchesCompensates(t *testing.T) {
+	Before(t)
+	defer After()
+
+	var restored consul.TxnOps
+	calls := 0
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		if "key/0" == key {
+			return &consul.KVPair{Key: key, Value: []byte("old"), Flags: 3}, nil, nil
+		}
+		return nil, nil, nil
+	}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		calls++
+		switch calls {
+		case 1:
+			return true, getSetResponse(operations), nil, nil
+		case 2:
+			return false, nil, nil, errors.New("kablammo")
+		}
+		restored = operations
+		return true, &consul.TxnResponse{}, nil, nil
+	}
+
+	_, _, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(70), true, WriteOptions{})
+
+	require.Nil(t, err)
+	require.NotNil(t, partial)
+	assert.Equal(t, []KVTransactionError{{Index: 64, Error: "failed to make transaction request: kablammo"}}, partial.Errors)
+	assert.True(t, partial.Compensated)
+	assert.Empty(t, partial.CompensationErrors)
+	require.Equal(t, 64, len(restored))
+	assert.Equal(t, consul.KVSet, restored[0].KV.Verb)
+	assert.Equal(t, []byte("old"), restored[0].KV.Value)
+	assert.Equal(t, uint64(3), restored[0].KV.Flags)
+	assert.Equal(t, consul.KVDelete, restored[1].KV.Verb)
+	assert.Equal(t, "key/1", restored[1].KV.Key)
+}
+
+func TestKVTransactBatchesCompensationReleasesLocks(t *testing.T) {
+	Before(t)
+	defer After()
+
+	var restored consul.TxnOps
+	calls := 0
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		if 1 < calls {
+			return &consul.KVPair{Key: key, Value: []byte("new"), Session: "applied"}, nil, nil
+		}
+		return &consul.KVPair{Key: key, Value: []byte("old"), Flags: 3}, nil, nil
+	}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		calls++
+		switch calls {
+		case 1:
+			return true, getSetResponse(operations), nil, nil
+		case 2:
+			return false, nil, nil, errors.New("kablammo")
+		}
+		restored = append(restored, operations...)
+		return true, &consul.TxnResponse{}, nil, nil
+	}
+
+	_, _, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(70), true, WriteOptions{})
+
+	require.Nil(t, err)
+	require.NotNil(t, partial)
+	assert.True(t, partial.Compensated)
+	require.Equal(t, 64, len(restored))
+	assert.Equal(t, consul.KVUnlock, restored[0].KV.Verb)
+	assert.Equal(t, "applied", restored[0].KV.Session)
+	assert.Equal(t, []byte("old"), restored[0].KV.Value)
+	assert.Equal(t, uint64(3), restored[0].KV.Flags)
+}
+
+func TestKVTransactBatchesCompensationReadFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	calls := 0
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		if 1 < calls && "key/0" == key {
+			return nil, nil, errors.New("kablammo")
+		}
+		return &consul.KVPair{Key: key, Value: []byte("old")}, nil, nil
+	}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		calls++
+		if 2 == calls {
+			return false, nil, nil, errors.New("kablammo")
+		}
+		return true, getSetResponse(operations), nil, nil
+	}
+
+	_, _, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(70), true, WriteOptions{})
+
+	require.Nil(t, err)
+	require.NotNil(t, partial)
+	assert.False(t, partial.Compensated)
+	assert.Equal(t, []string{"failed to restore key/0: kablammo"}, partial.CompensationErrors)
+}
+
+func TestKVTransactBatchesCompensationFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	calls := 0
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		calls++
+		if 1 == calls {
+			return true, getSetResponse(operations), nil, nil
+		}
+		return false, nil, nil, errors.New("kablammo")
+	}
+
+	_, _, partial, err := ConsulImpl.KVTransactBatches(getSetOperations(70), true, WriteOptions{})
+
+	require.Nil(t, err)
+	require.NotNil(t, partial)
+	assert.False(t, partial.Compensated)
+	assert.Equal(t, []string{"failed to restore keys: kablammo"}, partial.CompensationErrors)
+}
+
+func getSetOperations(count int) []KVOperation {
+	operations := make([]KVOperation, count)
+	for index := range operations {
+		operations[index] = KVOperation{Verb: "set", Key: fmt.Sprintf("key/%d", index)}
+	}
+	return operations
+}
+
+func getSetResponse(operations consul.TxnOps) *consul.TxnResponse {
+	response := &consul.TxnResponse{}
+	for _, operation := range operations {
+		response.Results = append(response.Results, &consul.TxnResult{KV: &consul.KVPair{Key: operation.KV.Key}})
+	}
+	return response
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+//KVTransactionPlan represents the outcome of key-value operations evaluated without writing anything.
+//Errors lists the operations whose conditions are not met and would roll the transaction back.
+type KVTransactionPlan struct {
+	Operations []KVPlannedOperation `json:"operations"`
+	Errors     []KVTransactionError `json:"errors"`
+}
+
+//KVPlannedOperation represents the change an operation would make to its key.
+//Before and After are nil when the key does not exist, After keeps the indexes of Before
+//as the new ones are only known once written. Keys lists the keys read or deleted by get-tree and delete-tree.
+type KVPlannedOperation struct {
+	Index   int      `json:"index"`
+	Verb    string   `json:"verb"`
+	Key     string   `json:"key"`
+	Before  *KVPair  `json:"before"`
+	After   *KVPair  `json:"after"`
+	Changed bool     `json:"changed"`
+	Keys    []string `json:"keys,omitempty"`
+}
+
+//kvPlanState tracks the keys read from consul and the changes of the evaluated operations.
+//A nil pair is a key known not to exist.
+type kvPlanState struct {
+	pairs map[string]*consul.KVPair
+	trees map[string]bool
+}
+
+//KVTransactPlan reads the current values of the keys the operations target and evaluates
+//the operations in order, including their cas and check conditions, without writing anything.
+func (c *consulClient) KVTransactPlan(operations []KVOperation, options WriteOptions) (*KVTransactionPlan, error) {
+	q := toConsistentQueryOptions(options)
+	state := &kvPlanState{
+		pairs: map[string]*consul.KVPair{},
+		trees: map[string]bool{},
+	}
+
+	plan := &KVTransactionPlan{
+		Operations: []KVPlannedOperation{},
+		Errors:     []KVTransactionError{},
+	}
+	for index, operation := range operations {
+		planned, problem, err := c.planKVOperation(q, state, operation)
+		if nil != err {
+			return nil, err
+		}
+		planned.Index = index
+		plan.Operations = append(plan.Operations, planned)
+		if "" != problem {
+			plan.Errors = append(plan.Errors, KVTransactionError{Index: index, Error: problem})
+		}
+	}
+	return plan, nil
+}
+
+func (c *consulClient) planKVOperation(q *consul.QueryOptions, state *kvPlanState, operation KVOperation) (KVPlannedOperation, string, error) {
+	planned := KVPlannedOperation{Verb: operation.Verb, Key: operation.Key}
+
+	verb := getSupportedVerbs()[operation.Verb]
+	if consul.KVGetTree == verb || consul.KVDeleteTree == verb {
+		pairs, err := c.listPlanned(q, state, operation.Key)
+		if nil != err {
+			return planned, "", err
+		}
+		for _, pair := range pairs {
+			planned.Keys = append(planned.Keys, pair.Key)
+			if consul.KVDeleteTree == verb {
+				state.pairs[pair.Key] = nil
+			}
+		}
+		planned.Changed = consul.KVDeleteTree == verb && 0 < len(pairs)
+		return planned, "", nil
+	}
+
+	current, err := c.getPlanned(q, state, operation.Key)
+	if nil != err {
+		return planned, "", err
+	}
+	planned.Before = toPlannedKVPair(current, operation.Encoding)
+
+	next, problem := evaluateKVOperation(current, operation)
+	if "" != problem {
+		planned.After = planned.Before
+		return planned, problem, nil
+	}
+	state.pairs[operation.Key] = next
+	planned.After = toPlannedKVPair(next, operation.Encoding)
+	planned.Changed = !sameKVPair(current, next)
+	return planned, "", nil
+}
+
+//evaluateKVOperation returns the pair the operation would leave or the reason the operation would fail.
+func evaluateKVOperation(current *consul.KVPair, operation KVOperation) (*consul.KVPair, string) {
+	session := ""
+	if nil != current {
+		session = current.Session
+	}
+
+	switch getSupportedVerbs()[operation.Verb] {
+	case consul.KVSet:
+		return writeKVPair(current, operation)
+	case consul.KVCAS:
+		if problem := checkKVIndex(current, operation.Index); "" != problem {
+			return current, problem
+		}
+		return writeKVPair(current, operation)
+	case consul.KVGet:
+		if nil == current {
+			return current, "key does not exist"
+		}
+	case consul.KVCheckIndex:
+		return current, checkKVIndex(current, operation.Index)
+	case consul.KVCheckSession:
+		if session != operation.Session {
+			return current, fmt.Sprintf("key is not locked by session %v", operation.Session)
+		}
+	case consul.KVCheckNotExists:
+		if nil != current {
+			return current, "key exists"
+		}
+	case consul.KVDelete:
+		return nil, ""
+	case consul.KVDeleteCAS:
+		if problem := checkKVIndex(current, operation.Index); "" != problem {
+			return current, problem
+		}
+		return nil, ""
+	case consul.KVLock:
+		if "" != session && session != operation.Session {
+			return current, fmt.Sprintf("key is locked by session %v", session)
+		}
+		next, problem := writeKVPair(current, operation)
+		if nil != next && session != operation.Session {
+			next.Session = operation.Session
+			next.LockIndex++
+		}
+		return next, problem
+	case consul.KVUnlock:
+		if session != operation.Session {
+			return current, fmt.Sprintf("key is not locked by session %v", operation.Session)
+		}
+		next, problem := writeKVPair(current, operation)
+		if nil != next {
+			next.Session = ""
+		}
+		return next, problem
+	}
+	return current, ""
+}
+
+func writeKVPair(current *consul.KVPair, operation KVOperation) (*consul.KVPair, string) {
+	value, err := encodeValue(operation.Value, operation.Encoding)
+	if nil != err {
+		return current, err.Error()
+	}
+
+	next := &consul.KVPair{Key: operation.Key}
+	if nil != current {
+		*next = *current
+	}
+	next.Value = value
+	next.Flags = operation.Flags
+	return next, ""
+}
+
+func checkKVIndex(current *consul.KVPair, index *uint64) string {
+	modifyIndex := uint64(0)
+	if nil != current {
+		modifyIndex = current.ModifyIndex
+	}
+	if nil == index || *index != modifyIndex {
+		return fmt.Sprintf("index does not match the current index %d", modifyIndex)
+	}
+	return ""
+}
+
+func sameKVPair(a, b *consul.KVPair) bool {
+	if nil == a || nil == b {
+		return a == b
+	}
+	return bytes.Equal(a.Value, b.Value) && a.Flags == b.Flags && a.Session == b.Session
+}
+
+func toPlannedKVPair(pair *consul.KVPair, encoding string) *KVPair {
+	if nil == pair {
+		return nil
+	}
+	retval := toKVPair(pair)
+	retval.Value = decodeValue(pair.Value, encoding)
+	return &retval
+}
+
+func (c *consulClient) getPlanned(q *consul.QueryOptions, state *kvPlanState, key string) (*consul.KVPair, error) {
+	if pair, ok := state.pairs[key]; ok {
+		return pair, nil
+	}
+	pair, _, err := c.kvClient.Get(key, q)
+	if nil != err {
+		return nil, newRequestError(fmt.Sprintf("failed to read current value of %v", key), err)
+	}
+	state.pairs[key] = pair
+	return pair, nil
+}
+
+//listPlanned returns the existing keys under the prefix, sorted by key.
+func (c *consulClient) listPlanned(q *consul.QueryOptions, state *kvPlanState, prefix string) ([]*consul.KVPair, error) {
+	if !state.trees[prefix] {
+		pairs, _, err := c.kvClient.List(prefix, q)
+		if nil != err {
+			return nil, newRequestError(fmt.Sprintf("failed to read current values of %v", prefix), err)
+		}
+		for _, pair := range pairs {
+			if _, ok := state.pairs[pair.Key]; !ok {
+				state.pairs[pair.Key] = pair
+			}
+		}
+		state.trees[prefix] = true
+	}
+
+	retval := []*consul.KVPair{}
+	for key, pair := range state.pairs {
+		if nil != pair && strings.HasPrefix(key, prefix) {
+			retval = append(retval, pair)
+		}
+	}
+	sort.Slice(retval, func(i, j int) bool {
+		return retval[i].Key < retval[j].Key
+	})
+	return retval, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVTransactPlanEvaluatesOperationsInOrder(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		assert.True(t, q.RequireConsistent)
+		assert.Equal(t, "dc2", q.Datacenter)
+		if "joe/mama" == key {
+			return &consul.KVPair{Key: key, Value: []byte(`"so fat"`), ModifyIndex: 7}, nil, nil
+		}
+		return nil, nil, nil
+	}
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		t.Fatal("nothing should be written")
+		return false, nil, nil, nil
+	}
+
+	index := uint64(7)
+	plan, err := ConsulImpl.KVTransactPlan([]KVOperation{
+		{Verb: "cas", Key: "joe/mama", Value: []byte(`"so thin"`), Index: &index},
+		{Verb: "set", Key: "joe/papa", Value: []byte(`"so tall"`)},
+		{Verb: "check-not-exists", Key: "joe/papa"},
+		{Verb: "get", Key: "joe/mama"},
+	}, WriteOptions{Datacenter: "dc2"})
+
+	require.Nil(t, err)
+	require.Equal(t, 4, len(plan.Operations))
+	assert.Equal(t, `"so fat"`, string(plan.Operations[0].Before.Value))
+	assert.Equal(t, `"so thin"`, string(plan.Operations[0].After.Value))
+	assert.True(t, plan.Operations[0].Changed)
+	assert.Nil(t, plan.Operations[1].Before)
+	assert.Equal(t, `"so tall"`, string(plan.Operations[1].After.Value))
+	assert.True(t, plan.Operations[1].Changed)
+	assert.False(t, plan.Operations[3].Changed)
+	assert.Equal(t, `"so thin"`, string(plan.Operations[3].After.Value))
+	assert.Equal(t, []KVTransactionError{{Index: 2, Error: "key exists"}}, plan.Errors)
+}
+
+func TestKVTransactPlanTrees(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return consul.KVPairs{{Key: "joe/b"}, {Key: "joe/a"}}, nil, nil
+	}
+
+	plan, err := ConsulImpl.KVTransactPlan([]KVOperation{
+		{Verb: "set", Key: "joe/c", Value: []byte(`1`)},
+		{Verb: "delete-tree", Key: "joe/"},
+		{Verb: "get-tree", Key: "joe/"},
+	}, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"joe/a", "joe/b", "joe/c"}, plan.Operations[1].Keys)
+	assert.True(t, plan.Operations[1].Changed)
+	assert.Empty(t, plan.Operations[2].Keys)
+	assert.Empty(t, plan.Errors)
+}
+
+func TestKVTransactPlanReadFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, fmt.Errorf("Unexpected response code: 403 (Permission denied)")
+	}
+
+	_, err := ConsulImpl.KVTransactPlan([]KVOperation{{Verb: "get", Key: "joe/mama"}}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, ErrorForbidden, err.(*RequestError).Class)
+}
+
+func TestEvaluateKVOperation(t *testing.T) {
+	index := uint64(3)
+	current := &consul.KVPair{Key: "joe/mama", Value: []byte(`1`), ModifyIndex: 5, Session: "a", LockIndex: 1}
+
+	_, problem := evaluateKVOperation(current, KVOperation{Verb: "cas", Key: "joe/mama", Index: &index})
+	assert.Equal(t, "index does not match the current index 5", problem)
+
+	_, problem = evaluateKVOperation(current, KVOperation{Verb: "check-index", Key: "joe/mama", Index: &index})
+	assert.Equal(t, "index does not match the current index 5", problem)
+
+	next, problem := evaluateKVOperation(current, KVOperation{Verb: "delete-cas", Key: "joe/mama", Index: &current.ModifyIndex})
+	assert.Empty(t, problem)
+	assert.Nil(t, next)
+
+	_, problem = evaluateKVOperation(nil, KVOperation{Verb: "get", Key: "joe/mama"})
+	assert.Equal(t, "key does not exist", problem)
+
+	_, problem = evaluateKVOperation(current, KVOperation{Verb: "check-session", Key: "joe/mama", Session: "b"})
+	assert.Equal(t, "key is not locked by session b", problem)
+
+	_, problem = evaluateKVOperation(current, KVOperation{Verb: "lock", Key: "joe/mama", Session: "b"})
+	assert.Equal(t, "key is locked by session a", problem)
+
+	next, problem = evaluateKVOperation(&consul.KVPair{Key: "joe/mama"}, KVOperation{Verb: "lock", Key: "joe/mama", Session: "b"})
+	assert.Empty(t, problem)
+	assert.Equal(t, "b", next.Session)
+	assert.Equal(t, uint64(1), next.LockIndex)
+
+	next, problem = evaluateKVOperation(current, KVOperation{Verb: "unlock", Key: "joe/mama", Session: "a"})
+	assert.Empty(t, problem)
+	assert.Equal(t, "", next.Session)
+
+	next, problem = evaluateKVOperation(nil, KVOperation{Verb: "set", Key: "joe/mama", Value: []byte(`"hi"`), Encoding: "string"})
+	assert.Empty(t, problem)
+	assert.Equal(t, "hi", string(next.Value))
+}
@@ -0,0 +1,612 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetKV(t *testing.T) {
+	Before(t)
+	defer After()
+
+	const key = "joe/mama"
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		assert.Equal(t, key, k)
+		require.NotNil(t, q)
+		assert.Equal(t, "dc", q.Datacenter)
+		assert.True(t, q.RequireConsistent)
+		assert.False(t, q.AllowStale)
+		return getKVPair(key), nil, nil
+	}
+
+	pair, err := ConsulImpl.GetKV(key, "", QueryOptions{Datacenter: "dc", Consistency: ConsistencyConsistent})
+	require.Nil(t, err)
+	require.NotNil(t, pair)
+	assert.Equal(t, key, pair.Key)
+	assert.Equal(t, `"value"`, string(pair.Value))
+	assert.Equal(t, uint64(42), pair.Flags)
+	assert.Equal(t, uint64(1), pair.CreateIndex)
+	assert.Equal(t, uint64(2), pair.ModifyIndex)
+	assert.Equal(t, uint64(3), pair.LockIndex)
+	assert.Equal(t, "session", pair.Session)
+}
+
+func TestGetKVNotJSONValue(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: k, Value: []byte("bar")}, nil, nil
+	}
+
+	pair, err := ConsulImpl.GetKV("foo", "", QueryOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, pair)
+	assert.Equal(t, `"bar"`, string(pair.Value))
+	_, err = json.Marshal(pair)
+	assert.Nil(t, err)
+}
+
+func TestGetKVDecodesValue(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Value: []byte("one: 1")}, nil, nil
+	}
+
+	pair, err := ConsulImpl.GetKV("joe/mama", EncodingYAML, QueryOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, pair)
+	assert.Equal(t, `{"one":1}`, string(pair.Value))
+}
+
+func TestGetKVNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+
+	pair, err := ConsulImpl.GetKV("joe/mama", "", QueryOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, pair)
+}
+
+func TestGetKVFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	pair, err := ConsulImpl.GetKV("joe/mama", "", QueryOptions{})
+	assert.Nil(t, pair)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to get key: kablammo", err.Error())
+}
+
+func TestGetKVInvalidConsistency(t *testing.T) {
+	Before(t)
+	defer After()
+
+	pair, err := ConsulImpl.GetKV("joe/mama", "", QueryOptions{Consistency: "eventual"})
+	assert.Nil(t, pair)
+	require.NotNil(t, err)
+	assert.Equal(t, "eventual consistency mode is not valid", err.Error())
+}
+
+func TestListKV(t *testing.T) {
+	Before(t)
+	defer After()
+
+	const prefix = "joe/"
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		assert.Equal(t, prefix, p)
+		require.NotNil(t, q)
+		assert.True(t, q.AllowStale)
+		return consul.KVPairs{getKVPair("joe/mama"), getKVPair("joe/papa")}, nil, nil
+	}
+
+	pairs, err := ConsulImpl.ListKV(prefix, "", QueryOptions{Consistency: ConsistencyStale})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(pairs))
+	assert.Equal(t, "joe/mama", pairs[0].Key)
+	assert.Equal(t, "joe/papa", pairs[1].Key)
+}
+
+func TestListKVNotJSONValues(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return consul.KVPairs{{Key: "joe/mama", Value: []byte("bar")}, {Key: "joe/papa"}}, nil, nil
+	}
+
+	pairs, err := ConsulImpl.ListKV("joe/", "", QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(pairs))
+	assert.Equal(t, `"bar"`, string(pairs[0].Value))
+	assert.Nil(t, pairs[1].Value)
+}
+
+func TestListKVDecodesValues(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return consul.KVPairs{{Key: "joe/a", Value: []byte{0x01, 0x02}}}, nil, nil
+	}
+
+	pairs, err := ConsulImpl.ListKV("joe/", EncodingBase64, QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(pairs))
+	assert.Equal(t, `"AQI="`, string(pairs[0].Value))
+}
+
+func TestListKVFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	pairs, err := ConsulImpl.ListKV("joe/", "", QueryOptions{})
+	assert.Nil(t, pairs)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to list keys: kablammo", err.Error())
+}
+
+func TestListKVKeys(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.KeysFunc = func(p, separator string, q *consul.QueryOptions) ([]string, *consul.QueryMeta, error) {
+		assert.Equal(t, "joe/", p)
+		assert.Equal(t, "", separator)
+		return []string{"joe/mama", "joe/papa"}, nil, nil
+	}
+
+	keys, err := ConsulImpl.ListKVKeys("joe/", QueryOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"joe/mama", "joe/papa"}, keys)
+}
+
+func TestWatchKV(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		assert.Equal(t, "joe/mama", k)
+		require.NotNil(t, q)
+		assert.Equal(t, uint64(5), q.WaitIndex)
+		assert.Equal(t, time.Minute, q.WaitTime)
+		return getKVPair(k), &consul.QueryMeta{LastIndex: 6}, nil
+	}
+
+	pairs, index, err := ConsulImpl.WatchKV("joe/mama", false, QueryOptions{WaitIndex: 5, WaitTime: time.Minute})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(6), index)
+	require.Equal(t, 1, len(pairs))
+	assert.Equal(t, "joe/mama", pairs[0].Key)
+}
+
+func TestWatchKVNotJSONValue(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: k, Value: []byte("web-1.example.com"), Session: "a"}, &consul.QueryMeta{LastIndex: 6}, nil
+	}
+
+	pairs, _, err := ConsulImpl.WatchKV("service/web/leader", false, QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(pairs))
+	assert.Equal(t, `"web-1.example.com"`, string(pairs[0].Value))
+}
+
+func TestWatchKVMissingKey(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(k string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, &consul.QueryMeta{LastIndex: 6}, nil
+	}
+
+	pairs, index, err := ConsulImpl.WatchKV("joe/mama", false, QueryOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(6), index)
+	assert.Equal(t, 0, len(pairs))
+}
+
+func TestWatchKVRecurse(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		assert.Equal(t, "joe/", p)
+		return consul.KVPairs{getKVPair("joe/mama"), getKVPair("joe/papa")}, &consul.QueryMeta{LastIndex: 9}, nil
+	}
+
+	pairs, index, err := ConsulImpl.WatchKV("joe/", true, QueryOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(9), index)
+	assert.Equal(t, 2, len(pairs))
+}
+
+func TestWatchKVNotJSONValues(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return consul.KVPairs{{Key: "joe/mama", Value: []byte("bar")}}, &consul.QueryMeta{LastIndex: 9}, nil
+	}
+
+	pairs, _, err := ConsulImpl.WatchKV("joe/", true, QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(pairs))
+	assert.Equal(t, `"bar"`, string(pairs[0].Value))
+}
+
+func TestWatchKVFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ListFunc = func(p string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	pairs, _, err := ConsulImpl.WatchKV("joe/", true, QueryOptions{})
+	assert.Nil(t, pairs)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to watch key: kablammo", err.Error())
+}
+
+func TestPutKV(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.PutFunc = func(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "joe/mama", p.Key)
+		assert.Equal(t, `"value"`, string(p.Value))
+		assert.Equal(t, uint64(42), p.Flags)
+		require.NotNil(t, q)
+		assert.Equal(t, "dc", q.Datacenter)
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", Value: []byte(`"value"`), Flags: 42}, WriteOptions{Datacenter: "dc"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestPutKVEncodesValue(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.PutFunc = func(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "hello", string(p.Value))
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", Value: []byte(`"hello"`), Encoding: EncodingString}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestPutKVValueCannotBeEncoded(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", Value: []byte(`{"one":1}`), Encoding: EncodingString}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "value must be a string with string encoding", err.Error())
+	assert.False(t, ok)
+}
+
+func TestPutKVCAS(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.CASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, uint64(7), p.ModifyIndex)
+		return false, nil, nil
+	}
+
+	index := uint64(7)
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", CAS: &index}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestPutKVAcquire(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "session", p.Session)
+		return true, nil, nil
+	}
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", Acquire: "session"}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestPutKVRelease(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ReleaseFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "session", p.Session)
+		return true, nil, nil
+	}
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama", Release: "session"}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestPutKVFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.PutFunc = func(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		return nil, errors.New("kablammo")
+	}
+
+	ok, err := ConsulImpl.PutKV(KVPut{Key: "joe/mama"}, WriteOptions{})
+	assert.False(t, ok)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to put key: kablammo", err.Error())
+}
+
+func TestDeleteKV(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.DeleteFunc = func(key string, w *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "joe/mama", key)
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteKV(KVDelete{Key: "joe/mama"}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDeleteKVRecurse(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.DeleteTreeFunc = func(prefix string, w *consul.WriteOptions) (*consul.WriteMeta, error) {
+		assert.Equal(t, "joe/", prefix)
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DeleteKV(KVDelete{Key: "joe/", Recurse: true}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestDeleteKVCAS(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.DeleteCASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "joe/mama", p.Key)
+		assert.Equal(t, uint64(7), p.ModifyIndex)
+		return false, nil, nil
+	}
+
+	index := uint64(7)
+	ok, err := ConsulImpl.DeleteKV(KVDelete{Key: "joe/mama", CAS: &index}, WriteOptions{})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestDeleteKVFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.DeleteFunc = func(key string, w *consul.WriteOptions) (*consul.WriteMeta, error) {
+		return nil, errors.New("kablammo")
+	}
+
+	ok, err := ConsulImpl.DeleteKV(KVDelete{Key: "joe/mama"}, WriteOptions{})
+	assert.False(t, ok)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to delete key: kablammo", err.Error())
+}
+
+func TestIsConsistencySupported(t *testing.T) {
+	assert.True(t, IsConsistencySupported(""))
+	assert.True(t, IsConsistencySupported(ConsistencyDefault))
+	assert.True(t, IsConsistencySupported(ConsistencyConsistent))
+	assert.True(t, IsConsistencySupported(ConsistencyStale))
+	assert.False(t, IsConsistencySupported("eventual"))
+}
+
+func getKVPair(key string) *consul.KVPair {
+	return &consul.KVPair{
+		Key:         key,
+		Value:       []byte(`"value"`),
+		Flags:       42,
+		CreateIndex: 1,
+		ModifyIndex: 2,
+		LockIndex:   3,
+		Session:     "session",
+	}
+}
+
+func TestKVOperationValidate(t *testing.T) {
+	index := uint64(0)
+	assert.Empty(t, KVOperation{Verb: "set", Key: "joe/mama"}.Validate())
+	assert.Empty(t, KVOperation{Verb: "cas", Key: "joe/mama", Index: &index}.Validate())
+	assert.Empty(t, KVOperation{Verb: "check-session", Key: "joe/mama", Session: "a"}.Validate())
+	assert.Equal(t, []string{"cas verb requires an index"}, KVOperation{Verb: "cas", Key: "joe/mama"}.Validate())
+	assert.Equal(t, []string{"delete-cas verb requires an index"}, KVOperation{Verb: "delete-cas", Key: "joe/mama"}.Validate())
+	assert.Equal(t, []string{"check-index verb requires an index"}, KVOperation{Verb: "check-index", Key: "joe/mama"}.Validate())
+	assert.Equal(t, []string{"lock verb requires a session"}, KVOperation{Verb: "lock", Key: "joe/mama"}.Validate())
+	assert.Equal(t, []string{"unlock verb requires a session"}, KVOperation{Verb: "unlock", Key: "joe/mama"}.Validate())
+	assert.Equal(t, []string{"xml encoding is not valid"}, KVOperation{Verb: "set", Key: "joe/mama", Encoding: "xml"}.Validate())
+	assert.Equal(t, []string{"value must be a string with base64 encoding"}, KVOperation{Verb: "set", Key: "joe/mama", Value: []byte(`{}`), Encoding: "base64"}.Validate())
+}
+
+func TestToTxnKVOp(t *testing.T) {
+	index := uint64(12)
+	op, err := toTxnKVOp(KVOperation{Verb: "check-session", Key: "joe/mama", Flags: 3, Index: &index, Session: "a"})
+	require.Nil(t, err)
+
+	assert.Equal(t, consul.KVCheckSession, op.Verb)
+	assert.Equal(t, "joe/mama", op.Key)
+	assert.Equal(t, uint64(3), op.Flags)
+	assert.Equal(t, uint64(12), op.Index)
+	assert.Equal(t, "a", op.Session)
+}
+
+func TestKVTransactReturnsIndexes(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		return true, &consul.TxnResponse{Results: consul.TxnResults{
+			{KV: &consul.KVPair{Key: "joe/mama", Flags: 3, CreateIndex: 4, ModifyIndex: 5, LockIndex: 1, Session: "a"}},
+		}}, nil, nil
+	}
+
+	results, _, err := ConsulImpl.KVTransact([]KVOperation{{Verb: "lock", Key: "joe/mama", Session: "a"}}, WriteOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(results))
+	assert.Equal(t, KVTransactionResult{Key: "joe/mama", Flags: 3, CreateIndex: 4, ModifyIndex: 5, LockIndex: 1, Session: "a"}, results[0])
+}
+
+func TestKVTransactEncodesAndDecodesValues(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		require.Equal(t, 3, len(operations))
+		assert.Equal(t, "plain text", string(operations[0].KV.Value))
+		assert.Equal(t, "hi", string(operations[1].KV.Value))
+		assert.Equal(t, "a: 1\n", string(operations[2].KV.Value))
+		return true, &consul.TxnResponse{Results: consul.TxnResults{
+			{KV: &consul.KVPair{Key: "joe/text", Value: []byte("plain text")}},
+			{KV: &consul.KVPair{Key: "joe/bytes", Value: []byte("hi")}},
+			{KV: &consul.KVPair{Key: "joe/yaml", Value: []byte("a: 1\n")}},
+			{KV: &consul.KVPair{Key: "joe/tree/a", Value: []byte("b: [1, 2]\n")}},
+		}}, nil, nil
+	}
+
+	results, errors, err := ConsulImpl.KVTransact([]KVOperation{
+		{Verb: "set", Key: "joe/text", Value: []byte(`"plain text"`), Encoding: "string"},
+		{Verb: "set", Key: "joe/bytes", Value: []byte(`"aGk="`), Encoding: "base64"},
+		{Verb: "set", Key: "joe/yaml", Value: []byte(`{"a":1}`), Encoding: "yaml"},
+	}, WriteOptions{})
+	require.Nil(t, err)
+	require.Nil(t, errors)
+	require.Equal(t, 4, len(results))
+	assert.Equal(t, `"plain text"`, string(results[0].Value))
+	assert.Equal(t, `"aGk="`, string(results[1].Value))
+	assert.Equal(t, `{"a":1}`, string(results[2].Value))
+	assert.Equal(t, `"b: [1, 2]\n"`, string(results[3].Value))
+}
+
+func TestKVTransactRejectsValuesThatCannotBeEncoded(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		t.Fatal("transaction should not be sent")
+		return false, nil, nil, nil
+	}
+
+	results, errors, err := ConsulImpl.KVTransact([]KVOperation{
+		{Verb: "set", Key: "joe/mama", Value: []byte(`"!!"`), Encoding: "base64"},
+	}, WriteOptions{})
+	require.Nil(t, err)
+	assert.Nil(t, results)
+	require.Equal(t, 1, len(errors))
+	assert.Equal(t, 0, errors[0].Index)
+	assert.Contains(t, errors[0].Error, "value is not valid base64")
+}
+
+type MockKVClient struct {
+	t              *testing.T
+	GetFunc        func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error)
+	ListFunc       func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error)
+	KeysFunc       func(prefix, separator string, q *consul.QueryOptions) ([]string, *consul.QueryMeta, error)
+	PutFunc        func(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	CASFunc        func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	AcquireFunc    func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	ReleaseFunc    func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	DeleteFunc     func(key string, w *consul.WriteOptions) (*consul.WriteMeta, error)
+	DeleteCASFunc  func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error)
+	DeleteTreeFunc func(prefix string, w *consul.WriteOptions) (*consul.WriteMeta, error)
+}
+
+func NewMockKVClient(t *testing.T) *MockKVClient {
+	return &MockKVClient{t: t}
+}
+
+func (m *MockKVClient) Get(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+	return m.GetFunc(key, q)
+}
+
+func (m *MockKVClient) List(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+	return m.ListFunc(prefix, q)
+}
+
+func (m *MockKVClient) Keys(prefix, separator string, q *consul.QueryOptions) ([]string, *consul.QueryMeta, error) {
+	return m.KeysFunc(prefix, separator, q)
+}
+
+func (m *MockKVClient) Put(p *consul.KVPair, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.PutFunc(p, q)
+}
+
+func (m *MockKVClient) CAS(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+	return m.CASFunc(p, q)
+}
+
+func (m *MockKVClient) Acquire(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+	return m.AcquireFunc(p, q)
+}
+
+func (m *MockKVClient) Release(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+	return m.ReleaseFunc(p, q)
+}
+
+func (m *MockKVClient) Delete(key string, w *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.DeleteFunc(key, w)
+}
+
+func (m *MockKVClient) DeleteCAS(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+	return m.DeleteCASFunc(p, q)
+}
+
+func (m *MockKVClient) DeleteTree(prefix string, w *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.DeleteTreeFunc(prefix, w)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/HotelsDotCom/go-logger"
+	consul "github.com/hashicorp/consul/api"
+)
+
+//LockRequest represents a request to lock a key with a new session.
+type LockRequest struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Session SessionRequest  `json:"session"`
+}
+
+//Lock represents a locked key and the session holding it.
+//Session is empty when the key is not locked.
+type Lock struct {
+	Key     string `json:"key"`
+	Session string `json:"session,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Node    string `json:"node,omitempty"`
+}
+
+//AcquireLock creates a session and uses it to lock the key.
+//When the key is locked by another session the session is destroyed and the current holder is returned.
+func (c *consulClient) AcquireLock(request LockRequest, options WriteOptions) (bool, Lock, error) {
+	session, err := c.CreateSession(request.Session, options)
+	if nil != err {
+		return false, Lock{}, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+
+	pair := &consul.KVPair{
+		Key:     request.Key,
+		Value:   request.Value,
+		Flags:   consul.LockFlagValue,
+		Session: session,
+	}
+	ok, _, err := c.kvClient.Acquire(pair, toWriteOptions(options))
+	if nil != err || !ok {
+		if err := c.destroySession(session, options); nil != err {
+			logger.Errorf("failed to clean up lock session %v: %v", session, err)
+		}
+	}
+	if nil != err {
+		return false, Lock{}, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	if !ok {
+		holder, err := c.getLock(request.Key, options)
+		return false, holder, err
+	}
+
+	return true, Lock{
+		Key:     request.Key,
+		Session: session,
+		Name:    request.Session.Name,
+		Node:    request.Session.Node,
+	}, nil
+}
+
+//ReleaseLock unlocks the key and destroys the session holding it.
+//When the key is not locked by the session the current holder is returned.
+func (c *consulClient) ReleaseLock(key, session string, options WriteOptions) (bool, Lock, error) {
+	pair := &consul.KVPair{
+		Key:     key,
+		Flags:   consul.LockFlagValue,
+		Session: session,
+	}
+	ok, _, err := c.kvClient.Release(pair, toWriteOptions(options))
+	if nil != err {
+		return false, Lock{}, fmt.Errorf("failed to release lock: %v", err)
+	}
+	if !ok {
+		holder, err := c.getLock(key, options)
+		return false, holder, err
+	}
+
+	if err := c.destroySession(session, options); nil != err {
+		return false, Lock{}, fmt.Errorf("failed to release lock: %v", err)
+	}
+	return true, Lock{Key: key}, nil
+}
+
+//RenewLock renews the session holding the key.
+//When the session has expired or no longer holds the key the current holder is returned,
+//a renewed session that lost the key being destroyed.
+func (c *consulClient) RenewLock(key, session string, options WriteOptions) (bool, Lock, error) {
+	entry, _, err := c.sessionClient.Renew(session, toWriteOptions(options))
+	if nil != err {
+		return false, Lock{}, fmt.Errorf("failed to renew lock: %v", err)
+	}
+
+	holder, err := c.getLock(key, options)
+	if nil != err {
+		return false, Lock{}, err
+	}
+	if nil != entry && session != holder.Session {
+		if err := c.destroySession(session, options); nil != err {
+			logger.Errorf("failed to clean up lock session %v: %v", session, err)
+		}
+	}
+	return nil != entry && session == holder.Session, holder, nil
+}
+
+func (c *consulClient) getLock(key string, options WriteOptions) (Lock, error) {
+	q := &consul.QueryOptions{Datacenter: options.Datacenter}
+	pair, _, err := c.kvClient.Get(key, q)
+	if nil != err {
+		return Lock{}, fmt.Errorf("failed to get lock holder: %v", err)
+	}
+	if nil == pair || "" == pair.Session {
+		return Lock{Key: key}, nil
+	}
+
+	retval := Lock{
+		Key:     key,
+		Session: pair.Session,
+	}
+	entry, _, err := c.sessionClient.Info(pair.Session, q)
+	if nil != err {
+		return Lock{}, fmt.Errorf("failed to get lock holder: %v", err)
+	}
+	if nil != entry {
+		retval.Name = entry.Name
+		retval.Node = entry.Node
+	}
+	return retval, nil
+}
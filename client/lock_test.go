@@ -0,0 +1,186 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLock(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "session-id", nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "locks/deploy", p.Key)
+		assert.Equal(t, "session-id", p.Session)
+		assert.Equal(t, uint64(consul.LockFlagValue), p.Flags)
+		return true, nil, nil
+	}
+
+	ok, lock, err := ConsulImpl.AcquireLock(LockRequest{Key: "locks/deploy", Session: SessionRequest{Name: "deploy"}}, WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Lock{Key: "locks/deploy", Session: "session-id", Name: "deploy"}, lock)
+}
+
+func TestAcquireLockHeld(t *testing.T) {
+	Before(t)
+	defer After()
+
+	destroyed := ""
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "session-id", nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		assert.Equal(t, "holder-id", id)
+		return &consul.SessionEntry{ID: id, Name: "other", Node: "node-2"}, nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return false, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Session: "holder-id"}, nil, nil
+	}
+
+	ok, holder, err := ConsulImpl.AcquireLock(LockRequest{Key: "locks/deploy"}, WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "session-id", destroyed)
+	assert.Equal(t, Lock{Key: "locks/deploy", Session: "holder-id", Name: "other", Node: "node-2"}, holder)
+}
+
+func TestAcquireLockFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "", nil, errors.New("kablammo")
+	}
+
+	_, _, err := ConsulImpl.AcquireLock(LockRequest{Key: "locks/deploy"}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to acquire lock: failed to create session: kablammo", err.Error())
+}
+
+func TestReleaseLock(t *testing.T) {
+	Before(t)
+	defer After()
+
+	destroyed := ""
+	ConsulMockKVClient.ReleaseFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "session-id", p.Session)
+		return true, nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+
+	ok, _, err := ConsulImpl.ReleaseLock("locks/deploy", "session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "session-id", destroyed)
+}
+
+func TestReleaseLockNotHeld(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.ReleaseFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return false, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+
+	ok, holder, err := ConsulImpl.ReleaseLock("locks/deploy", "session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, Lock{Key: "locks/deploy"}, holder)
+}
+
+func TestRenewLock(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.RenewFunc = func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+		return &consul.SessionEntry{ID: id}, nil, nil
+	}
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		return &consul.SessionEntry{ID: id, Node: "node-1"}, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Session: "session-id"}, nil, nil
+	}
+
+	ok, lock, err := ConsulImpl.RenewLock("locks/deploy", "session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "node-1", lock.Node)
+}
+
+func TestRenewLockLostDestroysSession(t *testing.T) {
+	Before(t)
+	defer After()
+
+	destroyed := ""
+	ConsulMockSessionClient.RenewFunc = func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+		return &consul.SessionEntry{ID: id}, nil, nil
+	}
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		return &consul.SessionEntry{ID: id, Node: "node-2"}, nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Session: "other-id"}, nil, nil
+	}
+
+	ok, holder, err := ConsulImpl.RenewLock("locks/deploy", "session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "other-id", holder.Session)
+	assert.Equal(t, "session-id", destroyed)
+}
+
+func TestRenewLockSessionExpired(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.RenewFunc = func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+		return nil, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key}, nil, nil
+	}
+
+	ok, holder, err := ConsulImpl.RenewLock("locks/deploy", "session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "", holder.Session)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+type maintenanceClient interface {
+	EnableNodeMaintenance(reason string) error
+	DisableNodeMaintenance() error
+	EnableServiceMaintenance(serviceID, reason string) error
+	DisableServiceMaintenance(serviceID string) error
+	Checks() (map[string]*consul.AgentCheck, error)
+}
+
+//EnableMaintenance puts the local node, or the given service when serviceID is set, into maintenance mode
+//and returns the resulting maintenance check.
+func (c *consulClient) EnableMaintenance(serviceID, reason string) (*HealthCheck, error) {
+	var err error
+	if "" == serviceID {
+		err = c.maintenanceClient.EnableNodeMaintenance(reason)
+	} else {
+		err = c.maintenanceClient.EnableServiceMaintenance(serviceID, reason)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("failed to enable maintenance: %v", err)
+	}
+	return c.GetMaintenance(serviceID)
+}
+
+//DisableMaintenance takes the local node, or the given service when serviceID is set, out of maintenance mode.
+func (c *consulClient) DisableMaintenance(serviceID string) error {
+	var err error
+	if "" == serviceID {
+		err = c.maintenanceClient.DisableNodeMaintenance()
+	} else {
+		err = c.maintenanceClient.DisableServiceMaintenance(serviceID)
+	}
+	if nil != err {
+		return fmt.Errorf("failed to disable maintenance: %v", err)
+	}
+	return nil
+}
+
+//GetMaintenance returns the maintenance check of the local node, or of the given service when serviceID is set.
+//It returns nil when maintenance mode is not enabled.
+func (c *consulClient) GetMaintenance(serviceID string) (*HealthCheck, error) {
+	checks, err := c.maintenanceClient.Checks()
+	if nil != err {
+		return nil, fmt.Errorf("failed to get maintenance status: %v", err)
+	}
+
+	checkID := consul.NodeMaint
+	if "" != serviceID {
+		checkID = consul.ServiceMaintPrefix + serviceID
+	}
+	check, ok := checks[checkID]
+	if !ok {
+		return nil, nil
+	}
+
+	retval := HealthCheck{
+		Node:        check.Node,
+		CheckID:     check.CheckID,
+		Name:        check.Name,
+		Status:      check.Status,
+		Notes:       check.Notes,
+		Output:      check.Output,
+		ServiceID:   check.ServiceID,
+		ServiceName: check.ServiceName,
+	}
+	return &retval, nil
+}
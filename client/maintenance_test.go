@@ -0,0 +1,179 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableNodeMaintenance(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockMaintenanceClient.EnableNodeMaintenanceFunc = func(reason string) error {
+		assert.Equal(t, "incident", reason)
+		return nil
+	}
+	ConsulMockMaintenanceClient.ChecksFunc = func() (map[string]*consul.AgentCheck, error) {
+		return getMaintenanceChecks(), nil
+	}
+
+	check, err := ConsulImpl.EnableMaintenance("", "incident")
+	require.Nil(t, err)
+	require.NotNil(t, check)
+	assert.Equal(t, "_node_maintenance", check.CheckID)
+	assert.Equal(t, consul.HealthCritical, check.Status)
+	assert.Equal(t, "incident", check.Notes)
+}
+
+func TestEnableServiceMaintenance(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockMaintenanceClient.EnableServiceMaintenanceFunc = func(serviceID, reason string) error {
+		assert.Equal(t, "web-1", serviceID)
+		assert.Equal(t, "drain", reason)
+		return nil
+	}
+	ConsulMockMaintenanceClient.ChecksFunc = func() (map[string]*consul.AgentCheck, error) {
+		return getMaintenanceChecks(), nil
+	}
+
+	check, err := ConsulImpl.EnableMaintenance("web-1", "drain")
+	require.Nil(t, err)
+	require.NotNil(t, check)
+	assert.Equal(t, "_service_maintenance:web-1", check.CheckID)
+	assert.Equal(t, "web-1", check.ServiceID)
+}
+
+func TestEnableMaintenanceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockMaintenanceClient.EnableNodeMaintenanceFunc = func(reason string) error {
+		return errors.New("kablammo")
+	}
+
+	check, err := ConsulImpl.EnableMaintenance("", "incident")
+	assert.Nil(t, check)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to enable maintenance: kablammo", err.Error())
+}
+
+func TestDisableMaintenance(t *testing.T) {
+	Before(t)
+	defer After()
+
+	nodeDisabled := false
+	ConsulMockMaintenanceClient.DisableNodeMaintenanceFunc = func() error {
+		nodeDisabled = true
+		return nil
+	}
+	ConsulMockMaintenanceClient.DisableServiceMaintenanceFunc = func(serviceID string) error {
+		assert.Equal(t, "web-1", serviceID)
+		return errors.New("kablammo")
+	}
+
+	assert.Nil(t, ConsulImpl.DisableMaintenance(""))
+	assert.True(t, nodeDisabled)
+
+	err := ConsulImpl.DisableMaintenance("web-1")
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to disable maintenance: kablammo", err.Error())
+}
+
+func TestGetMaintenanceNotEnabled(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockMaintenanceClient.ChecksFunc = func() (map[string]*consul.AgentCheck, error) {
+		return map[string]*consul.AgentCheck{}, nil
+	}
+
+	check, err := ConsulImpl.GetMaintenance("web-1")
+	assert.Nil(t, err)
+	assert.Nil(t, check)
+}
+
+func TestGetMaintenanceFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockMaintenanceClient.ChecksFunc = func() (map[string]*consul.AgentCheck, error) {
+		return nil, errors.New("kablammo")
+	}
+
+	check, err := ConsulImpl.GetMaintenance("")
+	assert.Nil(t, check)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to get maintenance status: kablammo", err.Error())
+}
+
+func getMaintenanceChecks() map[string]*consul.AgentCheck {
+	return map[string]*consul.AgentCheck{
+		"_node_maintenance": {
+			Node:    "node-1",
+			CheckID: "_node_maintenance",
+			Name:    "Node Maintenance Mode",
+			Status:  consul.HealthCritical,
+			Notes:   "incident",
+		},
+		"_service_maintenance:web-1": {
+			Node:      "node-1",
+			CheckID:   "_service_maintenance:web-1",
+			Name:      "Service Maintenance Mode",
+			Status:    consul.HealthCritical,
+			Notes:     "drain",
+			ServiceID: "web-1",
+		},
+	}
+}
+
+type MockMaintenanceClient struct {
+	t                             *testing.T
+	EnableNodeMaintenanceFunc     func(reason string) error
+	DisableNodeMaintenanceFunc    func() error
+	EnableServiceMaintenanceFunc  func(serviceID, reason string) error
+	DisableServiceMaintenanceFunc func(serviceID string) error
+	ChecksFunc                    func() (map[string]*consul.AgentCheck, error)
+}
+
+func NewMockMaintenanceClient(t *testing.T) *MockMaintenanceClient {
+	return &MockMaintenanceClient{t: t}
+}
+
+func (m *MockMaintenanceClient) EnableNodeMaintenance(reason string) error {
+	return m.EnableNodeMaintenanceFunc(reason)
+}
+
+func (m *MockMaintenanceClient) DisableNodeMaintenance() error {
+	return m.DisableNodeMaintenanceFunc()
+}
+
+func (m *MockMaintenanceClient) EnableServiceMaintenance(serviceID, reason string) error {
+	return m.EnableServiceMaintenanceFunc(serviceID, reason)
+}
+
+func (m *MockMaintenanceClient) DisableServiceMaintenance(serviceID string) error {
+	return m.DisableServiceMaintenanceFunc(serviceID)
+}
+
+func (m *MockMaintenanceClient) Checks() (map[string]*consul.AgentCheck, error) {
+	return m.ChecksFunc()
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+const (
+	//ConsistencyDefault lets consul choose the read consistency mode.
+	ConsistencyDefault = "default"
+	//ConsistencyConsistent forces reads to be served by the leader after verifying leadership.
+	ConsistencyConsistent = "consistent"
+	//ConsistencyStale allows reads to be served by any server.
+	ConsistencyStale = "stale"
+)
+
+type partitionContextKey struct{}
+
+//QueryOptions represents the options of a consul read request.
+//Near sorts results by round trip time from the given node and Filter is a
+//consul filter expression. WaitIndex and WaitTime turn the request into a blocking query.
+//Token replaces the ACL token of the client, Namespace and Partition are Consul Enterprise features.
+type QueryOptions struct {
+	Datacenter  string
+	Consistency string
+	Near        string
+	Filter      string
+	WaitIndex   uint64
+	WaitTime    time.Duration
+	Token       string
+	Namespace   string
+	Partition   string
+}
+
+//WriteOptions represents the options of a consul write request.
+//Token replaces the ACL token of the client, Namespace and Partition are Consul Enterprise features.
+type WriteOptions struct {
+	Datacenter string
+	Token      string
+	Namespace  string
+	Partition  string
+}
+
+//IsConsistencySupported reports whether the given read consistency mode is supported.
+func IsConsistencySupported(consistency string) bool {
+	switch consistency {
+	case "", ConsistencyDefault, ConsistencyConsistent, ConsistencyStale:
+		return true
+	}
+	return false
+}
+
+func toQueryOptions(options QueryOptions) (*consul.QueryOptions, error) {
+	q := &consul.QueryOptions{
+		Datacenter: options.Datacenter,
+		Near:       options.Near,
+		Filter:     options.Filter,
+		WaitIndex:  options.WaitIndex,
+		WaitTime:   options.WaitTime,
+		Token:      options.Token,
+		Namespace:  options.Namespace,
+	}
+	if "" != options.Partition {
+		q = q.WithContext(withPartition(options.Partition))
+	}
+	switch options.Consistency {
+	case "", ConsistencyDefault:
+	case ConsistencyConsistent:
+		q.RequireConsistent = true
+	case ConsistencyStale:
+		q.AllowStale = true
+	default:
+		return nil, fmt.Errorf("%v consistency mode is not valid", options.Consistency)
+	}
+	return q, nil
+}
+
+func toWriteOptions(options WriteOptions) *consul.WriteOptions {
+	w := &consul.WriteOptions{
+		Datacenter: options.Datacenter,
+		Token:      options.Token,
+		Namespace:  options.Namespace,
+	}
+	if "" != options.Partition {
+		w = w.WithContext(withPartition(options.Partition))
+	}
+	return w
+}
+
+//toTxnQueryOptions converts write options to the query options of the transaction endpoint,
+//which are nil when no option is set.
+func toTxnQueryOptions(options WriteOptions) *consul.QueryOptions {
+	if (WriteOptions{}) == options {
+		return nil
+	}
+	q := &consul.QueryOptions{
+		Datacenter: options.Datacenter,
+		Token:      options.Token,
+		Namespace:  options.Namespace,
+	}
+	if "" != options.Partition {
+		q = q.WithContext(withPartition(options.Partition))
+	}
+	return q
+}
+
+//toConsistentQueryOptions converts write options to the query options of the consistent reads a write depends on.
+func toConsistentQueryOptions(options WriteOptions) *consul.QueryOptions {
+	q := &consul.QueryOptions{
+		Datacenter:        options.Datacenter,
+		Token:             options.Token,
+		Namespace:         options.Namespace,
+		RequireConsistent: true,
+	}
+	if "" != options.Partition {
+		q = q.WithContext(withPartition(options.Partition))
+	}
+	return q
+}
+
+func withPartition(partition string) context.Context {
+	return context.WithValue(context.Background(), partitionContextKey{}, partition)
+}
+
+//partitionTransport adds the partition of the request context to the query string,
+//as the consul api version the pack is built with has no partition option.
+type partitionTransport struct {
+	next http.RoundTripper
+}
+
+func (t *partitionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	partition, _ := req.Context().Value(partitionContextKey{}).(string)
+	if "" == partition {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("partition", partition)
+	req.URL.RawQuery = query.Encode()
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestToWriteOptions(t *testing.T) {
+	w := toWriteOptions(WriteOptions{Datacenter: "dc2", Token: "secret", Namespace: "team", Partition: "part"})
+
+	assert.Equal(t, "dc2", w.Datacenter)
+	assert.Equal(t, "secret", w.Token)
+	assert.Equal(t, "team", w.Namespace)
+	assert.Equal(t, "part", w.Context().Value(partitionContextKey{}))
+}
+
+func TestToQueryOptionsWithTokenNamespaceAndPartition(t *testing.T) {
+	q, err := toQueryOptions(QueryOptions{Token: "secret", Namespace: "team", Partition: "part"})
+
+	require.Nil(t, err)
+	assert.Equal(t, "secret", q.Token)
+	assert.Equal(t, "team", q.Namespace)
+	assert.Equal(t, "part", q.Context().Value(partitionContextKey{}))
+}
+
+func TestToTxnQueryOptions(t *testing.T) {
+	assert.Nil(t, toTxnQueryOptions(WriteOptions{}))
+
+	q := toTxnQueryOptions(WriteOptions{Token: "secret"})
+	require.NotNil(t, q)
+	assert.Equal(t, "secret", q.Token)
+	assert.Nil(t, q.Context().Value(partitionContextKey{}))
+}
+
+func TestPartitionTransportAddsPartitionQuery(t *testing.T) {
+	var sent *http.Request
+	transport := &partitionTransport{next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})}
+
+	req, _ := http.NewRequest("PUT", "http://localhost:8500/v1/txn?dc=dc2", nil)
+	_, err := transport.RoundTrip(req.WithContext(withPartition("part")))
+	require.Nil(t, err)
+	assert.Equal(t, "part", sent.URL.Query().Get("partition"))
+	assert.Equal(t, "dc2", sent.URL.Query().Get("dc"))
+	assert.Equal(t, "", req.URL.Query().Get("partition"))
+
+	_, err = transport.RoundTrip(req)
+	require.Nil(t, err)
+	assert.Equal(t, req, sent)
+}
+
+func TestNewClientWrapsTransportOfUnixSocketClient(t *testing.T) {
+	config := consul.DefaultConfig()
+	config.Address = "unix:///var/run/consul.sock"
+
+	_, err := newClient(config)
+	require.Nil(t, err)
+	transport, ok := config.HttpClient.Transport.(*partitionTransport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.next)
+	assert.Equal(t, "/var/run/consul.sock", config.Address)
+}
+
+func TestKVTransactPassesWriteOptions(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		require.NotNil(t, queryOptions)
+		assert.Equal(t, "dc2", queryOptions.Datacenter)
+		assert.Equal(t, "secret", queryOptions.Token)
+		assert.Equal(t, "team", queryOptions.Namespace)
+		assert.Equal(t, "part", queryOptions.Context().Value(partitionContextKey{}))
+		return true, &consul.TxnResponse{}, nil, nil
+	}
+
+	_, _, err := ConsulImpl.KVTransact([]KVOperation{{Verb: "get", Key: "joe/mama"}}, WriteOptions{Datacenter: "dc2", Token: "secret", Namespace: "team", Partition: "part"})
+	require.Nil(t, err)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/HotelsDotCom/go-logger"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBackoff     = "100ms"
+	defaultRetryMaxBackoff  = "2s"
+)
+
+var sleep = time.Sleep
+
+//RetryPolicy defines how requests failing with retryable errors are retried.
+//Backoff is the wait before the second attempt, doubled for each further attempt up to MaxBackoff,
+//and a random jitter of up to half the wait is subtracted. A MaxAttempts of 0 or 1 disables retries.
+type RetryPolicy struct {
+	MaxAttempts int    `json:"maxAttempts,omitempty"`
+	Backoff     string `json:"backoff,omitempty"`
+	MaxBackoff  string `json:"maxBackoff,omitempty"`
+}
+
+//DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+	}
+}
+
+//Validate returns the list of problems with the retry policy.
+func (p RetryPolicy) Validate() []string {
+	errors := []string{}
+	if p.MaxAttempts < 0 {
+		errors = append(errors, "maxAttempts must not be negative")
+	}
+	errors = append(errors, validateDuration("backoff", p.Backoff)...)
+	errors = append(errors, validateDuration("maxBackoff", p.MaxBackoff)...)
+	return errors
+}
+
+//Override returns the policy with the fields set in override replaced.
+func (p RetryPolicy) Override(override RetryPolicy) RetryPolicy {
+	if 0 != override.MaxAttempts {
+		p.MaxAttempts = override.MaxAttempts
+	}
+	if "" != override.Backoff {
+		p.Backoff = override.Backoff
+	}
+	if "" != override.MaxBackoff {
+		p.MaxBackoff = override.MaxBackoff
+	}
+	return p
+}
+
+//Do calls f until it succeeds, fails with an error that is not retryable or the attempts are exhausted.
+//It returns the number of attempts made and the error of the last one.
+func (p RetryPolicy) Do(f func() error) (int, error) {
+	attempts := 0
+	for {
+		attempts++
+		err := f()
+		if nil == err || attempts >= p.MaxAttempts || !isRetryable(err) {
+			return attempts, err
+		}
+
+		wait := p.backoff(attempts)
+		logger.Infof("attempt %d failed, retrying in %v: %v", attempts, wait, err)
+		sleep(wait)
+	}
+}
+
+//backoff returns the jittered wait after the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff, _ := time.ParseDuration(p.Backoff)
+	maxBackoff, _ := time.ParseDuration(p.MaxBackoff)
+
+	wait := backoff
+	for i := 1; i < attempt && (0 == maxBackoff || wait < maxBackoff); i++ {
+		wait *= 2
+	}
+	if 0 != maxBackoff && wait > maxBackoff {
+		wait = maxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait - time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func isRetryable(err error) bool {
+	var requestErr *RequestError
+	return errors.As(err, &requestErr) && requestErr.Retryable
+}
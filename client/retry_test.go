@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withoutSleep(t *testing.T) *[]time.Duration {
+	waits := &[]time.Duration{}
+	sleep = func(d time.Duration) {
+		*waits = append(*waits, d)
+	}
+	t.Cleanup(func() { sleep = time.Sleep })
+	return waits
+}
+
+func TestRetryPolicyDoRetriesRetryableErrors(t *testing.T) {
+	waits := withoutSleep(t)
+
+	calls := 0
+	attempts, err := RetryPolicy{MaxAttempts: 3, Backoff: "100ms"}.Do(func() error {
+		calls++
+		if calls < 3 {
+			return &RequestError{Class: ErrorUnavailable, Retryable: true, Err: fmt.Errorf("No cluster leader")}
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2, len(*waits))
+}
+
+func TestRetryPolicyDoStopsAfterMaxAttempts(t *testing.T) {
+	withoutSleep(t)
+
+	retryable := &RequestError{Class: ErrorUnavailable, Retryable: true, Err: fmt.Errorf("No cluster leader")}
+	attempts, err := RetryPolicy{MaxAttempts: 2}.Do(func() error {
+		return retryable
+	})
+
+	assert.Equal(t, retryable, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryPolicyDoDoesNotRetryOtherErrors(t *testing.T) {
+	waits := withoutSleep(t)
+
+	attempts, err := RetryPolicy{MaxAttempts: 3}.Do(func() error {
+		return &RequestError{Class: ErrorForbidden, Err: fmt.Errorf("Permission denied")}
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts)
+
+	attempts, err = RetryPolicy{MaxAttempts: 3}.Do(func() error {
+		return fmt.Errorf("kablammo")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, *waits)
+}
+
+func TestRetryPolicyBackoffIsExponentialWithJitter(t *testing.T) {
+	policy := RetryPolicy{Backoff: "100ms", MaxBackoff: "300ms"}
+
+	for attempt, expected := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		wait := policy.backoff(attempt)
+		assert.True(t, wait <= expected, "attempt %d waited %v", attempt, wait)
+		assert.True(t, wait >= expected/2, "attempt %d waited %v", attempt, wait)
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+func TestRetryPolicyOverride(t *testing.T) {
+	policy := DefaultRetryPolicy().Override(RetryPolicy{MaxAttempts: 5})
+	assert.Equal(t, RetryPolicy{MaxAttempts: 5, Backoff: "100ms", MaxBackoff: "2s"}, policy)
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	assert.Empty(t, DefaultRetryPolicy().Validate())
+	assert.Equal(t, []string{"maxAttempts must not be negative", "backoff soon is not a valid duration"}, RetryPolicy{MaxAttempts: -1, Backoff: "soon"}.Validate())
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/HotelsDotCom/go-logger"
+	consul "github.com/hashicorp/consul/api"
+)
+
+const maxSemaphoreAttempts = 5
+
+//SemaphoreRequest represents a request to take a semaphore slot with a new session.
+type SemaphoreRequest struct {
+	Prefix  string          `json:"prefix"`
+	Limit   int             `json:"limit"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Session SessionRequest  `json:"session"`
+}
+
+//Semaphore represents the state of a semaphore.
+//Session is only set on the semaphore returned for an acquired slot.
+type Semaphore struct {
+	Prefix  string   `json:"prefix"`
+	Session string   `json:"session,omitempty"`
+	Limit   int      `json:"limit"`
+	Holders []string `json:"holders"`
+}
+
+//semaphoreLock is the coordination document stored under the semaphore prefix,
+//in the same format as the consul semaphore recipe.
+type semaphoreLock struct {
+	Limit   int
+	Holders map[string]bool
+}
+
+//AcquireSemaphore creates a session, registers it as a contender under the prefix and tries to take a slot.
+//When all slots are taken the contender is removed and the current holders are returned.
+func (c *consulClient) AcquireSemaphore(request SemaphoreRequest, options WriteOptions) (bool, Semaphore, error) {
+	session, err := c.CreateSession(request.Session, options)
+	if nil != err {
+		return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: %v", err)
+	}
+
+	contender := &consul.KVPair{
+		Key:     path.Join(request.Prefix, session),
+		Value:   request.Value,
+		Flags:   consul.SemaphoreFlagValue,
+		Session: session,
+	}
+	ok, _, err := c.kvClient.Acquire(contender, toWriteOptions(options))
+	if nil == err && !ok {
+		err = fmt.Errorf("contender key %v could not be locked", contender.Key)
+	}
+	if nil != err {
+		c.abandonSemaphore(request.Prefix, session, options)
+		return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: %v", err)
+	}
+
+	for attempt := 0; attempt < maxSemaphoreAttempts; attempt++ {
+		lock, index, err := c.getSemaphoreLock(request.Prefix, request.Limit, options)
+		if nil != err {
+			c.abandonSemaphore(request.Prefix, session, options)
+			return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: %v", err)
+		}
+		if lock.Limit != request.Limit {
+			c.abandonSemaphore(request.Prefix, session, options)
+			return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: limit %d does not match the semaphore limit %d", request.Limit, lock.Limit)
+		}
+		if len(lock.Holders) >= lock.Limit {
+			c.abandonSemaphore(request.Prefix, session, options)
+			return false, toSemaphore(request.Prefix, "", lock), nil
+		}
+
+		lock.Holders[session] = true
+		ok, err := c.putSemaphoreLock(request.Prefix, lock, index, options)
+		if nil != err {
+			c.abandonSemaphore(request.Prefix, session, options)
+			return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: %v", err)
+		}
+		if ok {
+			return true, toSemaphore(request.Prefix, session, lock), nil
+		}
+	}
+
+	c.abandonSemaphore(request.Prefix, session, options)
+	return false, Semaphore{}, fmt.Errorf("failed to acquire semaphore: semaphore was modified concurrently %d times", maxSemaphoreAttempts)
+}
+
+//ReleaseSemaphore frees the slot held by the session, removes its contender key and destroys the session.
+//When the session is not a contender of the semaphore nothing is changed and the current state is returned.
+func (c *consulClient) ReleaseSemaphore(prefix, session string, options WriteOptions) (bool, Semaphore, error) {
+	contender, err := c.getSemaphoreContender(prefix, session, options)
+	if nil != err {
+		return false, Semaphore{}, fmt.Errorf("failed to release semaphore: %v", err)
+	}
+
+	for attempt := 0; attempt < maxSemaphoreAttempts; attempt++ {
+		lock, index, err := c.getSemaphoreLock(prefix, 0, options)
+		if nil != err {
+			return false, Semaphore{}, fmt.Errorf("failed to release semaphore: %v", err)
+		}
+		if nil == contender {
+			return false, toSemaphore(prefix, "", lock), nil
+		}
+		if _, ok := lock.Holders[session]; !ok || 0 == index {
+			c.removeSemaphoreContender(contender, session, options)
+			return true, toSemaphore(prefix, "", lock), nil
+		}
+
+		delete(lock.Holders, session)
+		ok, err := c.putSemaphoreLock(prefix, lock, index, options)
+		if nil != err {
+			return false, Semaphore{}, fmt.Errorf("failed to release semaphore: %v", err)
+		}
+		if ok {
+			c.removeSemaphoreContender(contender, session, options)
+			return true, toSemaphore(prefix, "", lock), nil
+		}
+	}
+
+	return false, Semaphore{}, fmt.Errorf("failed to release semaphore: semaphore was modified concurrently %d times", maxSemaphoreAttempts)
+}
+
+//getSemaphoreContender reads the contender key of the session.
+//It returns nil when the key does not exist or is not a semaphore contender locked by the session.
+func (c *consulClient) getSemaphoreContender(prefix, session string, options WriteOptions) (*consul.KVPair, error) {
+	q := &consul.QueryOptions{
+		Datacenter:        options.Datacenter,
+		RequireConsistent: true,
+	}
+	pair, _, err := c.kvClient.Get(path.Join(prefix, session), q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to read contender: %v", err)
+	}
+	if nil == pair || session != pair.Session || consul.SemaphoreFlagValue != pair.Flags {
+		return nil, nil
+	}
+	return pair, nil
+}
+
+//getSemaphoreLock reads the coordination document and prunes the holders without a live contender key.
+//It returns a new document with the given limit and a zero index when the semaphore does not exist yet.
+func (c *consulClient) getSemaphoreLock(prefix string, limit int, options WriteOptions) (*semaphoreLock, uint64, error) {
+	q := &consul.QueryOptions{
+		Datacenter:        options.Datacenter,
+		RequireConsistent: true,
+	}
+	pairs, _, err := c.kvClient.List(strings.TrimSuffix(prefix, "/")+"/", q)
+	if nil != err {
+		return nil, 0, fmt.Errorf("failed to list contenders: %v", err)
+	}
+
+	lock := &semaphoreLock{Limit: limit, Holders: map[string]bool{}}
+	var index uint64
+	alive := map[string]bool{}
+	lockKey := path.Join(prefix, consul.DefaultSemaphoreKey)
+	for _, pair := range pairs {
+		if lockKey != pair.Key {
+			if "" != pair.Session {
+				alive[pair.Session] = true
+			}
+			continue
+		}
+		if consul.SemaphoreFlagValue != pair.Flags {
+			return nil, 0, fmt.Errorf("key %v is not a semaphore", lockKey)
+		}
+		if err := json.Unmarshal(pair.Value, lock); nil != err {
+			return nil, 0, fmt.Errorf("failed to decode semaphore: %v", err)
+		}
+		index = pair.ModifyIndex
+	}
+
+	for holder := range lock.Holders {
+		if !alive[holder] {
+			delete(lock.Holders, holder)
+		}
+	}
+	return lock, index, nil
+}
+
+func (c *consulClient) putSemaphoreLock(prefix string, lock *semaphoreLock, index uint64, options WriteOptions) (bool, error) {
+	value, err := json.Marshal(lock)
+	if nil != err {
+		return false, fmt.Errorf("failed to encode semaphore: %v", err)
+	}
+
+	pair := &consul.KVPair{
+		Key:         path.Join(prefix, consul.DefaultSemaphoreKey),
+		Value:       value,
+		Flags:       consul.SemaphoreFlagValue,
+		ModifyIndex: index,
+	}
+	ok, _, err := c.kvClient.CAS(pair, toWriteOptions(options))
+	if nil != err {
+		return false, fmt.Errorf("failed to update semaphore: %v", err)
+	}
+	return ok, nil
+}
+
+//abandonSemaphore removes the contender key of the session, if it holds one, and destroys the session.
+//Failures are only logged, the session ttl or health checks clean up eventually.
+func (c *consulClient) abandonSemaphore(prefix, session string, options WriteOptions) {
+	contender, err := c.getSemaphoreContender(prefix, session, options)
+	if nil != err {
+		logger.Errorf("failed to read semaphore contender %v: %v", session, err)
+	}
+	c.removeSemaphoreContender(contender, session, options)
+}
+
+//removeSemaphoreContender deletes the contender key, unless it changed since it was read, and destroys the session.
+//Failures are only logged, the session ttl or health checks clean up eventually.
+func (c *consulClient) removeSemaphoreContender(contender *consul.KVPair, session string, options WriteOptions) {
+	if nil != contender {
+		if _, _, err := c.kvClient.DeleteCAS(contender, toWriteOptions(options)); nil != err {
+			logger.Errorf("failed to delete semaphore contender %v: %v", session, err)
+		}
+	}
+	if err := c.destroySession(session, options); nil != err {
+		logger.Errorf("failed to clean up semaphore session %v: %v", session, err)
+	}
+}
+
+func toSemaphore(prefix, session string, lock *semaphoreLock) Semaphore {
+	holders := make([]string, 0, len(lock.Holders))
+	for holder := range lock.Holders {
+		holders = append(holders, holder)
+	}
+	sort.Strings(holders)
+
+	return Semaphore{
+		Prefix:  prefix,
+		Session: session,
+		Limit:   lock.Limit,
+		Holders: holders,
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireSemaphore(t *testing.T) {
+	Before(t)
+	defer After()
+
+	var written semaphoreLock
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "c", nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "deploys/c", p.Key)
+		assert.Equal(t, "c", p.Session)
+		return true, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		assert.Equal(t, "deploys/", prefix)
+		assert.True(t, q.RequireConsistent)
+		return getSemaphorePairs(t, 3, "a", "b"), nil, nil
+	}
+	ConsulMockKVClient.CASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, "deploys/.lock", p.Key)
+		assert.Equal(t, uint64(42), p.ModifyIndex)
+		assert.Equal(t, uint64(consul.SemaphoreFlagValue), p.Flags)
+		require.Nil(t, json.Unmarshal(p.Value, &written))
+		return true, nil, nil
+	}
+
+	ok, semaphore, err := ConsulImpl.AcquireSemaphore(SemaphoreRequest{Prefix: "deploys", Limit: 3}, WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Semaphore{Prefix: "deploys", Session: "c", Limit: 3, Holders: []string{"a", "b", "c"}}, semaphore)
+	assert.Equal(t, 3, written.Limit)
+	assert.Equal(t, 3, len(written.Holders))
+}
+
+func TestAcquireSemaphoreFull(t *testing.T) {
+	Before(t)
+	defer After()
+
+	deleted := ""
+	destroyed := ""
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "c", nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return true, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Flags: consul.SemaphoreFlagValue, Session: "c", ModifyIndex: 7}, nil, nil
+	}
+	ConsulMockKVClient.DeleteCASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, uint64(7), p.ModifyIndex)
+		deleted = p.Key
+		return true, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return getSemaphorePairs(t, 2, "a", "b"), nil, nil
+	}
+
+	ok, semaphore, err := ConsulImpl.AcquireSemaphore(SemaphoreRequest{Prefix: "deploys", Limit: 2}, WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, Semaphore{Prefix: "deploys", Limit: 2, Holders: []string{"a", "b"}}, semaphore)
+	assert.Equal(t, "deploys/c", deleted)
+	assert.Equal(t, "c", destroyed)
+}
+
+func TestAcquireSemaphorePrunesDeadHolders(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "c", nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return true, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		pairs := getSemaphorePairs(t, 2, "a", "b")
+		return pairs[1:], nil, nil
+	}
+	ConsulMockKVClient.CASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return true, nil, nil
+	}
+
+	ok, semaphore, err := ConsulImpl.AcquireSemaphore(SemaphoreRequest{Prefix: "deploys", Limit: 2}, WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"b", "c"}, semaphore.Holders)
+}
+
+func TestAcquireSemaphoreLimitConflict(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "c", nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		return nil, nil
+	}
+	ConsulMockKVClient.AcquireFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return true, nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Flags: consul.SemaphoreFlagValue, Session: "c"}, nil, nil
+	}
+	ConsulMockKVClient.DeleteCASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		return true, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return getSemaphorePairs(t, 2, "a"), nil, nil
+	}
+
+	_, _, err := ConsulImpl.AcquireSemaphore(SemaphoreRequest{Prefix: "deploys", Limit: 5}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to acquire semaphore: limit 5 does not match the semaphore limit 2", err.Error())
+}
+
+func TestReleaseSemaphore(t *testing.T) {
+	Before(t)
+	defer After()
+
+	var written semaphoreLock
+	deleted := ""
+	destroyed := ""
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		assert.Equal(t, "deploys/a", key)
+		assert.True(t, q.RequireConsistent)
+		return &consul.KVPair{Key: key, Flags: consul.SemaphoreFlagValue, Session: "a", ModifyIndex: 7}, nil, nil
+	}
+	ConsulMockKVClient.DeleteCASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		assert.Equal(t, uint64(7), p.ModifyIndex)
+		deleted = p.Key
+		return true, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return getSemaphorePairs(t, 2, "a", "b"), nil, nil
+	}
+	ConsulMockKVClient.CASFunc = func(p *consul.KVPair, q *consul.WriteOptions) (bool, *consul.WriteMeta, error) {
+		require.Nil(t, json.Unmarshal(p.Value, &written))
+		return true, nil, nil
+	}
+
+	ok, semaphore, err := ConsulImpl.ReleaseSemaphore("deploys", "a", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"b"}, semaphore.Holders)
+	assert.Equal(t, map[string]bool{"b": true}, written.Holders)
+	assert.Equal(t, "deploys/a", deleted)
+	assert.Equal(t, "a", destroyed)
+}
+
+func TestReleaseSemaphoreLeavesKeysOfOtherSessions(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Value: []byte("hunter2")}, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return consul.KVPairs{}, nil, nil
+	}
+
+	ok, semaphore, err := ConsulImpl.ReleaseSemaphore("secret", "db-password", WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, Semaphore{Prefix: "secret", Holders: []string{}}, semaphore)
+}
+
+func TestReleaseSemaphoreFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockKVClient.GetFunc = func(key string, q *consul.QueryOptions) (*consul.KVPair, *consul.QueryMeta, error) {
+		return &consul.KVPair{Key: key, Flags: consul.SemaphoreFlagValue, Session: "a"}, nil, nil
+	}
+	ConsulMockKVClient.ListFunc = func(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
+		return nil, nil, errors.New("kablammo")
+	}
+
+	_, _, err := ConsulImpl.ReleaseSemaphore("deploys", "a", WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to release semaphore: failed to list contenders: kablammo", err.Error())
+}
+
+func getSemaphorePairs(t *testing.T, limit int, holders ...string) consul.KVPairs {
+	lock := semaphoreLock{Limit: limit, Holders: map[string]bool{}}
+	pairs := consul.KVPairs{}
+	for _, holder := range holders {
+		lock.Holders[holder] = true
+		pairs = append(pairs, &consul.KVPair{Key: "deploys/" + holder, Session: holder})
+	}
+	pairs = append(pairs, &consul.KVPair{Key: "deploys/c", Session: "c"})
+
+	value, err := json.Marshal(lock)
+	require.Nil(t, err)
+	return append(pairs, &consul.KVPair{Key: "deploys/.lock", Value: value, Flags: consul.SemaphoreFlagValue, ModifyIndex: 42})
+}
@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+const (
+	minSessionTTL = 10 * time.Second
+	maxSessionTTL = 24 * time.Hour
+)
+
+type sessionClient interface {
+	Create(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error)
+	Renew(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error)
+	Destroy(id string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	Info(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error)
+	List(q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error)
+	Node(node string, q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error)
+}
+
+//SessionRequest represents the settings of a consul session to create.
+//The session is bound to the serfHealth check of the agent's node when neither Node nor Checks are set.
+type SessionRequest struct {
+	Name      string   `json:"name,omitempty"`
+	Node      string   `json:"node,omitempty"`
+	Checks    []string `json:"checks,omitempty"`
+	TTL       string   `json:"ttl,omitempty"`
+	Behavior  string   `json:"behavior,omitempty"`
+	LockDelay string   `json:"lockDelay,omitempty"`
+}
+
+//Session represents a consul session.
+type Session struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Node        string   `json:"node"`
+	Checks      []string `json:"checks"`
+	TTL         string   `json:"ttl,omitempty"`
+	Behavior    string   `json:"behavior"`
+	LockDelay   string   `json:"lockDelay"`
+	CreateIndex uint64   `json:"createIndex"`
+}
+
+//Validate returns the list of problems with the session request.
+func (s SessionRequest) Validate() []string {
+	errors := []string{}
+	if "" != s.TTL {
+		ttl, err := time.ParseDuration(s.TTL)
+		if nil != err {
+			errors = append(errors, fmt.Sprintf("ttl %v is not a valid duration", s.TTL))
+		} else if ttl < minSessionTTL || ttl > maxSessionTTL {
+			errors = append(errors, fmt.Sprintf("ttl must be between %v and %v", minSessionTTL, maxSessionTTL))
+		}
+	}
+	if "" != s.Behavior && consul.SessionBehaviorRelease != s.Behavior && consul.SessionBehaviorDelete != s.Behavior {
+		errors = append(errors, fmt.Sprintf("behavior %v is not valid", s.Behavior))
+	}
+	errors = append(errors, validateDuration("lockDelay", s.LockDelay)...)
+	return errors
+}
+
+//CreateSession creates a session and returns its ID.
+func (c *consulClient) CreateSession(request SessionRequest, options WriteOptions) (string, error) {
+	entry := &consul.SessionEntry{
+		Name:     request.Name,
+		Node:     request.Node,
+		Checks:   request.Checks,
+		TTL:      request.TTL,
+		Behavior: request.Behavior,
+	}
+	if "" != request.LockDelay {
+		lockDelay, err := time.ParseDuration(request.LockDelay)
+		if nil != err {
+			return "", fmt.Errorf("failed to create session: %v", err)
+		}
+		entry.LockDelay = lockDelay
+	}
+
+	id, _, err := c.sessionClient.Create(entry, toWriteOptions(options))
+	if nil != err {
+		return "", fmt.Errorf("failed to create session: %v", err)
+	}
+	return id, nil
+}
+
+//RenewSession resets the ttl of the session. It returns nil when the session does not exist.
+func (c *consulClient) RenewSession(id string, options WriteOptions) (*Session, error) {
+	entry, _, err := c.sessionClient.Renew(id, toWriteOptions(options))
+	if nil != err {
+		return nil, fmt.Errorf("failed to renew session: %v", err)
+	}
+	if nil == entry {
+		return nil, nil
+	}
+	retval := toSession(entry)
+	return &retval, nil
+}
+
+//DestroySession invalidates the session. It returns false when the session does not exist.
+func (c *consulClient) DestroySession(id string, options WriteOptions) (bool, error) {
+	entry, _, err := c.sessionClient.Info(id, &consul.QueryOptions{Datacenter: options.Datacenter})
+	if nil != err {
+		return false, fmt.Errorf("failed to destroy session: %v", err)
+	}
+	if nil == entry {
+		return false, nil
+	}
+	if err := c.destroySession(id, options); nil != err {
+		return false, err
+	}
+	return true, nil
+}
+
+//GetSession returns the session. It returns nil when the session does not exist.
+func (c *consulClient) GetSession(id string, options QueryOptions) (*Session, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	entry, _, err := c.sessionClient.Info(id, q)
+	if nil != err {
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+	if nil == entry {
+		return nil, nil
+	}
+	retval := toSession(entry)
+	return &retval, nil
+}
+
+//ListSessions returns all sessions, or the sessions of the given node when node is set.
+func (c *consulClient) ListSessions(node string, options QueryOptions) ([]Session, error) {
+	q, err := toQueryOptions(options)
+	if nil != err {
+		return nil, err
+	}
+
+	var entries []*consul.SessionEntry
+	if "" == node {
+		entries, _, err = c.sessionClient.List(q)
+	} else {
+		entries, _, err = c.sessionClient.Node(node, q)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("failed to list sessions: %v", err)
+	}
+
+	retval := make([]Session, len(entries))
+	for index, entry := range entries {
+		retval[index] = toSession(entry)
+	}
+	return retval, nil
+}
+
+func (c *consulClient) destroySession(id string, options WriteOptions) error {
+	if _, err := c.sessionClient.Destroy(id, toWriteOptions(options)); nil != err {
+		return fmt.Errorf("failed to destroy session: %v", err)
+	}
+	return nil
+}
+
+func toSession(entry *consul.SessionEntry) Session {
+	return Session{
+		ID:          entry.ID,
+		Name:        entry.Name,
+		Node:        entry.Node,
+		Checks:      entry.Checks,
+		TTL:         entry.TTL,
+		Behavior:    entry.Behavior,
+		LockDelay:   entry.LockDelay.String(),
+		CreateIndex: entry.CreateIndex,
+	}
+}
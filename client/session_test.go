@@ -0,0 +1,220 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRequestValidate(t *testing.T) {
+	request := SessionRequest{TTL: "30s", Behavior: "delete", LockDelay: "5s"}
+	assert.Empty(t, request.Validate())
+}
+
+func TestSessionRequestValidateInvalid(t *testing.T) {
+	request := SessionRequest{TTL: "5s", Behavior: "explode", LockDelay: "soon"}
+	errors := request.Validate()
+	require.Equal(t, 3, len(errors))
+	assert.Equal(t, "ttl must be between 10s and 24h0m0s", errors[0])
+	assert.Equal(t, "behavior explode is not valid", errors[1])
+	assert.Equal(t, "lockDelay soon is not a valid duration", errors[2])
+}
+
+func TestCreateSession(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		assert.Equal(t, "deploy", se.Name)
+		assert.Equal(t, "15s", se.TTL)
+		assert.Equal(t, 2*time.Second, se.LockDelay)
+		assert.Equal(t, "dc2", q.Datacenter)
+		return "session-id", nil, nil
+	}
+
+	id, err := ConsulImpl.CreateSession(SessionRequest{Name: "deploy", TTL: "15s", LockDelay: "2s"}, WriteOptions{Datacenter: "dc2"})
+	require.Nil(t, err)
+	assert.Equal(t, "session-id", id)
+}
+
+func TestCreateSessionFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.CreateFunc = func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+		return "", nil, errors.New("kablammo")
+	}
+
+	_, err := ConsulImpl.CreateSession(SessionRequest{}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to create session: kablammo", err.Error())
+}
+
+func TestRenewSession(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.RenewFunc = func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+		return getSessionEntry(id), nil, nil
+	}
+
+	session, err := ConsulImpl.RenewSession("session-id", WriteOptions{})
+	require.Nil(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, "session-id", session.ID)
+	assert.Equal(t, "15s", session.LockDelay)
+}
+
+func TestRenewSessionNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.RenewFunc = func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+		return nil, nil, nil
+	}
+
+	session, err := ConsulImpl.RenewSession("session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.Nil(t, session)
+}
+
+func TestDestroySession(t *testing.T) {
+	Before(t)
+	defer After()
+
+	destroyed := ""
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		return getSessionEntry(id), nil, nil
+	}
+	ConsulMockSessionClient.DestroyFunc = func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+		destroyed = id
+		return nil, nil
+	}
+
+	ok, err := ConsulImpl.DestroySession("session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "session-id", destroyed)
+}
+
+func TestDestroySessionNotFound(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		return nil, nil, nil
+	}
+
+	ok, err := ConsulImpl.DestroySession("session-id", WriteOptions{})
+	require.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestGetSession(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.InfoFunc = func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+		assert.True(t, q.RequireConsistent)
+		return getSessionEntry(id), nil, nil
+	}
+
+	session, err := ConsulImpl.GetSession("session-id", QueryOptions{Consistency: ConsistencyConsistent})
+	require.Nil(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, []string{"serfHealth"}, session.Checks)
+	assert.Equal(t, "release", session.Behavior)
+}
+
+func TestListSessions(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.ListFunc = func(q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error) {
+		return []*consul.SessionEntry{getSessionEntry("a"), getSessionEntry("b")}, nil, nil
+	}
+
+	sessions, err := ConsulImpl.ListSessions("", QueryOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(sessions))
+	assert.Equal(t, "b", sessions[1].ID)
+}
+
+func TestListNodeSessions(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockSessionClient.NodeFunc = func(node string, q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error) {
+		assert.Equal(t, "node-1", node)
+		return nil, nil, errors.New("kablammo")
+	}
+
+	_, err := ConsulImpl.ListSessions("node-1", QueryOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to list sessions: kablammo", err.Error())
+}
+
+func getSessionEntry(id string) *consul.SessionEntry {
+	return &consul.SessionEntry{
+		ID:        id,
+		Node:      "node-1",
+		Checks:    []string{"serfHealth"},
+		Behavior:  consul.SessionBehaviorRelease,
+		LockDelay: 15 * time.Second,
+	}
+}
+
+type MockSessionClient struct {
+	t           *testing.T
+	CreateFunc  func(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error)
+	RenewFunc   func(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error)
+	DestroyFunc func(id string, q *consul.WriteOptions) (*consul.WriteMeta, error)
+	InfoFunc    func(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error)
+	ListFunc    func(q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error)
+	NodeFunc    func(node string, q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error)
+}
+
+func NewMockSessionClient(t *testing.T) *MockSessionClient {
+	return &MockSessionClient{t: t}
+}
+
+func (m *MockSessionClient) Create(se *consul.SessionEntry, q *consul.WriteOptions) (string, *consul.WriteMeta, error) {
+	return m.CreateFunc(se, q)
+}
+
+func (m *MockSessionClient) Renew(id string, q *consul.WriteOptions) (*consul.SessionEntry, *consul.WriteMeta, error) {
+	return m.RenewFunc(id, q)
+}
+
+func (m *MockSessionClient) Destroy(id string, q *consul.WriteOptions) (*consul.WriteMeta, error) {
+	return m.DestroyFunc(id, q)
+}
+
+func (m *MockSessionClient) Info(id string, q *consul.QueryOptions) (*consul.SessionEntry, *consul.QueryMeta, error) {
+	return m.InfoFunc(id, q)
+}
+
+func (m *MockSessionClient) List(q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error) {
+	return m.ListFunc(q)
+}
+
+func (m *MockSessionClient) Node(node string, q *consul.QueryOptions) ([]*consul.SessionEntry, *consul.QueryMeta, error) {
+	return m.NodeFunc(node, q)
+}
@@ -0,0 +1,326 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+const (
+	txnOperationKV      = "kv"
+	txnOperationNode    = "node"
+	txnOperationService = "service"
+	txnOperationCheck   = "check"
+)
+
+//TxnOperation represents a single operation of a mixed transaction.
+//Exactly one of KV, Node, Service, Check and Session must be set.
+type TxnOperation struct {
+	KV      *KVOperation      `json:"kv,omitempty"`
+	Node    *NodeOperation    `json:"node,omitempty"`
+	Service *ServiceOperation `json:"service,omitempty"`
+	Check   *CheckOperation   `json:"check,omitempty"`
+	Session *SessionOperation `json:"session,omitempty"`
+}
+
+//NodeOperation represents a catalog node transaction operation.
+//Index is the modify index used by the cas and delete-cas verbs.
+type NodeOperation struct {
+	Verb            string            `json:"verb"`
+	Node            string            `json:"node"`
+	ID              string            `json:"id,omitempty"`
+	Address         string            `json:"address,omitempty"`
+	TaggedAddresses map[string]string `json:"taggedAddresses,omitempty"`
+	Meta            map[string]string `json:"meta,omitempty"`
+	Index           uint64            `json:"index,omitempty"`
+}
+
+//ServiceOperation represents a catalog service transaction operation.
+//Index is the modify index used by the cas and delete-cas verbs.
+type ServiceOperation struct {
+	Verb    string            `json:"verb"`
+	Node    string            `json:"node"`
+	ID      string            `json:"id"`
+	Name    string            `json:"name,omitempty"`
+	Address string            `json:"address,omitempty"`
+	Port    int               `json:"port,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Index   uint64            `json:"index,omitempty"`
+}
+
+//CheckOperation represents a catalog health check transaction operation.
+//Index is the modify index used by the cas and delete-cas verbs.
+type CheckOperation struct {
+	Verb      string `json:"verb"`
+	Node      string `json:"node"`
+	CheckID   string `json:"checkId"`
+	Name      string `json:"name,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+	Output    string `json:"output,omitempty"`
+	ServiceID string `json:"serviceId,omitempty"`
+	Index     uint64 `json:"index,omitempty"`
+}
+
+//SessionOperation represents a session transaction operation.
+type SessionOperation struct {
+	Verb string `json:"verb"`
+	ID   string `json:"id"`
+}
+
+//TxnResult represents the result of a mixed transaction operation.
+//Index is the position of the operation in the transaction, delete and check-not-exists operations
+//have no result and get-tree operations have one per key.
+//Type names the operation type and the matching field holds the result.
+type TxnResult struct {
+	Index   int              `json:"index"`
+	Type    string           `json:"type"`
+	KV      *KVPair          `json:"kv,omitempty"`
+	Node    *Node            `json:"node,omitempty"`
+	Service *ServiceInstance `json:"service,omitempty"`
+	Check   *HealthCheck     `json:"check,omitempty"`
+}
+
+//TxnError represents a mixed transaction error.
+type TxnError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+//Validate returns the list of problems with the transaction operation.
+func (o TxnOperation) Validate() []string {
+	set := 0
+	for _, isSet := range []bool{nil != o.KV, nil != o.Node, nil != o.Service, nil != o.Check, nil != o.Session} {
+		if isSet {
+			set++
+		}
+	}
+	if 1 != set {
+		return []string{"exactly one of kv, node, service, check and session must be set"}
+	}
+
+	errors := []string{}
+	switch {
+	case nil != o.KV:
+		if "" == o.KV.Key {
+			errors = append(errors, "key is missing")
+		}
+		if _, ok := getSupportedVerbs()[o.KV.Verb]; !ok {
+			errors = append(errors, fmt.Sprintf("%v verb is not valid", o.KV.Verb))
+		}
+		errors = append(errors, o.KV.Validate()...)
+	case nil != o.Node:
+		if "" == o.Node.Node {
+			errors = append(errors, "node is missing")
+		}
+		errors = append(errors, validateCatalogVerb(o.Node.Verb, o.Node.Index)...)
+	case nil != o.Service:
+		if "" == o.Service.Node {
+			errors = append(errors, "node is missing")
+		}
+		if "" == o.Service.ID {
+			errors = append(errors, "id is missing")
+		}
+		errors = append(errors, validateCatalogVerb(o.Service.Verb, o.Service.Index)...)
+	case nil != o.Check:
+		if "" == o.Check.Node {
+			errors = append(errors, "node is missing")
+		}
+		if "" == o.Check.CheckID {
+			errors = append(errors, "checkId is missing")
+		}
+		errors = append(errors, validateCatalogVerb(o.Check.Verb, o.Check.Index)...)
+	case nil != o.Session:
+		// the consul api version in use cannot encode session operations
+		errors = append(errors, "session operations are not supported")
+	}
+	return errors
+}
+
+func validateCatalogVerb(verb string, index uint64) []string {
+	switch verb {
+	case "get", "set", "delete":
+		return nil
+	case "cas", "delete-cas":
+		if 0 == index {
+			return []string{fmt.Sprintf("%v verb requires an index", verb)}
+		}
+		return nil
+	}
+	return []string{fmt.Sprintf("%v verb is not valid", verb)}
+}
+
+func (c *consulClient) Transact(operations []TxnOperation, options WriteOptions) ([]TxnResult, []TxnError, error) {
+	input := consul.TxnOps{}
+	encodings := kvEncodings{}
+	for index, operation := range operations {
+		op, err := toTxnOp(operation)
+		if nil != err {
+			return nil, []TxnError{{Index: index, Error: err.Error()}}, nil
+		}
+		input = append(input, op)
+		if nil != operation.KV {
+			encodings = append(encodings, *operation.KV)
+		}
+	}
+
+	ok, response, _, err := c.txnClient.Txn(input, toTxnQueryOptions(options))
+	if nil != err {
+		return nil, nil, newRequestError("failed to make transaction request", err)
+	}
+	if !ok {
+		errors := make([]TxnError, len(response.Errors))
+		for index, value := range response.Errors {
+			errors[index] = TxnError{Index: value.OpIndex, Error: value.What}
+		}
+		return nil, errors, nil
+	}
+
+	return toTxnResults(operations, response.Results, encodings), nil, nil
+}
+
+//toTxnResults maps the results, returned in order for the operations that have any, to their operation index.
+//A get-tree operation takes the following results under its prefix, leaving one for every later operation
+//that always has a result.
+func toTxnResults(operations []TxnOperation, values consul.TxnResults, encodings kvEncodings) []TxnResult {
+	pending := 0
+	for _, operation := range operations {
+		pending += txnResultCount(operation)
+	}
+
+	results := make([]TxnResult, 0, len(values))
+	next := 0
+	for index, operation := range operations {
+		if nil != operation.KV && string(consul.KVGetTree) == operation.KV.Verb {
+			for next < len(values)-pending && nil != values[next].KV && strings.HasPrefix(values[next].KV.Key, operation.KV.Key) {
+				results = append(results, toTxnResult(index, values[next], encodings))
+				next++
+			}
+			continue
+		}
+		if 1 == txnResultCount(operation) && next < len(values) {
+			results = append(results, toTxnResult(index, values[next], encodings))
+			next++
+			pending--
+		}
+	}
+	return results
+}
+
+//txnResultCount returns the number of results consul returns for the operation.
+//It is 0 for get-tree, whose number of results depends on the keys under its prefix.
+func txnResultCount(operation TxnOperation) int {
+	verb := ""
+	switch {
+	case nil != operation.KV:
+		switch consul.KVOp(operation.KV.Verb) {
+		case consul.KVGetTree, consul.KVDelete, consul.KVDeleteCAS, consul.KVDeleteTree, consul.KVCheckNotExists:
+			return 0
+		}
+		return 1
+	case nil != operation.Node:
+		verb = operation.Node.Verb
+	case nil != operation.Service:
+		verb = operation.Service.Verb
+	case nil != operation.Check:
+		verb = operation.Check.Verb
+	}
+	if "delete" == verb || "delete-cas" == verb {
+		return 0
+	}
+	return 1
+}
+
+func toTxnOp(operation TxnOperation) (*consul.TxnOp, error) {
+	switch {
+	case nil != operation.KV:
+		kvOp, err := toTxnKVOp(*operation.KV)
+		if nil != err {
+			return nil, err
+		}
+		return &consul.TxnOp{KV: &kvOp}, nil
+	case nil != operation.Node:
+		node := operation.Node
+		return &consul.TxnOp{Node: &consul.NodeTxnOp{
+			Verb: consul.NodeOp(node.Verb),
+			Node: consul.Node{
+				ID:              node.ID,
+				Node:            node.Node,
+				Address:         node.Address,
+				TaggedAddresses: node.TaggedAddresses,
+				Meta:            node.Meta,
+				ModifyIndex:     node.Index,
+			},
+		}}, nil
+	case nil != operation.Service:
+		service := operation.Service
+		return &consul.TxnOp{Service: &consul.ServiceTxnOp{
+			Verb: consul.ServiceOp(service.Verb),
+			Node: service.Node,
+			Service: consul.AgentService{
+				ID:          service.ID,
+				Service:     service.Name,
+				Address:     service.Address,
+				Port:        service.Port,
+				Tags:        service.Tags,
+				Meta:        service.Meta,
+				ModifyIndex: service.Index,
+			},
+		}}, nil
+	case nil != operation.Check:
+		check := operation.Check
+		return &consul.TxnOp{Check: &consul.CheckTxnOp{
+			Verb: consul.CheckOp(check.Verb),
+			Check: consul.HealthCheck{
+				Node:        check.Node,
+				CheckID:     check.CheckID,
+				Name:        check.Name,
+				Status:      check.Status,
+				Notes:       check.Notes,
+				Output:      check.Output,
+				ServiceID:   check.ServiceID,
+				ModifyIndex: check.Index,
+			},
+		}}, nil
+	}
+	return &consul.TxnOp{}, nil
+}
+
+func toTxnResult(index int, result *consul.TxnResult, encodings kvEncodings) TxnResult {
+	retval := TxnResult{Index: index}
+	switch {
+	case nil != result.KV:
+		pair := toKVPair(result.KV)
+		pair.Value = decodeValue(result.KV.Value, encodings.get(result.KV.Key))
+		retval.Type = txnOperationKV
+		retval.KV = &pair
+	case nil != result.Node:
+		node := toNode(result.Node)
+		retval.Type = txnOperationNode
+		retval.Node = &node
+	case nil != result.Service:
+		service := toServiceInstance(result.Service)
+		retval.Type = txnOperationService
+		retval.Service = &service
+	case nil != result.Check:
+		check := toHealthCheck(result.Check)
+		retval.Type = txnOperationCheck
+		retval.Check = &check
+	}
+	return retval
+}
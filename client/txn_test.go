@@ -0,0 +1,149 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxnOperationValidate(t *testing.T) {
+	operations := []TxnOperation{
+		{KV: &KVOperation{Verb: "set", Key: "joe/mama"}},
+		{Node: &NodeOperation{Verb: "set", Node: "node-1"}},
+		{Service: &ServiceOperation{Verb: "cas", Node: "node-1", ID: "web-1", Index: 3}},
+		{Check: &CheckOperation{Verb: "delete", Node: "node-1", CheckID: "web"}},
+	}
+	for _, operation := range operations {
+		assert.Empty(t, operation.Validate())
+	}
+}
+
+func TestTxnOperationValidateInvalid(t *testing.T) {
+	assert.Equal(t, []string{"exactly one of kv, node, service, check and session must be set"}, TxnOperation{}.Validate())
+	assert.Equal(t, []string{"key is missing", "boom verb is not valid"}, TxnOperation{KV: &KVOperation{Verb: "boom"}}.Validate())
+	assert.Equal(t, []string{"node is missing", "cas verb requires an index"}, TxnOperation{Node: &NodeOperation{Verb: "cas"}}.Validate())
+	assert.Equal(t, []string{"id is missing"}, TxnOperation{Service: &ServiceOperation{Verb: "get", Node: "node-1"}}.Validate())
+	assert.Equal(t, []string{"checkId is missing", "boom verb is not valid"}, TxnOperation{Check: &CheckOperation{Verb: "boom", Node: "node-1"}}.Validate())
+	assert.Equal(t, []string{"session operations are not supported"}, TxnOperation{Session: &SessionOperation{Verb: "delete", ID: "a"}}.Validate())
+}
+
+func TestTransact(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		require.Equal(t, 3, len(operations))
+		assert.Equal(t, "dc2", queryOptions.Datacenter)
+		assert.Equal(t, consul.KVSet, operations[0].KV.Verb)
+		assert.Equal(t, consul.ServiceCAS, operations[1].Service.Verb)
+		assert.Equal(t, "web", operations[1].Service.Service.Service)
+		assert.Equal(t, uint64(7), operations[1].Service.Service.ModifyIndex)
+		assert.Equal(t, consul.CheckSet, operations[2].Check.Verb)
+		assert.Equal(t, "passing", operations[2].Check.Check.Status)
+		return true, &consul.TxnResponse{Results: consul.TxnResults{
+			{KV: &consul.KVPair{Key: "joe/mama", ModifyIndex: 8}},
+			{Service: &consul.CatalogService{ServiceID: "web-1", ServiceName: "web", Node: "node-1"}},
+			{Check: &consul.HealthCheck{CheckID: "web", Status: "passing"}},
+		}}, nil, nil
+	}
+
+	operations := []TxnOperation{
+		{KV: &KVOperation{Verb: "set", Key: "joe/mama"}},
+		{Service: &ServiceOperation{Verb: "cas", Node: "node-1", ID: "web-1", Name: "web", Index: 7}},
+		{Check: &CheckOperation{Verb: "set", Node: "node-1", CheckID: "web", Status: "passing"}},
+	}
+	results, rollback, err := ConsulImpl.Transact(operations, WriteOptions{Datacenter: "dc2"})
+
+	require.Nil(t, err)
+	assert.Nil(t, rollback)
+	require.Equal(t, 3, len(results))
+	assert.Equal(t, "kv", results[0].Type)
+	assert.Equal(t, uint64(8), results[0].KV.ModifyIndex)
+	assert.Equal(t, "service", results[1].Type)
+	assert.Equal(t, 1, results[1].Index)
+	assert.Equal(t, "web", results[1].Service.Name)
+	assert.Equal(t, "check", results[2].Type)
+	assert.Equal(t, "passing", results[2].Check.Status)
+}
+
+func TestTransactMapsResultsToOperations(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		require.Equal(t, 6, len(operations))
+		return true, &consul.TxnResponse{Results: consul.TxnResults{
+			{KV: &consul.KVPair{Key: "app/a"}},
+			{KV: &consul.KVPair{Key: "app/b"}},
+			{KV: &consul.KVPair{Key: "app/a", ModifyIndex: 9}},
+			{Node: &consul.Node{Node: "node-2"}},
+		}}, nil, nil
+	}
+
+	operations := []TxnOperation{
+		{KV: &KVOperation{Verb: "delete", Key: "joe/mama"}},
+		{KV: &KVOperation{Verb: "get-tree", Key: "app/"}},
+		{Node: &NodeOperation{Verb: "delete", Node: "node-1"}},
+		{KV: &KVOperation{Verb: "set", Key: "app/a"}},
+		{KV: &KVOperation{Verb: "check-not-exists", Key: "app/c"}},
+		{Node: &NodeOperation{Verb: "get", Node: "node-2"}},
+	}
+	results, rollback, err := ConsulImpl.Transact(operations, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Nil(t, rollback)
+	require.Equal(t, 4, len(results))
+	assert.Equal(t, 1, results[0].Index)
+	assert.Equal(t, "app/a", results[0].KV.Key)
+	assert.Equal(t, 1, results[1].Index)
+	assert.Equal(t, "app/b", results[1].KV.Key)
+	assert.Equal(t, 3, results[2].Index)
+	assert.Equal(t, uint64(9), results[2].KV.ModifyIndex)
+	assert.Equal(t, 5, results[3].Index)
+	assert.Equal(t, "node", results[3].Type)
+}
+
+func TestTransactRolledBack(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		assert.Equal(t, consul.NodeDelete, operations[0].Node.Verb)
+		return false, &consul.TxnResponse{Errors: consul.TxnErrors{{OpIndex: 0, What: "node not found"}}}, nil, nil
+	}
+
+	results, rollback, err := ConsulImpl.Transact([]TxnOperation{{Node: &NodeOperation{Verb: "delete", Node: "node-1"}}}, WriteOptions{})
+
+	require.Nil(t, err)
+	assert.Nil(t, results)
+	assert.Equal(t, []TxnError{{Index: 0, Error: "node not found"}}, rollback)
+}
+
+func TestTransactFailed(t *testing.T) {
+	Before(t)
+	defer After()
+
+	ConsulMockClient.TxnFunc = func(operations consul.TxnOps, queryOptions *consul.QueryOptions) (bool, *consul.TxnResponse, *consul.QueryMeta, error) {
+		return false, nil, nil, errors.New("kablammo")
+	}
+
+	_, _, err := ConsulImpl.Transact([]TxnOperation{{KV: &KVOperation{Verb: "get", Key: "joe/mama"}}}, WriteOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "failed to make transaction request: kablammo", err.Error())
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	aclPolicyDeletedEventDef  = flyte.EventDef{Name: "ACLPolicyDeleted"}
+	aclPolicyNotFoundEventDef = flyte.EventDef{Name: "ACLPolicyNotFound"}
+)
+
+//ACLPolicyOutput represents the DeleteACLPolicy result payload.
+type ACLPolicyOutput struct {
+	Input ACLPolicyInput `json:"input"`
+}
+
+//DeleteACLPolicy produces the DeleteACLPolicy flyte command.
+func DeleteACLPolicy(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "DeleteACLPolicy",
+		OutputEvents: []flyte.EventDef{
+			aclPolicyDeletedEventDef,
+			aclPolicyNotFoundEventDef,
+		},
+		Handler: deleteACLPolicyHandler(consulClient, tokens),
+	}
+}
+
+func deleteACLPolicyHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLPolicyInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Name {
+			return flyte.NewFatalEvent("missing name")
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		ok, err := consulClient.DeleteACLPolicy(input.Name, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to delete acl policy: %v", err))
+		}
+
+		eventDef := aclPolicyDeletedEventDef
+		if !ok {
+			eventDef = aclPolicyNotFoundEventDef
+		}
+		return flyte.Event{
+			EventDef: eventDef,
+			Payload: ACLPolicyOutput{
+				Input: input,
+			},
+		}
+	}
+}
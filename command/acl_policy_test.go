@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLPolicyCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	upsertPolicy := UpsertACLPolicy(KVTransactionMockConsul, nil)
+	assert.Equal(t, "UpsertACLPolicy", upsertPolicy.Name)
+	require.Equal(t, 1, len(upsertPolicy.OutputEvents))
+	assert.Equal(t, "ACLPolicyApplied", upsertPolicy.OutputEvents[0].Name)
+
+	deletePolicy := DeleteACLPolicy(KVTransactionMockConsul, nil)
+	assert.Equal(t, "DeleteACLPolicy", deletePolicy.Name)
+	require.Equal(t, 2, len(deletePolicy.OutputEvents))
+	assert.Equal(t, "ACLPolicyDeleted", deletePolicy.OutputEvents[0].Name)
+	assert.Equal(t, "ACLPolicyNotFound", deletePolicy.OutputEvents[1].Name)
+
+	upsertRole := UpsertACLRole(KVTransactionMockConsul, nil)
+	assert.Equal(t, "UpsertACLRole", upsertRole.Name)
+	require.Equal(t, 1, len(upsertRole.OutputEvents))
+	assert.Equal(t, "ACLRoleApplied", upsertRole.OutputEvents[0].Name)
+
+	deleteRole := DeleteACLRole(KVTransactionMockConsul, nil)
+	assert.Equal(t, "DeleteACLRole", deleteRole.Name)
+	require.Equal(t, 2, len(deleteRole.OutputEvents))
+	assert.Equal(t, "ACLRoleDeleted", deleteRole.OutputEvents[0].Name)
+	assert.Equal(t, "ACLRoleNotFound", deleteRole.OutputEvents[1].Name)
+}
+
+func TestUpsertACLPolicyReturnsACLPolicyAppliedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.UpsertACLPolicyFunc = func(request client.ACLPolicyRequest, options client.WriteOptions) (*client.ACLPolicy, bool, error) {
+		assert.Equal(t, "read-only", request.Name)
+		assert.Equal(t, `key_prefix "" { policy = "read" }`, request.Rules)
+		assert.Equal(t, []string{"dc1"}, request.Datacenters)
+		assert.Equal(t, client.WriteOptions{Datacenter: "dc2", Token: "management-token"}, options)
+		return &client.ACLPolicy{ID: "policy-1", Name: request.Name}, false, nil
+	}
+
+	handler := UpsertACLPolicy(KVTransactionMockConsul, TokenRegistry{"admin": "management-token"}).Handler
+	event := handler([]byte(`{"dc": "dc2", "name": "read-only", "rules": "key_prefix \"\" { policy = \"read\" }", "datacenters": ["dc1"], "token": "admin"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLPolicyApplied", event.EventDef.Name)
+	output := event.Payload.(ACLPolicyAppliedOutput)
+	assert.Equal(t, "policy-1", output.Policy.ID)
+	assert.False(t, output.Changed)
+}
+
+func TestUpsertACLPolicyMissingName(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := UpsertACLPolicy(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"rules": ""}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing name", event.Payload)
+}
+
+func TestUpsertACLPolicyRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.UpsertACLPolicyFunc = func(request client.ACLPolicyRequest, options client.WriteOptions) (*client.ACLPolicy, bool, error) {
+		return nil, false, fmt.Errorf("rules are not valid: kablammo")
+	}
+
+	handler := UpsertACLPolicy(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "read-only", "rules": "key {"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to upsert acl policy: rules are not valid: kablammo", event.Payload)
+}
+
+func TestDeleteACLPolicyReturnsACLPolicyDeletedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLPolicyFunc = func(name string, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "read-only", name)
+		return true, nil
+	}
+
+	handler := DeleteACLPolicy(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "read-only"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLPolicyDeleted", event.EventDef.Name)
+	assert.Equal(t, "read-only", event.Payload.(ACLPolicyOutput).Input.Name)
+}
+
+func TestDeleteACLPolicyReturnsACLPolicyNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLPolicyFunc = func(name string, options client.WriteOptions) (bool, error) {
+		return false, nil
+	}
+
+	handler := DeleteACLPolicy(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "read-only"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLPolicyNotFound", event.EventDef.Name)
+}
+
+func TestUpsertACLRoleReturnsACLRoleAppliedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.UpsertACLRoleFunc = func(request client.ACLRoleRequest, options client.WriteOptions) (*client.ACLRole, bool, error) {
+		assert.Equal(t, "deployer", request.Name)
+		assert.Equal(t, []client.ACLLink{{Name: "read-only"}}, request.Policies)
+		assert.Equal(t, []client.ACLServiceIdentity{{ServiceName: "web"}}, request.ServiceIdentities)
+		return &client.ACLRole{ID: "role-1", Name: request.Name}, true, nil
+	}
+
+	handler := UpsertACLRole(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deployer", "policies": [{"name": "read-only"}], "serviceIdentities": [{"serviceName": "web"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLRoleApplied", event.EventDef.Name)
+	output := event.Payload.(ACLRoleAppliedOutput)
+	assert.Equal(t, "role-1", output.Role.ID)
+	assert.True(t, output.Changed)
+}
+
+func TestUpsertACLRoleInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := UpsertACLRole(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deployer", "nodeIdentities": [{"nodeName": "node-1"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "acl role is not valid: nodeIdentities[0] requires a nodeName and a datacenter", event.Payload)
+}
+
+func TestDeleteACLRoleReturnsACLRoleNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLRoleFunc = func(name string, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "deployer", name)
+		return false, nil
+	}
+
+	handler := DeleteACLRole(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deployer"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLRoleNotFound", event.EventDef.Name)
+}
+
+func TestDeleteACLRoleRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLRoleFunc = func(name string, options client.WriteOptions) (bool, error) {
+		return false, fmt.Errorf("kablammo")
+	}
+
+	handler := DeleteACLRole(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deployer"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to delete acl role: kablammo", event.Payload)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var aclPolicyAppliedEventDef = flyte.EventDef{Name: "ACLPolicyApplied"}
+
+//ACLPolicyInput represents the UpsertACLPolicy and DeleteACLPolicy commands payload.
+//DeleteACLPolicy only uses the name.
+type ACLPolicyInput struct {
+	Datacenter string `json:"dc"`
+	client.ACLPolicyRequest
+	RequestOptions
+}
+
+//ACLPolicyAppliedOutput represents the UpsertACLPolicy result payload.
+//Changed is false when the policy already matched the input.
+type ACLPolicyAppliedOutput struct {
+	Input   ACLPolicyInput    `json:"input"`
+	Policy  *client.ACLPolicy `json:"policy"`
+	Changed bool              `json:"changed"`
+}
+
+//UpsertACLPolicy produces the UpsertACLPolicy flyte command.
+func UpsertACLPolicy(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "UpsertACLPolicy",
+		OutputEvents: []flyte.EventDef{
+			aclPolicyAppliedEventDef,
+		},
+		Handler: upsertACLPolicyHandler(consulClient, tokens),
+	}
+}
+
+func upsertACLPolicyHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLPolicyInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Name {
+			return flyte.NewFatalEvent("missing name")
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		policy, changed, err := consulClient.UpsertACLPolicy(input.ACLPolicyRequest, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to upsert acl policy: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: aclPolicyAppliedEventDef,
+			Payload: ACLPolicyAppliedOutput{
+				Input:   input,
+				Policy:  policy,
+				Changed: changed,
+			},
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	aclRoleDeletedEventDef  = flyte.EventDef{Name: "ACLRoleDeleted"}
+	aclRoleNotFoundEventDef = flyte.EventDef{Name: "ACLRoleNotFound"}
+)
+
+//ACLRoleOutput represents the DeleteACLRole result payload.
+type ACLRoleOutput struct {
+	Input ACLRoleInput `json:"input"`
+}
+
+//DeleteACLRole produces the DeleteACLRole flyte command.
+func DeleteACLRole(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "DeleteACLRole",
+		OutputEvents: []flyte.EventDef{
+			aclRoleDeletedEventDef,
+			aclRoleNotFoundEventDef,
+		},
+		Handler: deleteACLRoleHandler(consulClient, tokens),
+	}
+}
+
+func deleteACLRoleHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLRoleInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Name {
+			return flyte.NewFatalEvent("missing name")
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		ok, err := consulClient.DeleteACLRole(input.Name, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to delete acl role: %v", err))
+		}
+
+		eventDef := aclRoleDeletedEventDef
+		if !ok {
+			eventDef = aclRoleNotFoundEventDef
+		}
+		return flyte.Event{
+			EventDef: eventDef,
+			Payload: ACLRoleOutput{
+				Input: input,
+			},
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var aclRoleAppliedEventDef = flyte.EventDef{Name: "ACLRoleApplied"}
+
+//ACLRoleInput represents the UpsertACLRole and DeleteACLRole commands payload.
+//DeleteACLRole only uses the name.
+type ACLRoleInput struct {
+	Datacenter string `json:"dc"`
+	client.ACLRoleRequest
+	RequestOptions
+}
+
+//ACLRoleAppliedOutput represents the UpsertACLRole result payload.
+//Changed is false when the role already matched the input.
+type ACLRoleAppliedOutput struct {
+	Input   ACLRoleInput    `json:"input"`
+	Role    *client.ACLRole `json:"role"`
+	Changed bool            `json:"changed"`
+}
+
+//UpsertACLRole produces the UpsertACLRole flyte command.
+func UpsertACLRole(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "UpsertACLRole",
+		OutputEvents: []flyte.EventDef{
+			aclRoleAppliedEventDef,
+		},
+		Handler: upsertACLRoleHandler(consulClient, tokens),
+	}
+}
+
+func upsertACLRoleHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLRoleInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Name {
+			return flyte.NewFatalEvent("missing name")
+		}
+		if errors := input.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("acl role is not valid: %v", strings.Join(errors, ", ")))
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		role, changed, err := consulClient.UpsertACLRole(input.ACLRoleRequest, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to upsert acl role: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: aclRoleAppliedEventDef,
+			Payload: ACLRoleAppliedOutput{
+				Input:   input,
+				Role:    role,
+				Changed: changed,
+			},
+		}
+	}
+}
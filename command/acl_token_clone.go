@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var aclTokenClonedEventDef = flyte.EventDef{Name: "ACLTokenCloned"}
+
+//CloneACLToken produces the CloneACLToken flyte command.
+func CloneACLToken(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "CloneACLToken",
+		OutputEvents: []flyte.EventDef{
+			aclTokenClonedEventDef,
+			aclTokenNotFoundEventDef,
+		},
+		Handler: cloneACLTokenHandler(consulClient, tokens),
+	}
+}
+
+func cloneACLTokenHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLTokenInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.AccessorID {
+			return flyte.NewFatalEvent("missing accessorId")
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		description := ""
+		if nil != input.Description {
+			description = *input.Description
+		}
+		token, err := consulClient.CloneACLToken(input.AccessorID, description, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to clone acl token: %v", err))
+		}
+		if nil == token {
+			return newACLTokenNotFoundEvent(input)
+		}
+
+		return newACLTokenEvent(aclTokenClonedEventDef, input, token)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var aclTokenCreatedEventDef = flyte.EventDef{Name: "ACLTokenCreated"}
+
+//CreateACLToken produces the CreateACLToken flyte command.
+func CreateACLToken(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "CreateACLToken",
+		OutputEvents: []flyte.EventDef{
+			aclTokenCreatedEventDef,
+		},
+		Handler: createACLTokenHandler(consulClient, tokens),
+	}
+}
+
+func createACLTokenHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLTokenInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if errors := input.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("acl token is not valid: %v", strings.Join(errors, ", ")))
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		token, err := consulClient.CreateACLToken(input.ACLTokenRequest, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to create acl token: %v", err))
+		}
+
+		return newACLTokenEvent(aclTokenCreatedEventDef, input, token)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+const redactedSecretID = "[REDACTED]"
+
+var (
+	aclTokenFoundEventDef    = flyte.EventDef{Name: "ACLTokenFound"}
+	aclTokenNotFoundEventDef = flyte.EventDef{Name: "ACLTokenNotFound"}
+)
+
+//ACLTokenInput represents the ACL token commands payload.
+//AccessorID is required by every command but CreateACLToken, the token fields are used by
+//CreateACLToken and UpdateACLToken, and CloneACLToken only uses the description.
+//The secret ID of the token is redacted from the events unless ShowSecret is set.
+type ACLTokenInput struct {
+	Datacenter string `json:"dc"`
+	AccessorID string `json:"accessorId,omitempty"`
+	client.ACLTokenRequest
+	ShowSecret bool `json:"showSecret,omitempty"`
+	RequestOptions
+}
+
+//ACLTokenOutput represents the ACL token commands result payload.
+type ACLTokenOutput struct {
+	Input ACLTokenInput    `json:"input"`
+	Token *client.ACLToken `json:"token,omitempty"`
+}
+
+//ReadACLToken produces the ReadACLToken flyte command.
+func ReadACLToken(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "ReadACLToken",
+		OutputEvents: []flyte.EventDef{
+			aclTokenFoundEventDef,
+			aclTokenNotFoundEventDef,
+		},
+		Handler: readACLTokenHandler(consulClient, tokens),
+	}
+}
+
+func readACLTokenHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLTokenInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.AccessorID {
+			return flyte.NewFatalEvent("missing accessorId")
+		}
+		options, err := input.queryOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		token, err := consulClient.ReadACLToken(input.AccessorID, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to read acl token: %v", err))
+		}
+		if nil == token {
+			return newACLTokenNotFoundEvent(input)
+		}
+
+		return newACLTokenEvent(aclTokenFoundEventDef, input, token)
+	}
+}
+
+func newACLTokenEvent(eventDef flyte.EventDef, input ACLTokenInput, token *client.ACLToken) flyte.Event {
+	if !input.ShowSecret && "" != token.SecretID {
+		token.SecretID = redactedSecretID
+	}
+	return flyte.Event{
+		EventDef: eventDef,
+		Payload: ACLTokenOutput{
+			Input: input,
+			Token: token,
+		},
+	}
+}
+
+func newACLTokenNotFoundEvent(input ACLTokenInput) flyte.Event {
+	return flyte.Event{
+		EventDef: aclTokenNotFoundEventDef,
+		Payload: ACLTokenOutput{
+			Input: input,
+		},
+	}
+}
@@ -0,0 +1,329 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLTokenCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	create := CreateACLToken(KVTransactionMockConsul, nil)
+	assert.Equal(t, "CreateACLToken", create.Name)
+	require.Equal(t, 1, len(create.OutputEvents))
+	assert.Equal(t, "ACLTokenCreated", create.OutputEvents[0].Name)
+
+	read := ReadACLToken(KVTransactionMockConsul, nil)
+	assert.Equal(t, "ReadACLToken", read.Name)
+	require.Equal(t, 2, len(read.OutputEvents))
+	assert.Equal(t, "ACLTokenFound", read.OutputEvents[0].Name)
+	assert.Equal(t, "ACLTokenNotFound", read.OutputEvents[1].Name)
+
+	update := UpdateACLToken(KVTransactionMockConsul, nil)
+	assert.Equal(t, "UpdateACLToken", update.Name)
+	require.Equal(t, 2, len(update.OutputEvents))
+	assert.Equal(t, "ACLTokenUpdated", update.OutputEvents[0].Name)
+	assert.Equal(t, "ACLTokenNotFound", update.OutputEvents[1].Name)
+
+	del := DeleteACLToken(KVTransactionMockConsul, nil)
+	assert.Equal(t, "DeleteACLToken", del.Name)
+	require.Equal(t, 2, len(del.OutputEvents))
+	assert.Equal(t, "ACLTokenDeleted", del.OutputEvents[0].Name)
+	assert.Equal(t, "ACLTokenNotFound", del.OutputEvents[1].Name)
+
+	clone := CloneACLToken(KVTransactionMockConsul, nil)
+	assert.Equal(t, "CloneACLToken", clone.Name)
+	require.Equal(t, 2, len(clone.OutputEvents))
+	assert.Equal(t, "ACLTokenCloned", clone.OutputEvents[0].Name)
+	assert.Equal(t, "ACLTokenNotFound", clone.OutputEvents[1].Name)
+}
+
+func TestCreateACLTokenReturnsACLTokenCreatedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CreateACLTokenFunc = func(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+		assert.Equal(t, "deploy", *request.Description)
+		assert.Equal(t, []client.ACLLink{{Name: "read-only"}}, request.Policies)
+		assert.Equal(t, "24h", request.ExpirationTTL)
+		assert.Equal(t, client.WriteOptions{Datacenter: "dc2", Token: "management-token"}, options)
+		return getACLToken(), nil
+	}
+
+	handler := CreateACLToken(KVTransactionMockConsul, TokenRegistry{"admin": "management-token"}).Handler
+	event := handler([]byte(`{"dc": "dc2", "description": "deploy", "policies": [{"name": "read-only"}], "expirationTTL": "24h", "token": "admin"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenCreated", event.EventDef.Name)
+	output := event.Payload.(ACLTokenOutput)
+	assert.Equal(t, "accessor", output.Token.AccessorID)
+	assert.Equal(t, "[REDACTED]", output.Token.SecretID)
+
+	payload, err := json.Marshal(event.Payload)
+	require.Nil(t, err)
+	assert.NotContains(t, string(payload), "secret-id")
+	assert.NotContains(t, string(payload), "management-token")
+}
+
+func TestCreateACLTokenShowsSecret(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CreateACLTokenFunc = func(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+		return getACLToken(), nil
+	}
+
+	handler := CreateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"description": "deploy", "showSecret": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenCreated", event.EventDef.Name)
+	assert.Equal(t, "secret-id", event.Payload.(ACLTokenOutput).Token.SecretID)
+}
+
+func TestCreateACLTokenInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := CreateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"expirationTTL": "tomorrow", "roles": [{}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "acl token is not valid: expirationTTL tomorrow is not a valid duration, roles[0] requires an id or a name", event.Payload)
+}
+
+func TestCreateACLTokenUnregisteredToken(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := CreateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"token": "admin"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "token admin is not registered", event.Payload)
+}
+
+func TestCreateACLTokenRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CreateACLTokenFunc = func(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := CreateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to create acl token: kablammo", event.Payload)
+}
+
+func TestReadACLTokenReturnsACLTokenFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReadACLTokenFunc = func(accessorID string, options client.QueryOptions) (*client.ACLToken, error) {
+		assert.Equal(t, "accessor", accessorID)
+		assert.Equal(t, client.QueryOptions{Datacenter: "dc2", Namespace: "team"}, options)
+		return getACLToken(), nil
+	}
+
+	handler := ReadACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"dc": "dc2", "accessorId": "accessor", "namespace": "team"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenFound", event.EventDef.Name)
+	output := event.Payload.(ACLTokenOutput)
+	assert.Equal(t, "deploy", output.Token.Description)
+	assert.Equal(t, "[REDACTED]", output.Token.SecretID)
+}
+
+func TestReadACLTokenReturnsACLTokenNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReadACLTokenFunc = func(accessorID string, options client.QueryOptions) (*client.ACLToken, error) {
+		return nil, nil
+	}
+
+	handler := ReadACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenNotFound", event.EventDef.Name)
+	assert.Nil(t, event.Payload.(ACLTokenOutput).Token)
+}
+
+func TestReadACLTokenMissingAccessorID(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := ReadACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing accessorId", event.Payload)
+}
+
+func TestUpdateACLTokenReturnsACLTokenUpdatedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.UpdateACLTokenFunc = func(accessorID string, request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+		assert.Equal(t, "accessor", accessorID)
+		assert.Equal(t, []client.ACLLink{{ID: "role-1"}}, request.Roles)
+		return getACLToken(), nil
+	}
+
+	handler := UpdateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor", "roles": [{"id": "role-1"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenUpdated", event.EventDef.Name)
+	assert.Equal(t, "[REDACTED]", event.Payload.(ACLTokenOutput).Token.SecretID)
+}
+
+func TestUpdateACLTokenReturnsACLTokenNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.UpdateACLTokenFunc = func(accessorID string, request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+		return nil, nil
+	}
+
+	handler := UpdateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenNotFound", event.EventDef.Name)
+}
+
+func TestUpdateACLTokenMissingAccessorID(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := UpdateACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"description": "deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing accessorId", event.Payload)
+}
+
+func TestDeleteACLTokenReturnsACLTokenDeletedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLTokenFunc = func(accessorID string, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "accessor", accessorID)
+		return true, nil
+	}
+
+	handler := DeleteACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenDeleted", event.EventDef.Name)
+	assert.Equal(t, "accessor", event.Payload.(ACLTokenOutput).Input.AccessorID)
+}
+
+func TestDeleteACLTokenReturnsACLTokenNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLTokenFunc = func(accessorID string, options client.WriteOptions) (bool, error) {
+		return false, nil
+	}
+
+	handler := DeleteACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenNotFound", event.EventDef.Name)
+}
+
+func TestDeleteACLTokenRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteACLTokenFunc = func(accessorID string, options client.WriteOptions) (bool, error) {
+		return false, fmt.Errorf("kablammo")
+	}
+
+	handler := DeleteACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to delete acl token: kablammo", event.Payload)
+}
+
+func TestCloneACLTokenReturnsACLTokenClonedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CloneACLTokenFunc = func(accessorID, description string, options client.WriteOptions) (*client.ACLToken, error) {
+		assert.Equal(t, "accessor", accessorID)
+		assert.Equal(t, "copy", description)
+		token := getACLToken()
+		token.AccessorID = "clone"
+		return token, nil
+	}
+
+	handler := CloneACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor", "description": "copy", "showSecret": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenCloned", event.EventDef.Name)
+	output := event.Payload.(ACLTokenOutput)
+	assert.Equal(t, "clone", output.Token.AccessorID)
+	assert.Equal(t, "secret-id", output.Token.SecretID)
+}
+
+func TestCloneACLTokenReturnsACLTokenNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CloneACLTokenFunc = func(accessorID, description string, options client.WriteOptions) (*client.ACLToken, error) {
+		return nil, nil
+	}
+
+	handler := CloneACLToken(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"accessorId": "accessor"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ACLTokenNotFound", event.EventDef.Name)
+}
+
+func getACLToken() *client.ACLToken {
+	return &client.ACLToken{
+		AccessorID:  "accessor",
+		SecretID:    "secret-id",
+		Description: "deploy",
+		Policies:    []client.ACLLink{{ID: "policy-1", Name: "read-only"}},
+		Roles:       []client.ACLLink{},
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var aclTokenUpdatedEventDef = flyte.EventDef{Name: "ACLTokenUpdated"}
+
+//UpdateACLToken produces the UpdateACLToken flyte command.
+func UpdateACLToken(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "UpdateACLToken",
+		OutputEvents: []flyte.EventDef{
+			aclTokenUpdatedEventDef,
+			aclTokenNotFoundEventDef,
+		},
+		Handler: updateACLTokenHandler(consulClient, tokens),
+	}
+}
+
+func updateACLTokenHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ACLTokenInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.AccessorID {
+			return flyte.NewFatalEvent("missing accessorId")
+		}
+		if errors := input.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("acl token is not valid: %v", strings.Join(errors, ", ")))
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		token, err := consulClient.UpdateACLToken(input.AccessorID, input.ACLTokenRequest, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to update acl token: %v", err))
+		}
+		if nil == token {
+			return newACLTokenNotFoundEvent(input)
+		}
+
+		return newACLTokenEvent(aclTokenUpdatedEventDef, input, token)
+	}
+}
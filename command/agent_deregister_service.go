@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	serviceDeregisteredEventDef  = flyte.EventDef{Name: "ServiceDeregistered"}
+	deregistrationFailedEventDef = flyte.EventDef{Name: "DeregistrationFailed"}
+)
+
+//DeregisterServiceInput represents the DeregisterService command payload.
+type DeregisterServiceInput struct {
+	ServiceID string `json:"serviceId"`
+}
+
+//DeregisterServiceOutput represents the DeregisterService result payload.
+type DeregisterServiceOutput struct {
+	Input DeregisterServiceInput `json:"input"`
+}
+
+//DeregisterServiceErrorOutput represents the DeregisterService error payload.
+type DeregisterServiceErrorOutput struct {
+	Input  DeregisterServiceInput `json:"input"`
+	Errors []string               `json:"errors"`
+}
+
+//DeregisterService produces the DeregisterService flyte command.
+func DeregisterService(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "DeregisterService",
+		OutputEvents: []flyte.EventDef{
+			serviceDeregisteredEventDef,
+			deregistrationFailedEventDef,
+		},
+		Handler: deregisterServiceHandler(consulClient),
+	}
+}
+
+func deregisterServiceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := DeregisterServiceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.ServiceID {
+			return newDeregisterServiceFailedEvent(input, []string{"service id is missing"})
+		}
+
+		if err := consulClient.DeregisterService(input.ServiceID); nil != err {
+			return newDeregisterServiceFailedEvent(input, []string{err.Error()})
+		}
+
+		return flyte.Event{
+			EventDef: serviceDeregisteredEventDef,
+			Payload: DeregisterServiceOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func newDeregisterServiceFailedEvent(input DeregisterServiceInput, errors []string) flyte.Event {
+	return flyte.Event{
+		EventDef: deregistrationFailedEventDef,
+		Payload: DeregisterServiceErrorOutput{
+			Input:  input,
+			Errors: errors,
+		},
+	}
+}
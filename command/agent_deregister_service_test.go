@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeregisterServiceCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := DeregisterService(KVTransactionMockConsul)
+
+	assert.Equal(t, "DeregisterService", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "ServiceDeregistered", command.OutputEvents[0].Name)
+	assert.Equal(t, "DeregistrationFailed", command.OutputEvents[1].Name)
+}
+
+func TestDeregisterServiceReturnsServiceDeregisteredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeregisterServiceFunc = func(serviceID string) error {
+		assert.Equal(t, "db-1", serviceID)
+		return nil
+	}
+
+	handler := DeregisterService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"serviceId": "db-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ServiceDeregistered", event.EventDef.Name)
+	assert.Equal(t, "db-1", event.Payload.(DeregisterServiceOutput).Input.ServiceID)
+}
+
+func TestDeregisterServiceFailsMissingServiceID(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := DeregisterService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "DeregistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"service id is missing"}, event.Payload.(DeregisterServiceErrorOutput).Errors)
+}
+
+func TestDeregisterServiceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeregisterServiceFunc = func(serviceID string) error {
+		return fmt.Errorf("kablammo")
+	}
+
+	handler := DeregisterService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"serviceId": "db-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "DeregistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"kablammo"}, event.Payload.(DeregisterServiceErrorOutput).Errors)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	serviceRegisteredEventDef  = flyte.EventDef{Name: "ServiceRegistered"}
+	registrationFailedEventDef = flyte.EventDef{Name: "RegistrationFailed"}
+)
+
+//RegisterServiceInput represents the RegisterService command payload.
+type RegisterServiceInput struct {
+	Service client.ServiceRegistration `json:"service"`
+}
+
+//RegisterServiceOutput represents the RegisterService result payload.
+type RegisterServiceOutput struct {
+	Input RegisterServiceInput `json:"input"`
+}
+
+//RegisterServiceErrorOutput represents the RegisterService error payload.
+type RegisterServiceErrorOutput struct {
+	Input  RegisterServiceInput `json:"input"`
+	Errors []string             `json:"errors"`
+}
+
+//RegisterService produces the RegisterService flyte command.
+func RegisterService(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "RegisterService",
+		OutputEvents: []flyte.EventDef{
+			serviceRegisteredEventDef,
+			registrationFailedEventDef,
+		},
+		Handler: registerServiceHandler(consulClient),
+	}
+}
+
+func registerServiceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := RegisterServiceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if errors := input.Service.Validate(); 0 != len(errors) {
+			return newRegisterServiceFailedEvent(input, errors)
+		}
+
+		if err := consulClient.RegisterService(input.Service); nil != err {
+			return newRegisterServiceFailedEvent(input, []string{err.Error()})
+		}
+
+		return flyte.Event{
+			EventDef: serviceRegisteredEventDef,
+			Payload: RegisterServiceOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func newRegisterServiceFailedEvent(input RegisterServiceInput, errors []string) flyte.Event {
+	return flyte.Event{
+		EventDef: registrationFailedEventDef,
+		Payload: RegisterServiceErrorOutput{
+			Input:  input,
+			Errors: errors,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterServiceCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := RegisterService(KVTransactionMockConsul)
+
+	assert.Equal(t, "RegisterService", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "ServiceRegistered", command.OutputEvents[0].Name)
+	assert.Equal(t, "RegistrationFailed", command.OutputEvents[1].Name)
+}
+
+func TestRegisterServiceReturnsServiceRegisteredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RegisterServiceFunc = func(service client.ServiceRegistration) error {
+		assert.Equal(t, "db", service.Name)
+		assert.Equal(t, 5432, service.Port)
+		require.Equal(t, 1, len(service.Checks))
+		assert.Equal(t, "db.example.com:5432", service.Checks[0].TCP)
+		return nil
+	}
+
+	handler := RegisterService(KVTransactionMockConsul).Handler
+	event := handler(getValidRegisterServicePayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ServiceRegistered", event.EventDef.Name)
+	assert.Equal(t, "db", event.Payload.(RegisterServiceOutput).Input.Service.Name)
+}
+
+func TestRegisterServiceFailsValidation(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := RegisterService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{
+		"service": {
+			"port": -1,
+			"checks": [{"http": "http://db.example.com/health"}]
+		}
+	}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "RegistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{
+		"name is missing",
+		"port -1 is out of range",
+		"check 0: interval is missing",
+	}, event.Payload.(RegisterServiceErrorOutput).Errors)
+}
+
+func TestRegisterServiceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RegisterServiceFunc = func(service client.ServiceRegistration) error {
+		return fmt.Errorf("kablammo")
+	}
+
+	handler := RegisterService(KVTransactionMockConsul).Handler
+	event := handler(getValidRegisterServicePayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "RegistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"kablammo"}, event.Payload.(RegisterServiceErrorOutput).Errors)
+}
+
+func TestRegisterServiceFailsInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := RegisterService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": []}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+}
+
+func getValidRegisterServicePayload() []byte {
+	return []byte(`{
+		"service": {
+			"id": "db-1",
+			"name": "db",
+			"address": "db.example.com",
+			"port": 5432,
+			"tags": ["external"],
+			"meta": {"owner": "team"},
+			"checks": [
+				{
+					"checkId": "db-tcp",
+					"tcp": "db.example.com:5432",
+					"interval": "10s"
+				}
+			]
+		}
+	}`)
+}
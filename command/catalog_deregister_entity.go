@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var catalogEntityDeregisteredEventDef = flyte.EventDef{Name: "CatalogEntityDeregistered"}
+
+//DeregisterCatalogEntityInput represents the DeregisterCatalogEntity command payload.
+//The whole node is deregistered when ServiceID is empty.
+type DeregisterCatalogEntityInput struct {
+	Datacenter string `json:"dc"`
+	Node       string `json:"node"`
+	ServiceID  string `json:"serviceId"`
+}
+
+//DeregisterCatalogEntityOutput represents the DeregisterCatalogEntity result payload.
+type DeregisterCatalogEntityOutput struct {
+	Input DeregisterCatalogEntityInput `json:"input"`
+}
+
+//DeregisterCatalogEntityErrorOutput represents the DeregisterCatalogEntity error payload.
+type DeregisterCatalogEntityErrorOutput struct {
+	Input  DeregisterCatalogEntityInput `json:"input"`
+	Errors []string                     `json:"errors"`
+}
+
+//DeregisterCatalogEntity produces the DeregisterCatalogEntity flyte command.
+func DeregisterCatalogEntity(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "DeregisterCatalogEntity",
+		OutputEvents: []flyte.EventDef{
+			catalogEntityDeregisteredEventDef,
+			deregistrationFailedEventDef,
+		},
+		Handler: deregisterCatalogEntityHandler(consulClient),
+	}
+}
+
+func deregisterCatalogEntityHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := DeregisterCatalogEntityInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Node {
+			return newDeregisterCatalogEntityFailedEvent(input, []string{"node is missing"})
+		}
+
+		err := consulClient.DeregisterCatalogEntity(input.Node, input.ServiceID, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return newDeregisterCatalogEntityFailedEvent(input, []string{err.Error()})
+		}
+
+		return flyte.Event{
+			EventDef: catalogEntityDeregisteredEventDef,
+			Payload: DeregisterCatalogEntityOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func newDeregisterCatalogEntityFailedEvent(input DeregisterCatalogEntityInput, errors []string) flyte.Event {
+	return flyte.Event{
+		EventDef: deregistrationFailedEventDef,
+		Payload: DeregisterCatalogEntityErrorOutput{
+			Input:  input,
+			Errors: errors,
+		},
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeregisterCatalogEntityCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := DeregisterCatalogEntity(KVTransactionMockConsul)
+
+	assert.Equal(t, "DeregisterCatalogEntity", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "CatalogEntityDeregistered", command.OutputEvents[0].Name)
+	assert.Equal(t, "DeregistrationFailed", command.OutputEvents[1].Name)
+}
+
+func TestDeregisterCatalogEntityReturnsCatalogEntityDeregisteredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeregisterCatalogEntityFunc = func(node, serviceID string, options client.WriteOptions) error {
+		assert.Equal(t, "external", node)
+		assert.Equal(t, "saas", serviceID)
+		assert.Equal(t, "dc", options.Datacenter)
+		return nil
+	}
+
+	handler := DeregisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc", "node": "external", "serviceId": "saas"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "CatalogEntityDeregistered", event.EventDef.Name)
+}
+
+func TestDeregisterCatalogEntityFailsMissingNode(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := DeregisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"serviceId": "saas"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "DeregistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"node is missing"}, event.Payload.(DeregisterCatalogEntityErrorOutput).Errors)
+}
+
+func TestDeregisterCatalogEntityRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeregisterCatalogEntityFunc = func(node, serviceID string, options client.WriteOptions) error {
+		return fmt.Errorf("kablammo")
+	}
+
+	handler := DeregisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": "external"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "DeregistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"kablammo"}, event.Payload.(DeregisterCatalogEntityErrorOutput).Errors)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	serviceFoundEventDef    = flyte.EventDef{Name: "ServiceFound"}
+	serviceNotFoundEventDef = flyte.EventDef{Name: "ServiceNotFound"}
+)
+
+//GetServiceInput represents the GetService command payload.
+type GetServiceInput struct {
+	Datacenter  string   `json:"dc"`
+	Service     string   `json:"service"`
+	Tags        []string `json:"tags"`
+	Near        string   `json:"near"`
+	Filter      string   `json:"filter"`
+	Consistency string   `json:"consistency"`
+}
+
+//GetServiceOutput represents the GetService result payload.
+type GetServiceOutput struct {
+	Input     GetServiceInput          `json:"input"`
+	Instances []client.ServiceInstance `json:"instances,omitempty"`
+}
+
+//GetService produces the GetService flyte command.
+func GetService(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "GetService",
+		OutputEvents: []flyte.EventDef{
+			serviceFoundEventDef,
+			serviceNotFoundEventDef,
+		},
+		Handler: getServiceHandler(consulClient),
+	}
+}
+
+func getServiceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := GetServiceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Service {
+			return flyte.NewFatalEvent("missing service")
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+
+		instances, err := consulClient.GetService(input.Service, input.Tags, client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+			Near:        input.Near,
+			Filter:      input.Filter,
+		})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to get service: %v", err))
+		}
+		if 0 == len(instances) {
+			return flyte.Event{
+				EventDef: serviceNotFoundEventDef,
+				Payload: GetServiceOutput{
+					Input: input,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: serviceFoundEventDef,
+			Payload: GetServiceOutput{
+				Input:     input,
+				Instances: instances,
+			},
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServiceCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := GetService(KVTransactionMockConsul)
+
+	assert.Equal(t, "GetService", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "ServiceFound", command.OutputEvents[0].Name)
+	assert.Equal(t, "ServiceNotFound", command.OutputEvents[1].Name)
+}
+
+func TestGetServiceReturnsServiceFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetServiceFunc = func(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error) {
+		assert.Equal(t, "web", name)
+		assert.Equal(t, []string{"prod", "v1"}, tags)
+		assert.Equal(t, "dc", options.Datacenter)
+		assert.Equal(t, "_agent", options.Near)
+		assert.Equal(t, "ServiceMeta.version == \"1\"", options.Filter)
+		return []client.ServiceInstance{{ID: "web-1", Address: "10.0.0.1", Port: 8080}}, nil
+	}
+
+	handler := GetService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{
+		"dc": "dc",
+		"service": "web",
+		"tags": ["prod", "v1"],
+		"near": "_agent",
+		"filter": "ServiceMeta.version == \"1\""
+	}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ServiceFound", event.EventDef.Name)
+	output := event.Payload.(GetServiceOutput)
+	require.Equal(t, 1, len(output.Instances))
+	assert.Equal(t, "web-1", output.Instances[0].ID)
+	assert.Equal(t, 8080, output.Instances[0].Port)
+}
+
+func TestGetServiceReturnsServiceNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetServiceFunc = func(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error) {
+		return []client.ServiceInstance{}, nil
+	}
+
+	handler := GetService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": "web"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ServiceNotFound", event.EventDef.Name)
+	assert.Equal(t, "web", event.Payload.(GetServiceOutput).Input.Service)
+}
+
+func TestGetServiceFailsInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`asdk292ds{}][;dsfjIljskdf{}[`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.True(t, strings.HasPrefix(event.Payload.(string), "input is not valid"))
+}
+
+func TestGetServiceFailsMissingService(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing service", event.Payload)
+}
+
+func TestGetServiceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetServiceFunc = func(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := GetService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": "web"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to get service: kablammo", event.Payload)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var nodesListedEventDef = flyte.EventDef{Name: "NodesListed"}
+
+//ListNodesInput represents the ListNodes command payload.
+type ListNodesInput struct {
+	Datacenter  string `json:"dc"`
+	Near        string `json:"near"`
+	Filter      string `json:"filter"`
+	Consistency string `json:"consistency"`
+}
+
+//ListNodesOutput represents the ListNodes result payload.
+type ListNodesOutput struct {
+	Input ListNodesInput `json:"input"`
+	Nodes []client.Node  `json:"nodes"`
+}
+
+//ListNodes produces the ListNodes flyte command.
+func ListNodes(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "ListNodes",
+		OutputEvents: []flyte.EventDef{
+			nodesListedEventDef,
+		},
+		Handler: listNodesHandler(consulClient),
+	}
+}
+
+func listNodesHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ListNodesInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+
+		nodes, err := consulClient.ListNodes(client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+			Near:        input.Near,
+			Filter:      input.Filter,
+		})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to list nodes: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: nodesListedEventDef,
+			Payload: ListNodesOutput{
+				Input: input,
+				Nodes: nodes,
+			},
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListNodesCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := ListNodes(KVTransactionMockConsul)
+
+	assert.Equal(t, "ListNodes", command.Name)
+	require.Equal(t, 1, len(command.OutputEvents))
+	assert.Equal(t, "NodesListed", command.OutputEvents[0].Name)
+}
+
+func TestListNodesReturnsNodesListedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListNodesFunc = func(options client.QueryOptions) ([]client.Node, error) {
+		assert.Equal(t, "dc", options.Datacenter)
+		assert.Equal(t, "_agent", options.Near)
+		return []client.Node{{Node: "node-1", Address: "10.0.0.1"}}, nil
+	}
+
+	handler := ListNodes(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc", "near": "_agent"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "NodesListed", event.EventDef.Name)
+	output := event.Payload.(ListNodesOutput)
+	require.Equal(t, 1, len(output.Nodes))
+	assert.Equal(t, "node-1", output.Nodes[0].Node)
+}
+
+func TestListNodesRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListNodesFunc = func(options client.QueryOptions) ([]client.Node, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := ListNodes(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to list nodes: kablammo", event.Payload)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var servicesListedEventDef = flyte.EventDef{Name: "ServicesListed"}
+
+//ListServicesInput represents the ListServices command payload.
+type ListServicesInput struct {
+	Datacenter  string `json:"dc"`
+	Filter      string `json:"filter"`
+	Consistency string `json:"consistency"`
+}
+
+//ListServicesOutput represents the ListServices result payload.
+type ListServicesOutput struct {
+	Input    ListServicesInput   `json:"input"`
+	Services map[string][]string `json:"services"`
+}
+
+//ListServices produces the ListServices flyte command.
+func ListServices(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "ListServices",
+		OutputEvents: []flyte.EventDef{
+			servicesListedEventDef,
+		},
+		Handler: listServicesHandler(consulClient),
+	}
+}
+
+func listServicesHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ListServicesInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+
+		services, err := consulClient.ListServices(client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+			Filter:      input.Filter,
+		})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to list services: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: servicesListedEventDef,
+			Payload: ListServicesOutput{
+				Input:    input,
+				Services: services,
+			},
+		}
+	}
+}
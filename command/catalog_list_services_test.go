@@ -0,0 +1,80 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListServicesCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := ListServices(KVTransactionMockConsul)
+
+	assert.Equal(t, "ListServices", command.Name)
+	require.Equal(t, 1, len(command.OutputEvents))
+	assert.Equal(t, "ServicesListed", command.OutputEvents[0].Name)
+}
+
+func TestListServicesReturnsServicesListedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListServicesFunc = func(options client.QueryOptions) (map[string][]string, error) {
+		assert.Equal(t, "dc", options.Datacenter)
+		assert.Equal(t, "ServiceTags contains \"prod\"", options.Filter)
+		return map[string][]string{"web": {"prod"}}, nil
+	}
+
+	handler := ListServices(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc", "filter": "ServiceTags contains \"prod\""}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ServicesListed", event.EventDef.Name)
+	assert.Equal(t, map[string][]string{"web": {"prod"}}, event.Payload.(ListServicesOutput).Services)
+}
+
+func TestListServicesFailsInvalidConsistency(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := ListServices(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"consistency": "eventual"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "eventual consistency mode is not valid", event.Payload)
+}
+
+func TestListServicesRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListServicesFunc = func(options client.QueryOptions) (map[string][]string, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := ListServices(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to list services: kablammo", event.Payload)
+}
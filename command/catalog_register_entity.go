@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var catalogEntityRegisteredEventDef = flyte.EventDef{Name: "CatalogEntityRegistered"}
+
+//RegisterCatalogEntityInput represents the RegisterCatalogEntity command payload.
+type RegisterCatalogEntityInput struct {
+	Datacenter string                      `json:"dc"`
+	Node       string                      `json:"node"`
+	Address    string                      `json:"address"`
+	NodeMeta   map[string]string           `json:"nodeMeta"`
+	Service    *client.ServiceRegistration `json:"service,omitempty"`
+}
+
+//RegisterCatalogEntityOutput represents the RegisterCatalogEntity result payload.
+type RegisterCatalogEntityOutput struct {
+	Input RegisterCatalogEntityInput `json:"input"`
+}
+
+//RegisterCatalogEntityErrorOutput represents the RegisterCatalogEntity error payload.
+type RegisterCatalogEntityErrorOutput struct {
+	Input  RegisterCatalogEntityInput `json:"input"`
+	Errors []string                   `json:"errors"`
+}
+
+//RegisterCatalogEntity produces the RegisterCatalogEntity flyte command.
+func RegisterCatalogEntity(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "RegisterCatalogEntity",
+		OutputEvents: []flyte.EventDef{
+			catalogEntityRegisteredEventDef,
+			registrationFailedEventDef,
+		},
+		Handler: registerCatalogEntityHandler(consulClient),
+	}
+}
+
+func registerCatalogEntityHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := RegisterCatalogEntityInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+
+		entity := client.CatalogEntity{
+			Node:     input.Node,
+			Address:  input.Address,
+			NodeMeta: input.NodeMeta,
+			Service:  input.Service,
+		}
+		if errors := entity.Validate(); 0 != len(errors) {
+			return newRegisterCatalogEntityFailedEvent(input, errors)
+		}
+
+		if err := consulClient.RegisterCatalogEntity(entity, client.WriteOptions{Datacenter: input.Datacenter}); nil != err {
+			return newRegisterCatalogEntityFailedEvent(input, []string{err.Error()})
+		}
+
+		return flyte.Event{
+			EventDef: catalogEntityRegisteredEventDef,
+			Payload: RegisterCatalogEntityOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func newRegisterCatalogEntityFailedEvent(input RegisterCatalogEntityInput, errors []string) flyte.Event {
+	return flyte.Event{
+		EventDef: registrationFailedEventDef,
+		Payload: RegisterCatalogEntityErrorOutput{
+			Input:  input,
+			Errors: errors,
+		},
+	}
+}
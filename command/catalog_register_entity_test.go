@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterCatalogEntityCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := RegisterCatalogEntity(KVTransactionMockConsul)
+
+	assert.Equal(t, "RegisterCatalogEntity", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "CatalogEntityRegistered", command.OutputEvents[0].Name)
+	assert.Equal(t, "RegistrationFailed", command.OutputEvents[1].Name)
+}
+
+func TestRegisterCatalogEntityReturnsCatalogEntityRegisteredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RegisterCatalogEntityFunc = func(entity client.CatalogEntity, options client.WriteOptions) error {
+		assert.Equal(t, "external", entity.Node)
+		assert.Equal(t, "saas.example.com", entity.Address)
+		assert.Equal(t, map[string]string{"external-node": "true"}, entity.NodeMeta)
+		require.NotNil(t, entity.Service)
+		assert.Equal(t, "saas", entity.Service.Name)
+		assert.Equal(t, "dc", options.Datacenter)
+		return nil
+	}
+
+	handler := RegisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler(getValidRegisterCatalogEntityPayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "CatalogEntityRegistered", event.EventDef.Name)
+	assert.Equal(t, "external", event.Payload.(RegisterCatalogEntityOutput).Input.Node)
+}
+
+func TestRegisterCatalogEntityFailsValidation(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := RegisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"address": "saas.example.com"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "RegistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"node is missing"}, event.Payload.(RegisterCatalogEntityErrorOutput).Errors)
+}
+
+func TestRegisterCatalogEntityRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RegisterCatalogEntityFunc = func(entity client.CatalogEntity, options client.WriteOptions) error {
+		return fmt.Errorf("kablammo")
+	}
+
+	handler := RegisterCatalogEntity(KVTransactionMockConsul).Handler
+	event := handler(getValidRegisterCatalogEntityPayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "RegistrationFailed", event.EventDef.Name)
+	assert.Equal(t, []string{"kablammo"}, event.Payload.(RegisterCatalogEntityErrorOutput).Errors)
+}
+
+func getValidRegisterCatalogEntityPayload() []byte {
+	return []byte(`{
+		"dc": "dc",
+		"node": "external",
+		"address": "saas.example.com",
+		"nodeMeta": {"external-node": "true"},
+		"service": {
+			"name": "saas",
+			"port": 443,
+			"checks": [
+				{
+					"http": "https://saas.example.com/health",
+					"interval": "30s"
+				}
+			]
+		}
+	}`)
+}
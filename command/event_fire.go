@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var eventFiredEventDef = flyte.EventDef{Name: "EventFired"}
+
+//FireEventInput represents the FireEvent command payload.
+type FireEventInput struct {
+	Datacenter string `json:"dc"`
+	client.UserEventRequest
+	RequestOptions
+}
+
+//FireEventOutput represents the FireEvent result payload.
+type FireEventOutput struct {
+	Input FireEventInput `json:"input"`
+	ID    string         `json:"id"`
+}
+
+//FireEvent produces the FireEvent flyte command.
+func FireEvent(consulClient client.Consul, tokens TokenRegistry) flyte.Command {
+	return flyte.Command{
+		Name: "FireEvent",
+		OutputEvents: []flyte.EventDef{
+			eventFiredEventDef,
+		},
+		Handler: fireEventHandler(consulClient, tokens),
+	}
+}
+
+func fireEventHandler(consulClient client.Consul, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := FireEventInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Name {
+			return flyte.NewFatalEvent("missing name")
+		}
+		if errors := input.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("event is not valid: %v", strings.Join(errors, ", ")))
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+
+		id, err := consulClient.FireEvent(input.UserEventRequest, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to fire event: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: eventFiredEventDef,
+			Payload: FireEventOutput{
+				Input: input,
+				ID:    id,
+			},
+		}
+	}
+}
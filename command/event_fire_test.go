@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireEventCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := FireEvent(KVTransactionMockConsul, nil)
+
+	assert.Equal(t, "FireEvent", command.Name)
+	require.Equal(t, 1, len(command.OutputEvents))
+	assert.Equal(t, "EventFired", command.OutputEvents[0].Name)
+}
+
+func TestFireEventReturnsEventFiredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.FireEventFunc = func(request client.UserEventRequest, options client.WriteOptions) (string, error) {
+		assert.Equal(t, "deploy", request.Name)
+		assert.Equal(t, `{"version": "v1.2"}`, string(request.Payload))
+		assert.Equal(t, "web", request.ServiceFilter)
+		assert.Equal(t, "canary", request.TagFilter)
+		assert.Equal(t, "dc2", options.Datacenter)
+		return "event-id", nil
+	}
+
+	handler := FireEvent(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"dc": "dc2", "name": "deploy", "payload": {"version": "v1.2"}, "service": "web", "tag": "canary"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "EventFired", event.EventDef.Name)
+	assert.Equal(t, "event-id", event.Payload.(FireEventOutput).ID)
+}
+
+func TestFireEventResolvesToken(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.FireEventFunc = func(request client.UserEventRequest, options client.WriteOptions) (string, error) {
+		assert.Equal(t, "secret", options.Token)
+		assert.Equal(t, "team", options.Namespace)
+		assert.Equal(t, "web", options.Partition)
+		return "event-id", nil
+	}
+
+	handler := FireEvent(KVTransactionMockConsul, TokenRegistry{"deploy": "secret"}).Handler
+	event := handler([]byte(`{"name": "deploy", "token": "deploy", "namespace": "team", "partition": "web"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "EventFired", event.EventDef.Name)
+	assert.Equal(t, "deploy", event.Payload.(FireEventOutput).Input.Token)
+
+	event = handler([]byte(`{"name": "deploy", "token": "other"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "token other is not registered", event.Payload)
+}
+
+func TestFireEventMissingName(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := FireEvent(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"payload": "v1.2"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing name", event.Payload)
+}
+
+func TestFireEventInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := FireEvent(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deploy", "tag": "canary"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "event is not valid: tag filter requires a service filter", event.Payload)
+}
+
+func TestFireEventRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.FireEventFunc = func(request client.UserEventRequest, options client.WriteOptions) (string, error) {
+		return "", fmt.Errorf("kablammo")
+	}
+
+	handler := FireEvent(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"name": "deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to fire event: kablammo", event.Payload)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	healthyInstancesFoundEventDef = flyte.EventDef{Name: "HealthyInstancesFound"}
+	noHealthyInstancesEventDef    = flyte.EventDef{Name: "NoHealthyInstances"}
+)
+
+//GetHealthyServiceInput represents the GetHealthyService command payload.
+//PassingOnly defaults to true.
+type GetHealthyServiceInput struct {
+	Datacenter  string   `json:"dc"`
+	Service     string   `json:"service"`
+	Tags        []string `json:"tags"`
+	PassingOnly *bool    `json:"passingOnly"`
+	Near        string   `json:"near"`
+	Filter      string   `json:"filter"`
+	Consistency string   `json:"consistency"`
+}
+
+//GetHealthyServiceOutput represents the GetHealthyService result payload.
+type GetHealthyServiceOutput struct {
+	Input     GetHealthyServiceInput         `json:"input"`
+	Instances []client.ServiceInstanceHealth `json:"instances,omitempty"`
+}
+
+//GetHealthyService produces the GetHealthyService flyte command.
+func GetHealthyService(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "GetHealthyService",
+		OutputEvents: []flyte.EventDef{
+			healthyInstancesFoundEventDef,
+			noHealthyInstancesEventDef,
+		},
+		Handler: getHealthyServiceHandler(consulClient),
+	}
+}
+
+func getHealthyServiceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := GetHealthyServiceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Service {
+			return flyte.NewFatalEvent("missing service")
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+		if nil == input.PassingOnly {
+			passingOnly := true
+			input.PassingOnly = &passingOnly
+		}
+
+		instances, err := consulClient.GetHealthyService(input.Service, input.Tags, *input.PassingOnly, client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+			Near:        input.Near,
+			Filter:      input.Filter,
+		})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to get service health: %v", err))
+		}
+		if 0 == len(instances) {
+			return flyte.Event{
+				EventDef: noHealthyInstancesEventDef,
+				Payload: GetHealthyServiceOutput{
+					Input: input,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: healthyInstancesFoundEventDef,
+			Payload: GetHealthyServiceOutput{
+				Input:     input,
+				Instances: instances,
+			},
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHealthyServiceCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := GetHealthyService(KVTransactionMockConsul)
+
+	assert.Equal(t, "GetHealthyService", command.Name)
+	require.Equal(t, 2, len(command.OutputEvents))
+	assert.Equal(t, "HealthyInstancesFound", command.OutputEvents[0].Name)
+	assert.Equal(t, "NoHealthyInstances", command.OutputEvents[1].Name)
+}
+
+func TestGetHealthyServiceReturnsHealthyInstancesFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetHealthyServiceFunc = func(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+		assert.Equal(t, "web", name)
+		assert.Equal(t, []string{"prod"}, tags)
+		assert.True(t, passingOnly)
+		assert.Equal(t, "dc", options.Datacenter)
+		return []client.ServiceInstanceHealth{
+			{
+				ServiceInstance: client.ServiceInstance{ID: "web-1"},
+				Status:          "passing",
+			},
+		}, nil
+	}
+
+	handler := GetHealthyService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc", "service": "web", "tags": ["prod"]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "HealthyInstancesFound", event.EventDef.Name)
+	output := event.Payload.(GetHealthyServiceOutput)
+	require.NotNil(t, output.Input.PassingOnly)
+	assert.True(t, *output.Input.PassingOnly)
+	require.Equal(t, 1, len(output.Instances))
+	assert.Equal(t, "web-1", output.Instances[0].ID)
+	assert.Equal(t, "passing", output.Instances[0].Status)
+}
+
+func TestGetHealthyServiceAllInstances(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetHealthyServiceFunc = func(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+		assert.False(t, passingOnly)
+		return []client.ServiceInstanceHealth{{Status: "critical"}}, nil
+	}
+
+	handler := GetHealthyService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": "web", "passingOnly": false}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "HealthyInstancesFound", event.EventDef.Name)
+}
+
+func TestGetHealthyServiceReturnsNoHealthyInstancesEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetHealthyServiceFunc = func(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+		return []client.ServiceInstanceHealth{}, nil
+	}
+
+	handler := GetHealthyService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": "web"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "NoHealthyInstances", event.EventDef.Name)
+	assert.Equal(t, "web", event.Payload.(GetHealthyServiceOutput).Input.Service)
+}
+
+func TestGetHealthyServiceFailsMissingService(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetHealthyService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing service", event.Payload)
+}
+
+func TestGetHealthyServiceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetHealthyServiceFunc = func(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := GetHealthyService(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"service": "web"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to get service health: kablammo", event.Payload)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var kvDeletedEventDef = flyte.EventDef{Name: "KVDeleted"}
+
+const deleteKVCommandName = "DeleteKV"
+
+//DeleteKVInput represents the DeleteKV command payload.
+type DeleteKVInput struct {
+	Datacenter string  `json:"dc"`
+	Key        string  `json:"key"`
+	Recurse    bool    `json:"recurse"`
+	CAS        *uint64 `json:"cas,omitempty"`
+}
+
+//verb returns the key-value transaction verb equivalent to the delete.
+func (i DeleteKVInput) verb() string {
+	switch {
+	case i.Recurse:
+		return "delete-tree"
+	case nil != i.CAS:
+		return "delete-cas"
+	}
+	return "delete"
+}
+
+//DeleteKVOutput represents the DeleteKV result payload.
+type DeleteKVOutput struct {
+	Input DeleteKVInput `json:"input"`
+}
+
+//DeleteKVErrorOutput represents the DeleteKV conflict payload.
+type DeleteKVErrorOutput struct {
+	Input DeleteKVInput `json:"input"`
+	Error string        `json:"error"`
+}
+
+//DeleteKV produces the DeleteKV flyte command.
+//Deletes violating the DeleteKV rules of the policy are rejected, checked as the delete, delete-cas or delete-tree verb.
+func DeleteKV(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: deleteKVCommandName,
+		OutputEvents: []flyte.EventDef{
+			kvDeletedEventDef,
+			kvCASConflictEventDef,
+			kvDeniedEventDef,
+		},
+		Handler: deleteKVHandler(consulClient, policy),
+	}
+}
+
+func deleteKVHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := DeleteKVInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Key {
+			return flyte.NewFatalEvent("missing key")
+		}
+		if input.Recurse && nil != input.CAS {
+			return flyte.NewFatalEvent("cas cannot be used with recurse")
+		}
+		if violations := policy.checkRequest(deleteKVCommandName, input.Datacenter, input.verb(), input.Key, input.Recurse); 0 != len(violations) {
+			return newPolicyDeniedEvent(kvDeniedEventDef, input, violations)
+		}
+
+		del := client.KVDelete{
+			Key:     input.Key,
+			Recurse: input.Recurse,
+			CAS:     input.CAS,
+		}
+		ok, err := consulClient.DeleteKV(del, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to delete key: %v", err))
+		}
+		if !ok {
+			return flyte.Event{
+				EventDef: kvCASConflictEventDef,
+				Payload: DeleteKVErrorOutput{
+					Input: input,
+					Error: "check-and-set index does not match",
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: kvDeletedEventDef,
+			Payload: DeleteKVOutput{
+				Input: input,
+			},
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteKVCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := DeleteKV(KVTransactionMockConsul, nil)
+
+	assert.Equal(t, "DeleteKV", command.Name)
+	require.Equal(t, 3, len(command.OutputEvents))
+	assert.Equal(t, "KVDeleted", command.OutputEvents[0].Name)
+	assert.Equal(t, "KVCASConflict", command.OutputEvents[1].Name)
+	assert.Equal(t, "KVDenied", command.OutputEvents[2].Name)
+}
+
+func TestDeleteKVDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"DeleteKV": {DeniedPrefixes: []string{"config/secret/"}}}
+	handler := DeleteKV(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"key": "config/", "recurse": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	assert.Equal(t, "config/", output.Input.(DeleteKVInput).Key)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Nil(t, output.Violations[0].Index)
+	assert.Equal(t, "deniedPrefixes", output.Violations[0].Rule)
+}
+
+func TestDeleteKVReturnsKVDeletedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteKVFunc = func(del client.KVDelete, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "joe/", del.Key)
+		assert.True(t, del.Recurse)
+		assert.Nil(t, del.CAS)
+		assert.Equal(t, "dc", options.Datacenter)
+		return true, nil
+	}
+
+	handler := DeleteKV(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"dc": "dc", "key": "joe/", "recurse": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVDeleted", event.EventDef.Name)
+	assert.Equal(t, "joe/", event.Payload.(DeleteKVOutput).Input.Key)
+}
+
+func TestDeleteKVReturnsKVCASConflictEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteKVFunc = func(del client.KVDelete, options client.WriteOptions) (bool, error) {
+		require.NotNil(t, del.CAS)
+		assert.Equal(t, uint64(7), *del.CAS)
+		return false, nil
+	}
+
+	handler := DeleteKV(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "joe/mama", "cas": 7}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVCASConflict", event.EventDef.Name)
+	assert.Equal(t, "check-and-set index does not match", event.Payload.(DeleteKVErrorOutput).Error)
+}
+
+func TestDeleteKVFailsMissingKey(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := DeleteKV(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"recurse": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing key", event.Payload)
+}
+
+func TestDeleteKVFailsRecurseWithCAS(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := DeleteKV(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "joe/", "recurse": true, "cas": 7}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "cas cannot be used with recurse", event.Payload)
+}
+
+func TestDeleteKVRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DeleteKVFunc = func(del client.KVDelete, options client.WriteOptions) (bool, error) {
+		return false, fmt.Errorf("kablammo")
+	}
+
+	handler := DeleteKV(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "joe/mama"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to delete key: kablammo", event.Payload)
+}
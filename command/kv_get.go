@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	kvFoundEventDef    = flyte.EventDef{Name: "KVFound"}
+	kvNotFoundEventDef = flyte.EventDef{Name: "KVNotFound"}
+	kvDeniedEventDef   = flyte.EventDef{Name: "KVDenied"}
+)
+
+const getKVCommandName = "GetKV"
+
+//GetKVInput represents the GetKV command payload.
+type GetKVInput struct {
+	Datacenter  string `json:"dc"`
+	Key         string `json:"key"`
+	Recurse     bool   `json:"recurse"`
+	KeysOnly    bool   `json:"keysOnly"`
+	Consistency string `json:"consistency"`
+	Encoding    string `json:"encoding,omitempty"`
+}
+
+//GetKVOutput represents the GetKV result payload.
+type GetKVOutput struct {
+	Input GetKVInput      `json:"input"`
+	Pairs []client.KVPair `json:"pairs,omitempty"`
+	Keys  []string        `json:"keys,omitempty"`
+}
+
+//GetKV produces the GetKV flyte command.
+//Reads violating the GetKV rules of the policy are rejected, prefix lookups being checked as the get-tree verb,
+//and the values of keys matching a redaction pattern are redacted.
+func GetKV(consulClient client.Consul, policy Policy, redaction Redaction) flyte.Command {
+	return flyte.Command{
+		Name: getKVCommandName,
+		OutputEvents: []flyte.EventDef{
+			kvFoundEventDef,
+			kvNotFoundEventDef,
+			kvDeniedEventDef,
+		},
+		Handler: redaction.redactGetKV(getKVHandler(consulClient, policy)),
+	}
+}
+
+func getKVHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := GetKVInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		isPrefixLookup := input.Recurse || input.KeysOnly
+		if "" == input.Key && !isPrefixLookup {
+			return flyte.NewFatalEvent("missing key")
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+		if !client.IsEncodingSupported(input.Encoding) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v encoding is not valid", input.Encoding))
+		}
+		verb := "get"
+		if isPrefixLookup {
+			verb = "get-tree"
+		}
+		if violations := policy.checkRequest(getKVCommandName, input.Datacenter, verb, input.Key, isPrefixLookup); 0 != len(violations) {
+			return newPolicyDeniedEvent(kvDeniedEventDef, input, violations)
+		}
+
+		options := client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+		}
+
+		switch {
+		case input.KeysOnly:
+			keys, err := consulClient.ListKVKeys(input.Key, options)
+			if nil != err {
+				return flyte.NewFatalEvent(fmt.Sprintf("failed to get key: %v", err))
+			}
+			if 0 == len(keys) {
+				return newKVNotFoundEvent(input)
+			}
+			return newKVFoundEvent(GetKVOutput{Input: input, Keys: keys})
+		case input.Recurse:
+			pairs, err := consulClient.ListKV(input.Key, input.Encoding, options)
+			if nil != err {
+				return flyte.NewFatalEvent(fmt.Sprintf("failed to get key: %v", err))
+			}
+			if 0 == len(pairs) {
+				return newKVNotFoundEvent(input)
+			}
+			return newKVFoundEvent(GetKVOutput{Input: input, Pairs: pairs})
+		default:
+			pair, err := consulClient.GetKV(input.Key, input.Encoding, options)
+			if nil != err {
+				return flyte.NewFatalEvent(fmt.Sprintf("failed to get key: %v", err))
+			}
+			if nil == pair {
+				return newKVNotFoundEvent(input)
+			}
+			return newKVFoundEvent(GetKVOutput{Input: input, Pairs: []client.KVPair{*pair}})
+		}
+	}
+}
+
+func newKVFoundEvent(output GetKVOutput) flyte.Event {
+	return flyte.Event{
+		EventDef: kvFoundEventDef,
+		Payload:  output,
+	}
+}
+
+func newKVNotFoundEvent(input GetKVInput) flyte.Event {
+	return flyte.Event{
+		EventDef: kvNotFoundEventDef,
+		Payload: GetKVOutput{
+			Input: input,
+		},
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetKVCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := GetKV(KVTransactionMockConsul, nil, Redaction{})
+
+	assert.Equal(t, "GetKV", command.Name)
+	require.Equal(t, 3, len(command.OutputEvents))
+	assert.Equal(t, "KVFound", command.OutputEvents[0].Name)
+	assert.Equal(t, "KVNotFound", command.OutputEvents[1].Name)
+	assert.Equal(t, "KVDenied", command.OutputEvents[2].Name)
+}
+
+func TestGetKVDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"GetKV": {Verbs: []string{"get"}, Datacenters: []string{"dc1"}}}
+	handler := GetKV(KVTransactionMockConsul, policy, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc2", "key": "config/", "keysOnly": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 2, len(output.Violations))
+	assert.Equal(t, "datacenters", output.Violations[0].Rule)
+	assert.Equal(t, "verbs", output.Violations[1].Rule)
+	assert.Equal(t, "verb get-tree is not allowed", output.Violations[1].Error)
+}
+
+func TestGetKVReturnsKVFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetKVFunc = func(key, encoding string, options client.QueryOptions) (*client.KVPair, error) {
+		assert.Equal(t, "joe/mama", key)
+		assert.Equal(t, "dc", options.Datacenter)
+		assert.Equal(t, "stale", options.Consistency)
+		return &client.KVPair{Key: key, Value: []byte(`"value"`), ModifyIndex: 7}, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc", "key": "joe/mama", "consistency": "stale"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVFound", event.EventDef.Name)
+	output := event.Payload.(GetKVOutput)
+	assert.Equal(t, "joe/mama", output.Input.Key)
+	require.Equal(t, 1, len(output.Pairs))
+	assert.Equal(t, "joe/mama", output.Pairs[0].Key)
+	assert.Equal(t, `"value"`, string(output.Pairs[0].Value))
+	assert.Equal(t, uint64(7), output.Pairs[0].ModifyIndex)
+}
+
+func TestGetKVReturnsKVNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetKVFunc = func(key, encoding string, options client.QueryOptions) (*client.KVPair, error) {
+		return nil, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVNotFound", event.EventDef.Name)
+	assert.Equal(t, "joe/mama", event.Payload.(GetKVOutput).Input.Key)
+}
+
+func TestGetKVRecurse(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListKVFunc = func(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+		assert.Equal(t, "joe/", prefix)
+		return []client.KVPair{{Key: "joe/mama"}, {Key: "joe/papa"}}, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/", "recurse": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVFound", event.EventDef.Name)
+	require.Equal(t, 2, len(event.Payload.(GetKVOutput).Pairs))
+}
+
+func TestGetKVRecurseNotFound(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListKVFunc = func(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+		return []client.KVPair{}, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/", "recurse": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVNotFound", event.EventDef.Name)
+}
+
+func TestGetKVKeysOnly(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListKVKeysFunc = func(prefix string, options client.QueryOptions) ([]string, error) {
+		assert.Equal(t, "", prefix)
+		return []string{"joe/mama"}, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"keysOnly": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVFound", event.EventDef.Name)
+	output := event.Payload.(GetKVOutput)
+	assert.Equal(t, []string{"joe/mama"}, output.Keys)
+	assert.Nil(t, output.Pairs)
+}
+
+func TestGetKVFailsInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`asdk292ds{}][;dsfjIljskdf{}[`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.True(t, strings.HasPrefix(event.Payload.(string), "input is not valid"))
+}
+
+func TestGetKVFailsMissingKey(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing key", event.Payload)
+}
+
+func TestGetKVFailsInvalidConsistency(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "consistency": "eventual"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "eventual consistency mode is not valid", event.Payload)
+}
+
+func TestGetKVPassesEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListKVFunc = func(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+		assert.Equal(t, client.EncodingYAML, encoding)
+		return []client.KVPair{{Key: "joe/a", Value: []byte(`{"one":1}`)}}, nil
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/", "recurse": true, "encoding": "yaml"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVFound", event.EventDef.Name)
+}
+
+func TestGetKVFailsInvalidEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "encoding": "xml"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "xml encoding is not valid", event.Payload)
+}
+
+func TestGetKVRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetKVFunc = func(key, encoding string, options client.QueryOptions) (*client.KVPair, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := GetKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to get key: kablammo", event.Payload)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	kvWrittenEventDef     = flyte.EventDef{Name: "KVWritten"}
+	kvCASConflictEventDef = flyte.EventDef{Name: "KVCASConflict"}
+)
+
+const putKVCommandName = "PutKV"
+
+//PutKVInput represents the PutKV command payload.
+type PutKVInput struct {
+	Datacenter string          `json:"dc"`
+	Key        string          `json:"key"`
+	Value      json.RawMessage `json:"value"`
+	Flags      uint64          `json:"flags"`
+	CAS        *uint64         `json:"cas,omitempty"`
+	Acquire    string          `json:"acquire,omitempty"`
+	Release    string          `json:"release,omitempty"`
+	Encoding   string          `json:"encoding,omitempty"`
+}
+
+//verb returns the key-value transaction verb equivalent to the write.
+func (i PutKVInput) verb() string {
+	switch {
+	case nil != i.CAS:
+		return "cas"
+	case "" != i.Acquire:
+		return "lock"
+	case "" != i.Release:
+		return "unlock"
+	}
+	return "set"
+}
+
+//PutKVOutput represents the PutKV result payload.
+type PutKVOutput struct {
+	Input PutKVInput `json:"input"`
+}
+
+//PutKVErrorOutput represents the PutKV conflict payload.
+type PutKVErrorOutput struct {
+	Input PutKVInput `json:"input"`
+	Error string     `json:"error"`
+}
+
+//PutKV produces the PutKV flyte command.
+//Writes violating the PutKV rules of the policy are rejected, checked as the set, cas, lock or unlock verb,
+//and the echoed value of a key matching a redaction pattern is redacted.
+func PutKV(consulClient client.Consul, policy Policy, redaction Redaction) flyte.Command {
+	return flyte.Command{
+		Name: putKVCommandName,
+		OutputEvents: []flyte.EventDef{
+			kvWrittenEventDef,
+			kvCASConflictEventDef,
+			kvDeniedEventDef,
+		},
+		Handler: redaction.redactPutKV(putKVHandler(consulClient, policy)),
+	}
+}
+
+func putKVHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := PutKVInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Key {
+			return flyte.NewFatalEvent("missing key")
+		}
+		if 1 < countSet(nil != input.CAS, "" != input.Acquire, "" != input.Release) {
+			return flyte.NewFatalEvent("only one of cas, acquire and release can be set")
+		}
+		if !client.IsEncodingSupported(input.Encoding) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v encoding is not valid", input.Encoding))
+		}
+		if violations := policy.checkRequest(putKVCommandName, input.Datacenter, input.verb(), input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(kvDeniedEventDef, input, violations)
+		}
+
+		put := client.KVPut{
+			Key:      input.Key,
+			Value:    input.Value,
+			Flags:    input.Flags,
+			CAS:      input.CAS,
+			Acquire:  input.Acquire,
+			Release:  input.Release,
+			Encoding: input.Encoding,
+		}
+		if errors := put.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("value is not valid: %v", strings.Join(errors, ", ")))
+		}
+		ok, err := consulClient.PutKV(put, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to put key: %v", err))
+		}
+		if !ok {
+			return newPutKVConflictEvent(input)
+		}
+
+		return flyte.Event{
+			EventDef: kvWrittenEventDef,
+			Payload: PutKVOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func newPutKVConflictEvent(input PutKVInput) flyte.Event {
+	reason := "check-and-set index does not match"
+	switch {
+	case "" != input.Acquire:
+		reason = "lock is held by another session"
+	case "" != input.Release:
+		reason = "lock is not held by the session"
+	}
+
+	return flyte.Event{
+		EventDef: kvCASConflictEventDef,
+		Payload: PutKVErrorOutput{
+			Input: input,
+			Error: reason,
+		},
+	}
+}
+
+func countSet(flags ...bool) int {
+	retval := 0
+	for _, flag := range flags {
+		if flag {
+			retval++
+		}
+	}
+	return retval
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutKVCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := PutKV(KVTransactionMockConsul, nil, Redaction{})
+
+	assert.Equal(t, "PutKV", command.Name)
+	require.Equal(t, 3, len(command.OutputEvents))
+	assert.Equal(t, "KVWritten", command.OutputEvents[0].Name)
+	assert.Equal(t, "KVCASConflict", command.OutputEvents[1].Name)
+	assert.Equal(t, "KVDenied", command.OutputEvents[2].Name)
+}
+
+func TestPutKVDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"PutKV": {AllowedPrefixes: []string{"config/"}, Verbs: []string{"set"}}}
+	handler := PutKV(KVTransactionMockConsul, policy, Redaction{}).Handler
+	event := handler([]byte(`{"key": "config/app", "value": 1, "acquire": "abc"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "verb lock is not allowed", output.Violations[0].Error)
+
+	event = handler([]byte(`{"key": "other/app", "value": 1}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVDenied", event.EventDef.Name)
+	assert.Equal(t, "allowedPrefixes", event.Payload.(PolicyDeniedOutput).Violations[0].Rule)
+}
+
+func TestPutKVReturnsKVWrittenEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "joe/mama", put.Key)
+		assert.Equal(t, `{"one":1}`, string(put.Value))
+		assert.Equal(t, uint64(42), put.Flags)
+		require.NotNil(t, put.CAS)
+		assert.Equal(t, uint64(7), *put.CAS)
+		assert.Equal(t, "dc", options.Datacenter)
+		return true, nil
+	}
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc", "key": "joe/mama", "value": {"one":1}, "flags": 42, "cas": 7}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVWritten", event.EventDef.Name)
+	assert.Equal(t, "joe/mama", event.Payload.(PutKVOutput).Input.Key)
+}
+
+func TestPutKVReturnsKVCASConflictEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		return false, nil
+	}
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "cas": 0}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVCASConflict", event.EventDef.Name)
+	assert.Equal(t, "check-and-set index does not match", event.Payload.(PutKVErrorOutput).Error)
+}
+
+func TestPutKVAcquireConflict(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "session", put.Acquire)
+		return false, nil
+	}
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "acquire": "session"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVCASConflict", event.EventDef.Name)
+	assert.Equal(t, "lock is held by another session", event.Payload.(PutKVErrorOutput).Error)
+}
+
+func TestPutKVFailsMissingKey(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"value": {}}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing key", event.Payload)
+}
+
+func TestPutKVFailsConflictingConditions(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "cas": 1, "acquire": "session"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "only one of cas, acquire and release can be set", event.Payload)
+}
+
+func TestPutKVPassesEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, client.EncodingString, put.Encoding)
+		assert.Equal(t, `"hello"`, string(put.Value))
+		return true, nil
+	}
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "value": "hello", "encoding": "string"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVWritten", event.EventDef.Name)
+}
+
+func TestPutKVFailsInvalidEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama", "value": "hello", "encoding": "xml"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "xml encoding is not valid", event.Payload)
+
+	event = handler([]byte(`{"key": "joe/mama", "value": 1, "encoding": "string"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "value is not valid: value must be a string with string encoding", event.Payload)
+}
+
+func TestPutKVRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		return false, fmt.Errorf("kablammo")
+	}
+
+	handler := PutKV(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"key": "joe/mama"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to put key: kablammo", event.Payload)
+}
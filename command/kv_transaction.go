@@ -15,48 +15,122 @@ package command
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ExpediaGroup/flyte-client/flyte"
 	client "github.com/ExpediaGroup/flyte-consul/client"
 )
 
 var (
-	transactionSucceededEventDef  = flyte.EventDef{Name: "TransactionSucceeded"}
-	transactionRolledBackEventDef = flyte.EventDef{Name: "TransactionRolledBack"}
+	transactionSucceededEventDef        = flyte.EventDef{Name: "TransactionSucceeded"}
+	transactionRolledBackEventDef       = flyte.EventDef{Name: "TransactionRolledBack"}
+	transactionPartiallyAppliedEventDef = flyte.EventDef{Name: "TransactionPartiallyApplied"}
+	transactionForbiddenEventDef        = flyte.EventDef{Name: "TransactionForbidden"}
+	transactionUnavailableEventDef      = flyte.EventDef{Name: "TransactionUnavailable"}
+	transactionInvalidEventDef          = flyte.EventDef{Name: "TransactionInvalid"}
+	transactionPlannedEventDef          = flyte.EventDef{Name: "TransactionPlanned"}
+	transactionDeniedEventDef           = flyte.EventDef{Name: "TransactionDenied"}
 )
 
+const transactKVCommandName = "TransactKV"
+
 //TransactKVInput represents the TransactKV command payload.
+//NonAtomic applies the operations in sequential batches of at most client.MaxTxnOperations operations,
+//Compensate restores the keys changed by the applied batches when a later batch fails.
+//Encoding is the value encoding of the operations that do not define their own.
+//Retry overrides the fields it sets of the retry policy the command was produced with.
+//DryRun evaluates the operations against the current values without writing anything.
+//Token is a reference to a token of the pack token registry.
 type TransactKVInput struct {
 	Datacenter string               `json:"dc"`
 	Operations []client.KVOperation `json:"operations"`
+	NonAtomic  bool                 `json:"nonAtomic,omitempty"`
+	Compensate bool                 `json:"compensate,omitempty"`
+	Encoding   string               `json:"encoding,omitempty"`
+	Retry      *client.RetryPolicy  `json:"retry,omitempty"`
+	DryRun     bool                 `json:"dryRun,omitempty"`
+	RequestOptions
+}
+
+//operations returns the operations with the default encoding applied, leaving the input untouched.
+func (i TransactKVInput) operations() []client.KVOperation {
+	operations := make([]client.KVOperation, len(i.Operations))
+	for index, operation := range i.Operations {
+		if "" == operation.Encoding {
+			operation.Encoding = i.Encoding
+		}
+		operations[index] = operation
+	}
+	return operations
 }
 
 //TransactKVErrorOutput represents the error payload.
 type TransactKVErrorOutput struct {
-	Input  TransactKVInput             `json:"input"`
-	Errors []client.KVTransactionError `json:"errors"`
+	Input    TransactKVInput             `json:"input"`
+	Errors   []client.KVTransactionError `json:"errors"`
+	Attempts int                         `json:"attempts"`
 }
 
 //TransactKVResultOutput represents the result payload.
 type TransactKVResultOutput struct {
-	Input   TransactKVInput              `json:"input"`
-	Results []client.KVTransactionResult `json:"results"`
+	Input    TransactKVInput              `json:"input"`
+	Results  []client.KVTransactionResult `json:"results"`
+	Attempts int                          `json:"attempts"`
+}
+
+//TransactKVPartialOutput represents the partially applied payload.
+type TransactKVPartialOutput struct {
+	Input    TransactKVInput              `json:"input"`
+	Results  []client.KVTransactionResult `json:"results"`
+	Attempts int                          `json:"attempts"`
+	client.KVPartialTransaction
+}
+
+//TransactKVPlanOutput represents the dry run payload.
+type TransactKVPlanOutput struct {
+	Input    TransactKVInput `json:"input"`
+	Attempts int             `json:"attempts"`
+	client.KVTransactionPlan
+}
+
+//TransactKVDeniedOutput represents the payload of operations rejected by the policy.
+type TransactKVDeniedOutput struct {
+	Input      TransactKVInput   `json:"input"`
+	Violations []PolicyViolation `json:"violations"`
+}
+
+//TransactKVFailureOutput represents the payload of a classified request failure.
+type TransactKVFailureOutput struct {
+	Input      TransactKVInput `json:"input"`
+	Error      string          `json:"error"`
+	StatusCode int             `json:"statusCode,omitempty"`
+	Retryable  bool            `json:"retryable"`
+	Attempts   int             `json:"attempts"`
 }
 
 //TransactKV produces the TransactKV flyte command.
-func TransactKV(consulClient client.Consul) flyte.Command {
+//Requests failing with retryable errors are retried following the retry policy,
+//operations violating the TransactKV rules of the policy are rejected and values are redacted from the events.
+func TransactKV(consulClient client.Consul, retryPolicy client.RetryPolicy, policy Policy, tokens TokenRegistry, redaction Redaction) flyte.Command {
 	return flyte.Command{
-		Name: "TransactKV",
+		Name: transactKVCommandName,
 		OutputEvents: []flyte.EventDef{
 			transactionSucceededEventDef,
 			transactionRolledBackEventDef,
+			transactionPartiallyAppliedEventDef,
+			transactionForbiddenEventDef,
+			transactionUnavailableEventDef,
+			transactionInvalidEventDef,
+			transactionPlannedEventDef,
+			transactionDeniedEventDef,
 		},
-		Handler: transactKVHandler(consulClient),
+		Handler: redaction.redactTransactKV(transactKVHandler(consulClient, retryPolicy, policy, tokens)),
 	}
 }
 
-func transactKVHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+func transactKVHandler(consulClient client.Consul, retryPolicy client.RetryPolicy, policy Policy, tokens TokenRegistry) func(json.RawMessage) flyte.Event {
 	return func(rawInput json.RawMessage) flyte.Event {
 		input := TransactKVInput{}
 		if err := json.Unmarshal(rawInput, &input); nil != err {
@@ -65,9 +139,38 @@ func transactKVHandler(consulClient client.Consul) func(json.RawMessage) flyte.E
 		if 0 == len(input.Operations) {
 			return flyte.NewFatalEvent("missing operations")
 		}
+		if input.Compensate && !input.NonAtomic {
+			return flyte.NewFatalEvent("compensate requires nonAtomic")
+		}
+		if client.MaxTxnOperations < len(input.Operations) && !input.NonAtomic {
+			return flyte.NewFatalEvent(fmt.Sprintf("transactions are limited to %d operations, set nonAtomic to apply them in batches", client.MaxTxnOperations))
+		}
+		if !client.IsEncodingSupported(input.Encoding) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v encoding is not valid", input.Encoding))
+		}
+		if nil != input.Retry {
+			if errors := input.Retry.Validate(); 0 != len(errors) {
+				return flyte.NewFatalEvent(fmt.Sprintf("retry is not valid: %v", strings.Join(errors, ", ")))
+			}
+			retryPolicy = retryPolicy.Override(*input.Retry)
+		}
+		options, err := input.writeOptions(input.Datacenter, tokens)
+		if nil != err {
+			return flyte.NewFatalEvent(err.Error())
+		}
+		if violations := checkTransactKVPolicy(policy, input); 0 != len(violations) {
+			return flyte.Event{
+				EventDef: transactionDeniedEventDef,
+				Payload: TransactKVDeniedOutput{
+					Input:      input,
+					Violations: violations,
+				},
+			}
+		}
 
+		operations := input.operations()
 		errors := []client.KVTransactionError{}
-		for index, operation := range input.Operations {
+		for index, operation := range operations {
 			isKeyMissing := operation.Key == ""
 			isVerbSupported := consulClient.IsVerbSupported(operation.Verb)
 
@@ -86,40 +189,157 @@ func transactKVHandler(consulClient client.Consul) func(json.RawMessage) flyte.E
 			if isKeyMissing || !isVerbSupported {
 				continue
 			}
+
+			for _, err := range operation.Validate() {
+				errors = append(errors, client.KVTransactionError{
+					Index: index,
+					Error: err,
+				})
+			}
 		}
 
+		if input.DryRun {
+			return planTransactKV(consulClient, retryPolicy, input, operations, errors, options)
+		}
 		if 0 != len(errors) {
-			return newTransactionRolledBackEvent(input, errors)
+			return newTransactionRolledBackEvent(input, errors, 0)
+		}
+
+		if input.NonAtomic {
+			return transactKVBatches(consulClient, retryPolicy, input, operations, options)
 		}
 
-		results, rollback, err := consulClient.KVTransact(input.Datacenter, input.Operations)
+		var results []client.KVTransactionResult
+		var rollback []client.KVTransactionError
+		attempts, err := retryPolicy.Do(func() (err error) {
+			results, rollback, err = consulClient.KVTransact(operations, options)
+			return err
+		})
 		if nil != err {
-			return flyte.NewFatalEvent(fmt.Sprintf("failed to make transaction request: %v", err))
+			return newTransactionFailedEvent(input, err, attempts)
 		}
 		if 0 < len(rollback) {
-			return newTransactionRolledBackEvent(input, rollback)
+			return newTransactionRolledBackEvent(input, rollback, attempts)
 		}
 
-		return newTransactionSucceededEvent(input, results)
+		return newTransactionSucceededEvent(input, results, attempts)
+	}
+}
+
+//transactKVBatches retries only failures of the first batch, later failures are reported as partially applied.
+func transactKVBatches(consulClient client.Consul, retryPolicy client.RetryPolicy, input TransactKVInput, operations []client.KVOperation, options client.WriteOptions) flyte.Event {
+	var results []client.KVTransactionResult
+	var rollback []client.KVTransactionError
+	var partial *client.KVPartialTransaction
+	attempts, err := retryPolicy.Do(func() (err error) {
+		results, rollback, partial, err = consulClient.KVTransactBatches(operations, input.Compensate, options)
+		return err
+	})
+	if nil != err {
+		return newTransactionFailedEvent(input, err, attempts)
+	}
+	if 0 < len(rollback) {
+		return newTransactionRolledBackEvent(input, rollback, attempts)
+	}
+	if nil != partial {
+		return flyte.Event{
+			EventDef: transactionPartiallyAppliedEventDef,
+			Payload: TransactKVPartialOutput{
+				Input:                input,
+				Results:              results,
+				Attempts:             attempts,
+				KVPartialTransaction: *partial,
+			},
+		}
+	}
+
+	return newTransactionSucceededEvent(input, results, attempts)
+}
+
+func checkTransactKVPolicy(policy Policy, input TransactKVInput) []PolicyViolation {
+	violations := []PolicyViolation{}
+	if violation := policy.checkDatacenter(transactKVCommandName, input.Datacenter); nil != violation {
+		violations = append(violations, *violation)
+	}
+	for index, operation := range input.Operations {
+		tree := "get-tree" == operation.Verb || "delete-tree" == operation.Verb
+		if violation := policy.checkKV(transactKVCommandName, index, operation.Verb, operation.Key, tree); nil != violation {
+			violations = append(violations, *violation)
+		}
+	}
+	return violations
+}
+
+//planTransactKV evaluates valid operations without writing them, invalid ones are planned with their validation errors.
+func planTransactKV(consulClient client.Consul, retryPolicy client.RetryPolicy, input TransactKVInput, operations []client.KVOperation, errors []client.KVTransactionError, options client.WriteOptions) flyte.Event {
+	plan := &client.KVTransactionPlan{
+		Operations: []client.KVPlannedOperation{},
+		Errors:     errors,
+	}
+	attempts := 0
+	if 0 == len(errors) {
+		var err error
+		attempts, err = retryPolicy.Do(func() (err error) {
+			plan, err = consulClient.KVTransactPlan(operations, options)
+			return err
+		})
+		if nil != err {
+			return newTransactionFailedEvent(input, err, attempts)
+		}
+	}
+
+	return flyte.Event{
+		EventDef: transactionPlannedEventDef,
+		Payload: TransactKVPlanOutput{
+			Input:             input,
+			Attempts:          attempts,
+			KVTransactionPlan: *plan,
+		},
+	}
+}
+
+//newTransactionFailedEvent emits the event of the error class, unclassified errors being fatal.
+func newTransactionFailedEvent(input TransactKVInput, err error, attempts int) flyte.Event {
+	var requestErr *client.RequestError
+	if !errors.As(err, &requestErr) || "" == requestErr.Class {
+		return flyte.NewFatalEvent(fmt.Sprintf("failed to make transaction request: %v", err))
+	}
+
+	eventDefs := map[string]flyte.EventDef{
+		client.ErrorForbidden:   transactionForbiddenEventDef,
+		client.ErrorUnavailable: transactionUnavailableEventDef,
+		client.ErrorInvalid:     transactionInvalidEventDef,
+	}
+	return flyte.Event{
+		EventDef: eventDefs[requestErr.Class],
+		Payload: TransactKVFailureOutput{
+			Input:      input,
+			Error:      err.Error(),
+			StatusCode: requestErr.StatusCode,
+			Retryable:  requestErr.Retryable,
+			Attempts:   attempts,
+		},
 	}
 }
 
-func newTransactionRolledBackEvent(input TransactKVInput, errors []client.KVTransactionError) flyte.Event {
+func newTransactionRolledBackEvent(input TransactKVInput, errors []client.KVTransactionError, attempts int) flyte.Event {
 	return flyte.Event{
 		EventDef: transactionRolledBackEventDef,
 		Payload: TransactKVErrorOutput{
-			Input:  input,
-			Errors: errors,
+			Input:    input,
+			Errors:   errors,
+			Attempts: attempts,
 		},
 	}
 }
 
-func newTransactionSucceededEvent(input TransactKVInput, results []client.KVTransactionResult) flyte.Event {
+func newTransactionSucceededEvent(input TransactKVInput, results []client.KVTransactionResult, attempts int) flyte.Event {
 	return flyte.Event{
 		EventDef: transactionSucceededEventDef,
 		Payload: TransactKVResultOutput{
-			Input:   input,
-			Results: results,
+			Input:    input,
+			Results:  results,
+			Attempts: attempts,
 		},
 	}
 }
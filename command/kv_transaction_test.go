@@ -14,6 +14,7 @@ limitations under the License.
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -39,12 +40,18 @@ func TestTransactKVCommandIsPopulated(t *testing.T) {
 	Before()
 	defer After()
 
-	command := TransactKV(KVTransactionMockConsul)
+	command := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{})
 
 	assert.Equal(t, "TransactKV", command.Name)
-	require.Equal(t, 2, len(command.OutputEvents))
+	require.Equal(t, 8, len(command.OutputEvents))
 	assert.Equal(t, "TransactionSucceeded", command.OutputEvents[0].Name)
 	assert.Equal(t, "TransactionRolledBack", command.OutputEvents[1].Name)
+	assert.Equal(t, "TransactionPartiallyApplied", command.OutputEvents[2].Name)
+	assert.Equal(t, "TransactionForbidden", command.OutputEvents[3].Name)
+	assert.Equal(t, "TransactionUnavailable", command.OutputEvents[4].Name)
+	assert.Equal(t, "TransactionInvalid", command.OutputEvents[5].Name)
+	assert.Equal(t, "TransactionPlanned", command.OutputEvents[6].Name)
+	assert.Equal(t, "TransactionDenied", command.OutputEvents[7].Name)
 }
 
 func TestTransactKVReturnsTransactionSucceededEvent(t *testing.T) {
@@ -52,7 +59,7 @@ func TestTransactKVReturnsTransactionSucceededEvent(t *testing.T) {
 	defer After()
 
 	const key = "joe/mama"
-	KVTransactionMockConsul.KVTransactFunc = func(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
 		return []client.KVTransactionResult{
 			{
 				Index: 0,
@@ -65,7 +72,7 @@ func TestTransactKVReturnsTransactionSucceededEvent(t *testing.T) {
 		return true
 	}
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 
 	event := handler(getValidTransactKVPayload())
 	println(fmt.Sprintf("event: %+v", event.Payload))
@@ -84,7 +91,7 @@ func TestTransactKVFailsInvalidInput(t *testing.T) {
 	Before()
 	defer After()
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler([]byte(`asdk292ds{}][;dsfjIljskdf{}[`))
 
 	require.NotNil(t, event)
@@ -96,7 +103,7 @@ func TestTransactKVFailsMissingOperations(t *testing.T) {
 	Before()
 	defer After()
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler([]byte(`{
 		"dc": "dc",
 		"operations": []
@@ -115,7 +122,7 @@ func TestTransactKVFailedMissingKey(t *testing.T) {
 		return true
 	}
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler([]byte(`{
 		"dc": "dc",
 		"operations": [
@@ -142,7 +149,7 @@ func TestTransactKVFailedUnsupportedVerb(t *testing.T) {
 		return false
 	}
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler(getValidTransactKVPayload())
 
 	require.NotNil(t, event)
@@ -157,14 +164,14 @@ func TestTransactKVRequestFailed(t *testing.T) {
 	Before()
 	defer After()
 
-	KVTransactionMockConsul.KVTransactFunc = func(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
 		return nil, nil, fmt.Errorf("kablammo")
 	}
 	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
 		return true
 	}
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler(getValidTransactKVPayload())
 
 	require.NotNil(t, event)
@@ -172,6 +179,246 @@ func TestTransactKVRequestFailed(t *testing.T) {
 	assert.Equal(t, "failed to make transaction request: kablammo", event.Payload)
 }
 
+func TestTransactKVRequestFailedWithClassifiedError(t *testing.T) {
+	cases := []struct {
+		err       *client.RequestError
+		eventName string
+	}{
+		{&client.RequestError{Class: client.ErrorForbidden, StatusCode: 403, Err: fmt.Errorf("Permission denied")}, "TransactionForbidden"},
+		{&client.RequestError{Class: client.ErrorUnavailable, StatusCode: 500, Retryable: true, Err: fmt.Errorf("No cluster leader")}, "TransactionUnavailable"},
+		{&client.RequestError{Class: client.ErrorInvalid, StatusCode: 500, Err: fmt.Errorf("No path to datacenter")}, "TransactionInvalid"},
+	}
+
+	for _, c := range cases {
+		Before()
+		KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+			return nil, nil, c.err
+		}
+		KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+			return true
+		}
+
+		handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+		event := handler(getValidTransactKVPayload())
+
+		require.NotNil(t, event)
+		assert.Equal(t, c.eventName, event.EventDef.Name)
+		output := event.Payload.(TransactKVFailureOutput)
+		assert.Equal(t, c.err.Error(), output.Error)
+		assert.Equal(t, c.err.StatusCode, output.StatusCode)
+		assert.Equal(t, c.err.Retryable, output.Retryable)
+		After()
+	}
+}
+
+func TestTransactKVRequestFailedWithUnclassifiedError(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return nil, nil, &client.RequestError{Err: fmt.Errorf("kablammo")}
+	}
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getValidTransactKVPayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to make transaction request: kablammo", event.Payload)
+}
+
+func TestTransactKVRetriesRetryableErrors(t *testing.T) {
+	Before()
+	defer After()
+
+	calls := 0
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		calls++
+		if calls < 3 {
+			return nil, nil, &client.RequestError{Class: client.ErrorUnavailable, StatusCode: 500, Retryable: true, Err: fmt.Errorf("No cluster leader")}
+		}
+		return []client.KVTransactionResult{{Key: "joe/mama"}}, nil, nil
+	}
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{MaxAttempts: 3, Backoff: "1ms"}, nil, nil, Redaction{}).Handler
+	event := handler(getValidTransactKVPayload())
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	assert.Equal(t, 3, event.Payload.(TransactKVResultOutput).Attempts)
+}
+
+func TestTransactKVRetryOverriddenByInput(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return nil, nil, &client.RequestError{Class: client.ErrorUnavailable, StatusCode: 503, Retryable: true, Err: fmt.Errorf("unavailable")}
+	}
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{MaxAttempts: 5, Backoff: "1ms"}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"retry": {"maxAttempts": 2}, "operations": [{"verb": "get", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionUnavailable", event.EventDef.Name)
+	assert.Equal(t, 2, event.Payload.(TransactKVFailureOutput).Attempts)
+}
+
+func TestTransactKVInvalidRetry(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"retry": {"backoff": "soon"}, "operations": [{"verb": "get", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "retry is not valid: backoff soon is not a valid duration", event.Payload)
+}
+
+func TestTransactKVDryRun(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		t.Fatal("nothing should be written")
+		return nil, nil, nil
+	}
+	KVTransactionMockConsul.KVTransactPlanFunc = func(operations []client.KVOperation, options client.WriteOptions) (*client.KVTransactionPlan, error) {
+		assert.Equal(t, "dc2", options.Datacenter)
+		require.Equal(t, 1, len(operations))
+		return &client.KVTransactionPlan{
+			Operations: []client.KVPlannedOperation{{Verb: "set", Key: "joe/mama", After: &client.KVPair{Key: "joe/mama"}, Changed: true}},
+			Errors:     []client.KVTransactionError{},
+		}, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc2", "dryRun": true, "operations": [{"verb": "set", "key": "joe/mama", "value": 1}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionPlanned", event.EventDef.Name)
+	output := event.Payload.(TransactKVPlanOutput)
+	assert.Equal(t, 1, output.Attempts)
+	require.Equal(t, 1, len(output.Operations))
+	assert.True(t, output.Operations[0].Changed)
+	assert.Empty(t, output.Errors)
+}
+
+func TestTransactKVDryRunInvalidOperations(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return "set" == verb
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dryRun": true, "operations": [{"verb": "nope", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionPlanned", event.EventDef.Name)
+	output := event.Payload.(TransactKVPlanOutput)
+	assert.Equal(t, 0, output.Attempts)
+	assert.Empty(t, output.Operations)
+	assert.Equal(t, []client.KVTransactionError{{Index: 0, Error: "nope verb is not valid"}}, output.Errors)
+}
+
+func TestTransactKVDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		t.Fatal("denied operations should not be sent")
+		return nil, nil, nil
+	}
+
+	policy := Policy{"TransactKV": {AllowedPrefixes: []string{"config/"}, Datacenters: []string{"dc1"}}}
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, policy, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc2", "operations": [
+		{"verb": "set", "key": "config/app"},
+		{"verb": "set", "key": "secret/app"}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionDenied", event.EventDef.Name)
+	output := event.Payload.(TransactKVDeniedOutput)
+	require.Equal(t, 2, len(output.Violations))
+	assert.Equal(t, "datacenters", output.Violations[0].Rule)
+	assert.Nil(t, output.Violations[0].Index)
+	assert.Equal(t, "allowedPrefixes", output.Violations[1].Rule)
+	assert.Equal(t, 1, *output.Violations[1].Index)
+}
+
+func TestTransactKVAllowedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return []client.KVTransactionResult{{Key: "config/app"}}, nil, nil
+	}
+
+	policy := Policy{"TransactKV": {AllowedPrefixes: []string{"config/"}, Verbs: []string{"set"}}}
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, policy, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"verb": "set", "key": "config/app"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+}
+
+func TestTransactKVResolvesTokenReference(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		assert.Equal(t, client.WriteOptions{Datacenter: "dc2", Token: "secret", Namespace: "team", Partition: "part"}, options)
+		return []client.KVTransactionResult{{Key: "joe/mama"}}, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, TokenRegistry{"deploy": "secret"}, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc2", "token": "deploy", "namespace": "team", "partition": "part", "operations": [{"verb": "get", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	payload, err := json.Marshal(event.Payload)
+	require.Nil(t, err)
+	assert.Contains(t, string(payload), `"token":"deploy"`)
+	assert.NotContains(t, string(payload), "secret")
+}
+
+func TestTransactKVUnknownTokenReference(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, TokenRegistry{"deploy": "secret"}, Redaction{}).Handler
+	event := handler([]byte(`{"token": "admin", "operations": [{"verb": "get", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "token admin is not registered", event.Payload)
+}
+
 func TestTransactKVRolledBack(t *testing.T) {
 	Before()
 	defer After()
@@ -180,7 +427,7 @@ func TestTransactKVRolledBack(t *testing.T) {
 	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
 		return true
 	}
-	KVTransactionMockConsul.KVTransactFunc = func(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
 		return nil, []client.KVTransactionError{
 			{
 				Index: 0,
@@ -189,7 +436,7 @@ func TestTransactKVRolledBack(t *testing.T) {
 		}, nil
 	}
 
-	handler := TransactKV(KVTransactionMockConsul).Handler
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
 	event := handler(getValidTransactKVPayload())
 
 	require.NotNil(t, event)
@@ -200,6 +447,213 @@ func TestTransactKVRolledBack(t *testing.T) {
 	assert.Equal(t, errorMsg, output.Errors[0].Error)
 }
 
+func TestTransactKVFailedVerbRequirements(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [
+		{"verb": "cas", "key": "joe/mama", "index": 0},
+		{"verb": "cas", "key": "joe/mama"},
+		{"verb": "lock", "key": "joe/mama"}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	output := event.Payload.(TransactKVErrorOutput)
+	assert.Equal(t, []client.KVTransactionError{
+		{Index: 1, Error: "cas verb requires an index"},
+		{Index: 2, Error: "lock verb requires a session"},
+	}, output.Errors)
+}
+
+func TestTransactKVPassesIndexFlagsAndSession(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		require.Equal(t, 1, len(operations))
+		require.NotNil(t, operations[0].Index)
+		assert.Equal(t, uint64(7), *operations[0].Index)
+		assert.Equal(t, uint64(2), operations[0].Flags)
+		assert.Equal(t, "a", operations[0].Session)
+		return []client.KVTransactionResult{{Key: "joe/mama", ModifyIndex: 8, Flags: 2}}, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"verb": "cas", "key": "joe/mama", "index": 7, "flags": 2, "session": "a"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	assert.Equal(t, uint64(8), event.Payload.(TransactKVResultOutput).Results[0].ModifyIndex)
+}
+
+func TestTransactKVAppliesDefaultEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		require.Equal(t, 2, len(operations))
+		assert.Equal(t, "string", operations[0].Encoding)
+		assert.Equal(t, "yaml", operations[1].Encoding)
+		return []client.KVTransactionResult{{Key: "joe/mama"}, {Key: "joe/papa"}}, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"encoding": "string", "operations": [
+		{"verb": "set", "key": "joe/mama", "value": "so fat"},
+		{"verb": "set", "key": "joe/papa", "value": {"so": "thin"}, "encoding": "yaml"}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	output := event.Payload.(TransactKVResultOutput)
+	assert.Equal(t, "", output.Input.Operations[0].Encoding)
+}
+
+func TestTransactKVInvalidEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"encoding": "xml", "operations": [{"verb": "set", "key": "joe/mama"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "xml encoding is not valid", event.Payload)
+}
+
+func TestTransactKVValueDoesNotMatchEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"encoding": "base64", "operations": [{"verb": "set", "key": "joe/mama", "value": 1}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	assert.Equal(t, []client.KVTransactionError{
+		{Index: 0, Error: "value must be a string with base64 encoding"},
+	}, event.Payload.(TransactKVErrorOutput).Errors)
+}
+
+func TestTransactKVTooManyOperations(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getTransactKVPayload(65, ""))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "transactions are limited to 64 operations, set nonAtomic to apply them in batches", event.Payload)
+}
+
+func TestTransactKVCompensateRequiresNonAtomic(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getTransactKVPayload(1, `"compensate": true,`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "compensate requires nonAtomic", event.Payload)
+}
+
+func TestTransactKVNonAtomicSucceeded(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactBatchesFunc = func(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error) {
+		assert.Equal(t, 100, len(operations))
+		assert.False(t, compensate)
+		return []client.KVTransactionResult{{Index: 0, Key: "key/0"}}, nil, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getTransactKVPayload(100, `"nonAtomic": true,`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+}
+
+func TestTransactKVNonAtomicPartiallyApplied(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactBatchesFunc = func(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error) {
+		assert.True(t, compensate)
+		return []client.KVTransactionResult{{Index: 0, Key: "key/0"}}, nil, &client.KVPartialTransaction{
+			AppliedBatches: []client.KVBatch{{Index: 0, Start: 0, End: 64}},
+			FailedBatch:    client.KVBatch{Index: 1, Start: 64, End: 100},
+			Errors:         []client.KVTransactionError{{Index: 70, Error: "kablammo"}},
+			Compensated:    true,
+		}, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getTransactKVPayload(100, `"nonAtomic": true, "compensate": true,`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionPartiallyApplied", event.EventDef.Name)
+	output := event.Payload.(TransactKVPartialOutput)
+	assert.Equal(t, 1, len(output.AppliedBatches))
+	assert.Equal(t, 64, output.FailedBatch.Start)
+	assert.Equal(t, 70, output.Errors[0].Index)
+	assert.True(t, output.Compensated)
+}
+
+func TestTransactKVNonAtomicRolledBack(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactBatchesFunc = func(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error) {
+		return nil, []client.KVTransactionError{{Index: 3, Error: "kablammo"}}, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler(getTransactKVPayload(100, `"nonAtomic": true,`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+}
+
+func getTransactKVPayload(count int, options string) []byte {
+	operations := make([]string, count)
+	for index := range operations {
+		operations[index] = fmt.Sprintf(`{"verb": "set", "key": "key/%d"}`, index)
+	}
+	return []byte(fmt.Sprintf(`{%s "operations": [%s]}`, options, strings.Join(operations, ",")))
+}
+
 func getValidTransactKVPayload() []byte {
 	return []byte(getValidTransactKVPayloadString())
 }
@@ -229,14 +683,224 @@ func NewMockConsul() *MockConsul {
 }
 
 type MockConsul struct {
-	KVTransactFunc      func(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error)
-	IsVerbSupportedFunc func(verb string) bool
+	KVTransactFunc              func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error)
+	IsVerbSupportedFunc         func(verb string) bool
+	GetKVFunc                   func(key, encoding string, options client.QueryOptions) (*client.KVPair, error)
+	ListKVFunc                  func(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error)
+	ListKVKeysFunc              func(prefix string, options client.QueryOptions) ([]string, error)
+	PutKVFunc                   func(put client.KVPut, options client.WriteOptions) (bool, error)
+	DeleteKVFunc                func(del client.KVDelete, options client.WriteOptions) (bool, error)
+	WatchKVFunc                 func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error)
+	ListServicesFunc            func(options client.QueryOptions) (map[string][]string, error)
+	GetServiceFunc              func(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error)
+	ListNodesFunc               func(options client.QueryOptions) ([]client.Node, error)
+	GetHealthyServiceFunc       func(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error)
+	WatchHealthChecksFunc       func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error)
+	RegisterServiceFunc         func(service client.ServiceRegistration) error
+	DeregisterServiceFunc       func(serviceID string) error
+	RegisterCatalogEntityFunc   func(entity client.CatalogEntity, options client.WriteOptions) error
+	DeregisterCatalogEntityFunc func(node, serviceID string, options client.WriteOptions) error
+	EnableMaintenanceFunc       func(serviceID, reason string) (*client.HealthCheck, error)
+	DisableMaintenanceFunc      func(serviceID string) error
+	GetMaintenanceFunc          func(serviceID string) (*client.HealthCheck, error)
+	AcquireLockFunc             func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error)
+	ReleaseLockFunc             func(key, session string, options client.WriteOptions) (bool, client.Lock, error)
+	RenewLockFunc               func(key, session string, options client.WriteOptions) (bool, client.Lock, error)
+	CreateSessionFunc           func(request client.SessionRequest, options client.WriteOptions) (string, error)
+	RenewSessionFunc            func(id string, options client.WriteOptions) (*client.Session, error)
+	DestroySessionFunc          func(id string, options client.WriteOptions) (bool, error)
+	GetSessionFunc              func(id string, options client.QueryOptions) (*client.Session, error)
+	ListSessionsFunc            func(node string, options client.QueryOptions) ([]client.Session, error)
+	AcquireSemaphoreFunc        func(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error)
+	ReleaseSemaphoreFunc        func(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error)
+	TransactFunc                func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error)
+	KVTransactBatchesFunc       func(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error)
+	KVTransactPlanFunc          func(operations []client.KVOperation, options client.WriteOptions) (*client.KVTransactionPlan, error)
+	CreateACLTokenFunc          func(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error)
+	ReadACLTokenFunc            func(accessorID string, options client.QueryOptions) (*client.ACLToken, error)
+	UpdateACLTokenFunc          func(accessorID string, request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error)
+	DeleteACLTokenFunc          func(accessorID string, options client.WriteOptions) (bool, error)
+	CloneACLTokenFunc           func(accessorID, description string, options client.WriteOptions) (*client.ACLToken, error)
+	UpsertACLPolicyFunc         func(request client.ACLPolicyRequest, options client.WriteOptions) (*client.ACLPolicy, bool, error)
+	DeleteACLPolicyFunc         func(name string, options client.WriteOptions) (bool, error)
+	UpsertACLRoleFunc           func(request client.ACLRoleRequest, options client.WriteOptions) (*client.ACLRole, bool, error)
+	DeleteACLRoleFunc           func(name string, options client.WriteOptions) (bool, error)
+	FireEventFunc               func(request client.UserEventRequest, options client.WriteOptions) (string, error)
+	WatchUserEventsFunc         func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error)
 }
 
-func (m *MockConsul) KVTransact(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
-	return m.KVTransactFunc(datacenter, operations)
+func (m *MockConsul) KVTransact(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+	return m.KVTransactFunc(operations, options)
 }
 
 func (m *MockConsul) IsVerbSupported(verb string) bool {
 	return m.IsVerbSupportedFunc(verb)
 }
+
+func (m *MockConsul) GetKV(key, encoding string, options client.QueryOptions) (*client.KVPair, error) {
+	return m.GetKVFunc(key, encoding, options)
+}
+
+func (m *MockConsul) ListKV(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+	return m.ListKVFunc(prefix, encoding, options)
+}
+
+func (m *MockConsul) ListKVKeys(prefix string, options client.QueryOptions) ([]string, error) {
+	return m.ListKVKeysFunc(prefix, options)
+}
+
+func (m *MockConsul) PutKV(put client.KVPut, options client.WriteOptions) (bool, error) {
+	return m.PutKVFunc(put, options)
+}
+
+func (m *MockConsul) DeleteKV(del client.KVDelete, options client.WriteOptions) (bool, error) {
+	return m.DeleteKVFunc(del, options)
+}
+
+func (m *MockConsul) WatchKV(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+	return m.WatchKVFunc(key, recurse, options)
+}
+
+func (m *MockConsul) ListServices(options client.QueryOptions) (map[string][]string, error) {
+	return m.ListServicesFunc(options)
+}
+
+func (m *MockConsul) GetService(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error) {
+	return m.GetServiceFunc(name, tags, options)
+}
+
+func (m *MockConsul) ListNodes(options client.QueryOptions) ([]client.Node, error) {
+	return m.ListNodesFunc(options)
+}
+
+func (m *MockConsul) GetHealthyService(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+	return m.GetHealthyServiceFunc(name, tags, passingOnly, options)
+}
+
+func (m *MockConsul) WatchHealthChecks(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+	return m.WatchHealthChecksFunc(service, options)
+}
+
+func (m *MockConsul) RegisterService(service client.ServiceRegistration) error {
+	return m.RegisterServiceFunc(service)
+}
+
+func (m *MockConsul) DeregisterService(serviceID string) error {
+	return m.DeregisterServiceFunc(serviceID)
+}
+
+func (m *MockConsul) RegisterCatalogEntity(entity client.CatalogEntity, options client.WriteOptions) error {
+	return m.RegisterCatalogEntityFunc(entity, options)
+}
+
+func (m *MockConsul) DeregisterCatalogEntity(node, serviceID string, options client.WriteOptions) error {
+	return m.DeregisterCatalogEntityFunc(node, serviceID, options)
+}
+
+func (m *MockConsul) EnableMaintenance(serviceID, reason string) (*client.HealthCheck, error) {
+	return m.EnableMaintenanceFunc(serviceID, reason)
+}
+
+func (m *MockConsul) DisableMaintenance(serviceID string) error {
+	return m.DisableMaintenanceFunc(serviceID)
+}
+
+func (m *MockConsul) GetMaintenance(serviceID string) (*client.HealthCheck, error) {
+	return m.GetMaintenanceFunc(serviceID)
+}
+
+func (m *MockConsul) AcquireLock(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+	return m.AcquireLockFunc(request, options)
+}
+
+func (m *MockConsul) ReleaseLock(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+	return m.ReleaseLockFunc(key, session, options)
+}
+
+func (m *MockConsul) RenewLock(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+	return m.RenewLockFunc(key, session, options)
+}
+
+func (m *MockConsul) CreateSession(request client.SessionRequest, options client.WriteOptions) (string, error) {
+	return m.CreateSessionFunc(request, options)
+}
+
+func (m *MockConsul) RenewSession(id string, options client.WriteOptions) (*client.Session, error) {
+	return m.RenewSessionFunc(id, options)
+}
+
+func (m *MockConsul) DestroySession(id string, options client.WriteOptions) (bool, error) {
+	return m.DestroySessionFunc(id, options)
+}
+
+func (m *MockConsul) GetSession(id string, options client.QueryOptions) (*client.Session, error) {
+	return m.GetSessionFunc(id, options)
+}
+
+func (m *MockConsul) ListSessions(node string, options client.QueryOptions) ([]client.Session, error) {
+	return m.ListSessionsFunc(node, options)
+}
+
+func (m *MockConsul) AcquireSemaphore(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error) {
+	return m.AcquireSemaphoreFunc(request, options)
+}
+
+func (m *MockConsul) ReleaseSemaphore(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error) {
+	return m.ReleaseSemaphoreFunc(prefix, session, options)
+}
+
+func (m *MockConsul) Transact(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+	return m.TransactFunc(operations, options)
+}
+
+func (m *MockConsul) KVTransactBatches(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error) {
+	return m.KVTransactBatchesFunc(operations, compensate, options)
+}
+
+func (m *MockConsul) KVTransactPlan(operations []client.KVOperation, options client.WriteOptions) (*client.KVTransactionPlan, error) {
+	return m.KVTransactPlanFunc(operations, options)
+}
+
+func (m *MockConsul) CreateACLToken(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+	return m.CreateACLTokenFunc(request, options)
+}
+
+func (m *MockConsul) ReadACLToken(accessorID string, options client.QueryOptions) (*client.ACLToken, error) {
+	return m.ReadACLTokenFunc(accessorID, options)
+}
+
+func (m *MockConsul) UpdateACLToken(accessorID string, request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+	return m.UpdateACLTokenFunc(accessorID, request, options)
+}
+
+func (m *MockConsul) DeleteACLToken(accessorID string, options client.WriteOptions) (bool, error) {
+	return m.DeleteACLTokenFunc(accessorID, options)
+}
+
+func (m *MockConsul) CloneACLToken(accessorID, description string, options client.WriteOptions) (*client.ACLToken, error) {
+	return m.CloneACLTokenFunc(accessorID, description, options)
+}
+
+func (m *MockConsul) UpsertACLPolicy(request client.ACLPolicyRequest, options client.WriteOptions) (*client.ACLPolicy, bool, error) {
+	return m.UpsertACLPolicyFunc(request, options)
+}
+
+func (m *MockConsul) DeleteACLPolicy(name string, options client.WriteOptions) (bool, error) {
+	return m.DeleteACLPolicyFunc(name, options)
+}
+
+func (m *MockConsul) UpsertACLRole(request client.ACLRoleRequest, options client.WriteOptions) (*client.ACLRole, bool, error) {
+	return m.UpsertACLRoleFunc(request, options)
+}
+
+func (m *MockConsul) DeleteACLRole(name string, options client.WriteOptions) (bool, error) {
+	return m.DeleteACLRoleFunc(name, options)
+}
+
+func (m *MockConsul) FireEvent(request client.UserEventRequest, options client.WriteOptions) (string, error) {
+	return m.FireEventFunc(request, options)
+}
+
+func (m *MockConsul) WatchUserEvents(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+	return m.WatchUserEventsFunc(name, options)
+}
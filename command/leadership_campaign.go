@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	campaignWonEventDef    = flyte.EventDef{Name: "CampaignWon"}
+	campaignLostEventDef   = flyte.EventDef{Name: "CampaignLost"}
+	campaignDeniedEventDef = flyte.EventDef{Name: "CampaignDenied"}
+)
+
+const campaignLeadershipCommandName = "CampaignLeadership"
+
+//CampaignLeadershipInput represents the CampaignLeadership command payload.
+type CampaignLeadershipInput struct {
+	Datacenter string                `json:"dc"`
+	Key        string                `json:"key"`
+	Candidate  json.RawMessage       `json:"candidate,omitempty"`
+	Session    client.SessionRequest `json:"session"`
+}
+
+//CampaignLeadershipOutput represents the CampaignLeadership result payload.
+type CampaignLeadershipOutput struct {
+	Input  CampaignLeadershipInput `json:"input"`
+	Leader client.Lock             `json:"leader"`
+}
+
+//CampaignLeadership produces the CampaignLeadership flyte command.
+//Campaigns violating the CampaignLeadership rules of the policy are rejected, checked as the lock verb.
+func CampaignLeadership(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: campaignLeadershipCommandName,
+		OutputEvents: []flyte.EventDef{
+			campaignWonEventDef,
+			campaignLostEventDef,
+			campaignDeniedEventDef,
+		},
+		Handler: campaignLeadershipHandler(consulClient, policy),
+	}
+}
+
+func campaignLeadershipHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := CampaignLeadershipInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Key {
+			return flyte.NewFatalEvent("missing key")
+		}
+		if errors := input.Session.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("session is not valid: %v", strings.Join(errors, ", ")))
+		}
+		if violations := policy.checkRequest(campaignLeadershipCommandName, input.Datacenter, "lock", input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(campaignDeniedEventDef, input, violations)
+		}
+
+		request := client.LockRequest{
+			Key:     input.Key,
+			Value:   input.Candidate,
+			Session: input.Session,
+		}
+		ok, leader, err := consulClient.AcquireLock(request, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to campaign for leadership: %v", err))
+		}
+
+		eventDef := campaignWonEventDef
+		if !ok {
+			eventDef = campaignLostEventDef
+		}
+		return flyte.Event{
+			EventDef: eventDef,
+			Payload: CampaignLeadershipOutput{
+				Input:  input,
+				Leader: leader,
+			},
+		}
+	}
+}
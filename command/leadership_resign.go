@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	leadershipResignedEventDef = flyte.EventDef{Name: "LeadershipResigned"}
+	notLeaderEventDef          = flyte.EventDef{Name: "NotLeader"}
+	resignationDeniedEventDef  = flyte.EventDef{Name: "ResignationDenied"}
+)
+
+const resignLeadershipCommandName = "ResignLeadership"
+
+//ResignLeadershipOutput represents the ResignLeadership result payload.
+type ResignLeadershipOutput struct {
+	Input  LockInput    `json:"input"`
+	Leader *client.Lock `json:"leader,omitempty"`
+}
+
+//ResignLeadership produces the ResignLeadership flyte command.
+//Resignations violating the ResignLeadership rules of the policy are rejected, checked as the unlock verb.
+func ResignLeadership(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: resignLeadershipCommandName,
+		OutputEvents: []flyte.EventDef{
+			leadershipResignedEventDef,
+			notLeaderEventDef,
+			resignationDeniedEventDef,
+		},
+		Handler: resignLeadershipHandler(consulClient, policy),
+	}
+}
+
+func resignLeadershipHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input, fatal := unmarshalLockInput(rawInput)
+		if nil != fatal {
+			return *fatal
+		}
+		if violations := policy.checkRequest(resignLeadershipCommandName, input.Datacenter, "unlock", input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(resignationDeniedEventDef, input, violations)
+		}
+
+		ok, leader, err := consulClient.ReleaseLock(input.Key, input.Session, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to resign leadership: %v", err))
+		}
+		if !ok {
+			return flyte.Event{
+				EventDef: notLeaderEventDef,
+				Payload: ResignLeadershipOutput{
+					Input:  input,
+					Leader: &leader,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: leadershipResignedEventDef,
+			Payload: ResignLeadershipOutput{
+				Input: input,
+			},
+		}
+	}
+}
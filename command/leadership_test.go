@@ -0,0 +1,154 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeadershipCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	campaign := CampaignLeadership(KVTransactionMockConsul, nil)
+	assert.Equal(t, "CampaignLeadership", campaign.Name)
+	require.Equal(t, 3, len(campaign.OutputEvents))
+	assert.Equal(t, "CampaignWon", campaign.OutputEvents[0].Name)
+	assert.Equal(t, "CampaignLost", campaign.OutputEvents[1].Name)
+	assert.Equal(t, "CampaignDenied", campaign.OutputEvents[2].Name)
+
+	resign := ResignLeadership(KVTransactionMockConsul, nil)
+	assert.Equal(t, "ResignLeadership", resign.Name)
+	require.Equal(t, 3, len(resign.OutputEvents))
+	assert.Equal(t, "LeadershipResigned", resign.OutputEvents[0].Name)
+	assert.Equal(t, "NotLeader", resign.OutputEvents[1].Name)
+	assert.Equal(t, "ResignationDenied", resign.OutputEvents[2].Name)
+}
+
+func TestCampaignLeadershipReturnsCampaignWonEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		assert.Equal(t, "service/web/leader", request.Key)
+		assert.Equal(t, `"node-1"`, string(request.Value))
+		return true, client.Lock{Key: request.Key, Session: "a"}, nil
+	}
+
+	handler := CampaignLeadership(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "service/web/leader", "candidate": "node-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "CampaignWon", event.EventDef.Name)
+	assert.Equal(t, "a", event.Payload.(CampaignLeadershipOutput).Leader.Session)
+}
+
+func TestCampaignLeadershipReturnsCampaignLostEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{Key: request.Key, Session: "b", Node: "node-2"}, nil
+	}
+
+	handler := CampaignLeadership(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "service/web/leader"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "CampaignLost", event.EventDef.Name)
+	assert.Equal(t, "node-2", event.Payload.(CampaignLeadershipOutput).Leader.Node)
+}
+
+func TestCampaignLeadershipDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"CampaignLeadership": {Datacenters: []string{"dc1"}}}
+	handler := CampaignLeadership(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"dc": "dc2", "key": "service/web/leader"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "CampaignDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "datacenter dc2 is not allowed", output.Violations[0].Error)
+}
+
+func TestResignLeadershipDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"ResignLeadership": {DeniedPrefixes: []string{"service/db/"}}}
+	handler := ResignLeadership(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"key": "service/db/leader", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "ResignationDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "key service/db/leader is denied by prefix service/db/", output.Violations[0].Error)
+}
+
+func TestCampaignLeadershipRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{}, fmt.Errorf("kablammo")
+	}
+
+	handler := CampaignLeadership(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "service/web/leader"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to campaign for leadership: kablammo", event.Payload)
+}
+
+func TestResignLeadershipReturnsLeadershipResignedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return true, client.Lock{Key: key}, nil
+	}
+
+	handler := ResignLeadership(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "service/web/leader", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LeadershipResigned", event.EventDef.Name)
+	assert.Nil(t, event.Payload.(ResignLeadershipOutput).Leader)
+}
+
+func TestResignLeadershipReturnsNotLeaderEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{Key: key, Session: "b"}, nil
+	}
+
+	handler := ResignLeadership(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "service/web/leader", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "NotLeader", event.EventDef.Name)
+	assert.Equal(t, "b", event.Payload.(ResignLeadershipOutput).Leader.Session)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	lockAcquiredEventDef = flyte.EventDef{Name: "LockAcquired"}
+	lockHeldEventDef     = flyte.EventDef{Name: "LockHeld"}
+	lockDeniedEventDef   = flyte.EventDef{Name: "LockDenied"}
+)
+
+const acquireLockCommandName = "AcquireLock"
+
+//AcquireLockInput represents the AcquireLock command payload.
+type AcquireLockInput struct {
+	Datacenter string                `json:"dc"`
+	Key        string                `json:"key"`
+	Value      json.RawMessage       `json:"value,omitempty"`
+	Session    client.SessionRequest `json:"session"`
+}
+
+//AcquireLockOutput represents the AcquireLock result payload.
+type AcquireLockOutput struct {
+	Input  AcquireLockInput `json:"input"`
+	Lock   *client.Lock     `json:"lock,omitempty"`
+	Holder *client.Lock     `json:"holder,omitempty"`
+}
+
+//AcquireLock produces the AcquireLock flyte command.
+//Locks violating the AcquireLock rules of the policy are rejected, checked as the lock verb.
+func AcquireLock(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: acquireLockCommandName,
+		OutputEvents: []flyte.EventDef{
+			lockAcquiredEventDef,
+			lockHeldEventDef,
+			lockDeniedEventDef,
+		},
+		Handler: acquireLockHandler(consulClient, policy),
+	}
+}
+
+func acquireLockHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := AcquireLockInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Key {
+			return flyte.NewFatalEvent("missing key")
+		}
+		if errors := input.Session.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("session is not valid: %v", strings.Join(errors, ", ")))
+		}
+		if violations := policy.checkRequest(acquireLockCommandName, input.Datacenter, "lock", input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(lockDeniedEventDef, input, violations)
+		}
+
+		request := client.LockRequest{
+			Key:     input.Key,
+			Value:   input.Value,
+			Session: input.Session,
+		}
+		ok, lock, err := consulClient.AcquireLock(request, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to acquire lock: %v", err))
+		}
+		if !ok {
+			return flyte.Event{
+				EventDef: lockHeldEventDef,
+				Payload: AcquireLockOutput{
+					Input:  input,
+					Holder: &lock,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: lockAcquiredEventDef,
+			Payload: AcquireLockOutput{
+				Input: input,
+				Lock:  &lock,
+			},
+		}
+	}
+}
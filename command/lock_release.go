@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	lockReleasedEventDef = flyte.EventDef{Name: "LockReleased"}
+	lockNotHeldEventDef  = flyte.EventDef{Name: "LockNotHeld"}
+)
+
+const releaseLockCommandName = "ReleaseLock"
+
+//LockInput represents the ReleaseLock and RenewLock commands payload.
+type LockInput struct {
+	Datacenter string `json:"dc"`
+	Key        string `json:"key"`
+	Session    string `json:"session"`
+}
+
+//LockOutput represents the ReleaseLock and RenewLock result payload.
+type LockOutput struct {
+	Input  LockInput    `json:"input"`
+	Lock   *client.Lock `json:"lock,omitempty"`
+	Holder *client.Lock `json:"holder,omitempty"`
+}
+
+//ReleaseLock produces the ReleaseLock flyte command.
+//Releases violating the ReleaseLock rules of the policy are rejected, checked as the unlock verb.
+func ReleaseLock(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: releaseLockCommandName,
+		OutputEvents: []flyte.EventDef{
+			lockReleasedEventDef,
+			lockNotHeldEventDef,
+			lockDeniedEventDef,
+		},
+		Handler: releaseLockHandler(consulClient, policy),
+	}
+}
+
+func releaseLockHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input, fatal := unmarshalLockInput(rawInput)
+		if nil != fatal {
+			return *fatal
+		}
+		if violations := policy.checkRequest(releaseLockCommandName, input.Datacenter, "unlock", input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(lockDeniedEventDef, input, violations)
+		}
+
+		ok, holder, err := consulClient.ReleaseLock(input.Key, input.Session, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to release lock: %v", err))
+		}
+		if !ok {
+			return flyte.Event{
+				EventDef: lockNotHeldEventDef,
+				Payload: LockOutput{
+					Input:  input,
+					Holder: &holder,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: lockReleasedEventDef,
+			Payload: LockOutput{
+				Input: input,
+			},
+		}
+	}
+}
+
+func unmarshalLockInput(rawInput json.RawMessage) (LockInput, *flyte.Event) {
+	input := LockInput{}
+	if err := json.Unmarshal(rawInput, &input); nil != err {
+		fatal := flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		return input, &fatal
+	}
+	if "" == input.Key {
+		fatal := flyte.NewFatalEvent("missing key")
+		return input, &fatal
+	}
+	if "" == input.Session {
+		fatal := flyte.NewFatalEvent("missing session")
+		return input, &fatal
+	}
+	return input, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	lockRenewedEventDef = flyte.EventDef{Name: "LockRenewed"}
+	lockLostEventDef    = flyte.EventDef{Name: "LockLost"}
+)
+
+const renewLockCommandName = "RenewLock"
+
+//RenewLock produces the RenewLock flyte command.
+//Renewals violating the RenewLock rules of the policy are rejected, checked as the lock verb.
+func RenewLock(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: renewLockCommandName,
+		OutputEvents: []flyte.EventDef{
+			lockRenewedEventDef,
+			lockLostEventDef,
+			lockDeniedEventDef,
+		},
+		Handler: renewLockHandler(consulClient, policy),
+	}
+}
+
+func renewLockHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input, fatal := unmarshalLockInput(rawInput)
+		if nil != fatal {
+			return *fatal
+		}
+		if violations := policy.checkRequest(renewLockCommandName, input.Datacenter, "lock", input.Key, false); 0 != len(violations) {
+			return newPolicyDeniedEvent(lockDeniedEventDef, input, violations)
+		}
+
+		ok, lock, err := consulClient.RenewLock(input.Key, input.Session, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to renew lock: %v", err))
+		}
+		if !ok {
+			return flyte.Event{
+				EventDef: lockLostEventDef,
+				Payload: LockOutput{
+					Input:  input,
+					Holder: &lock,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: lockRenewedEventDef,
+			Payload: LockOutput{
+				Input: input,
+				Lock:  &lock,
+			},
+		}
+	}
+}
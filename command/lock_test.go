@@ -0,0 +1,254 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	acquire := AcquireLock(KVTransactionMockConsul, nil)
+	assert.Equal(t, "AcquireLock", acquire.Name)
+	require.Equal(t, 3, len(acquire.OutputEvents))
+	assert.Equal(t, "LockAcquired", acquire.OutputEvents[0].Name)
+	assert.Equal(t, "LockHeld", acquire.OutputEvents[1].Name)
+	assert.Equal(t, "LockDenied", acquire.OutputEvents[2].Name)
+
+	release := ReleaseLock(KVTransactionMockConsul, nil)
+	assert.Equal(t, "ReleaseLock", release.Name)
+	require.Equal(t, 3, len(release.OutputEvents))
+	assert.Equal(t, "LockReleased", release.OutputEvents[0].Name)
+	assert.Equal(t, "LockNotHeld", release.OutputEvents[1].Name)
+	assert.Equal(t, "LockDenied", release.OutputEvents[2].Name)
+
+	renew := RenewLock(KVTransactionMockConsul, nil)
+	assert.Equal(t, "RenewLock", renew.Name)
+	require.Equal(t, 3, len(renew.OutputEvents))
+	assert.Equal(t, "LockRenewed", renew.OutputEvents[0].Name)
+	assert.Equal(t, "LockLost", renew.OutputEvents[1].Name)
+	assert.Equal(t, "LockDenied", renew.OutputEvents[2].Name)
+}
+
+func TestAcquireLockReturnsLockAcquiredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		assert.Equal(t, "locks/deploy", request.Key)
+		assert.Equal(t, "30s", request.Session.TTL)
+		assert.Equal(t, "dc2", options.Datacenter)
+		return true, client.Lock{Key: request.Key, Session: "session-id"}, nil
+	}
+
+	handler := AcquireLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"dc": "dc2", "key": "locks/deploy", "session": {"ttl": "30s"}}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockAcquired", event.EventDef.Name)
+	output := event.Payload.(AcquireLockOutput)
+	require.NotNil(t, output.Lock)
+	assert.Equal(t, "session-id", output.Lock.Session)
+	assert.Nil(t, output.Holder)
+}
+
+func TestAcquireLockReturnsLockHeldEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{Key: request.Key, Session: "holder-id", Node: "node-2"}, nil
+	}
+
+	handler := AcquireLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockHeld", event.EventDef.Name)
+	output := event.Payload.(AcquireLockOutput)
+	require.NotNil(t, output.Holder)
+	assert.Equal(t, "node-2", output.Holder.Node)
+}
+
+func TestAcquireLockInvalidSession(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := AcquireLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": {"behavior": "explode"}}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "session is not valid: behavior explode is not valid", event.Payload)
+}
+
+func TestAcquireLockDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"AcquireLock": {AllowedPrefixes: []string{"locks/"}}}
+	handler := AcquireLock(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"key": "config/deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "key config/deploy is not under an allowed prefix", output.Violations[0].Error)
+}
+
+func TestReleaseLockDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"ReleaseLock": {Verbs: []string{"lock"}}}
+	handler := ReleaseLock(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "verb unlock is not allowed", output.Violations[0].Error)
+}
+
+func TestRenewLockDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"RenewLock": {AllowedPrefixes: []string{"locks/"}}}
+	handler := RenewLock(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"key": "config/deploy", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "key config/deploy is not under an allowed prefix", output.Violations[0].Error)
+}
+
+func TestAcquireLockRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireLockFunc = func(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{}, fmt.Errorf("kablammo")
+	}
+
+	handler := AcquireLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to acquire lock: kablammo", event.Payload)
+}
+
+func TestReleaseLockReturnsLockReleasedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		assert.Equal(t, "locks/deploy", key)
+		assert.Equal(t, "session-id", session)
+		return true, client.Lock{Key: key}, nil
+	}
+
+	handler := ReleaseLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockReleased", event.EventDef.Name)
+}
+
+func TestReleaseLockReturnsLockNotHeldEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{Key: key, Session: "holder-id"}, nil
+	}
+
+	handler := ReleaseLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockNotHeld", event.EventDef.Name)
+	assert.Equal(t, "holder-id", event.Payload.(LockOutput).Holder.Session)
+}
+
+func TestReleaseLockMissingSession(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := ReleaseLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing session", event.Payload)
+}
+
+func TestRenewLockReturnsLockRenewedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RenewLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return true, client.Lock{Key: key, Session: session}, nil
+	}
+
+	handler := RenewLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockRenewed", event.EventDef.Name)
+	assert.Equal(t, "session-id", event.Payload.(LockOutput).Lock.Session)
+}
+
+func TestRenewLockReturnsLockLostEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RenewLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{Key: key}, nil
+	}
+
+	handler := RenewLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "LockLost", event.EventDef.Name)
+}
+
+func TestRenewLockRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RenewLockFunc = func(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+		return false, client.Lock{}, fmt.Errorf("kablammo")
+	}
+
+	handler := RenewLock(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"key": "locks/deploy", "session": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to renew lock: kablammo", event.Payload)
+}
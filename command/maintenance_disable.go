@@ -0,0 +1,58 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var maintenanceDisabledEventDef = flyte.EventDef{Name: "MaintenanceDisabled"}
+
+//DisableMaintenance produces the DisableMaintenance flyte command.
+func DisableMaintenance(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "DisableMaintenance",
+		OutputEvents: []flyte.EventDef{
+			maintenanceDisabledEventDef,
+		},
+		Handler: disableMaintenanceHandler(consulClient),
+	}
+}
+
+func disableMaintenanceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := MaintenanceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if problem := input.checkTarget(); "" != problem {
+			return flyte.NewFatalEvent(problem)
+		}
+
+		if err := consulClient.DisableMaintenance(input.ServiceID); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to disable maintenance: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: maintenanceDisabledEventDef,
+			Payload: MaintenanceOutput{
+				Input: input,
+			},
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var maintenanceEnabledEventDef = flyte.EventDef{Name: "MaintenanceEnabled"}
+
+//MaintenanceInput represents the maintenance commands payload.
+//Maintenance applies to a service of the pack's consul agent, or to the agent's node when Node is set.
+//Only the agent the pack is connected to can be targeted, consul does not expose the maintenance of other agents.
+type MaintenanceInput struct {
+	ServiceID string `json:"serviceId,omitempty"`
+	Node      bool   `json:"node,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+//MaintenanceOutput represents the maintenance commands result payload.
+type MaintenanceOutput struct {
+	Input MaintenanceInput    `json:"input"`
+	Check *client.HealthCheck `json:"check,omitempty"`
+}
+
+//EnableMaintenance produces the EnableMaintenance flyte command.
+func EnableMaintenance(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "EnableMaintenance",
+		OutputEvents: []flyte.EventDef{
+			maintenanceEnabledEventDef,
+		},
+		Handler: enableMaintenanceHandler(consulClient),
+	}
+}
+
+func enableMaintenanceHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := MaintenanceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if problem := input.checkTarget(); "" != problem {
+			return flyte.NewFatalEvent(problem)
+		}
+
+		check, err := consulClient.EnableMaintenance(input.ServiceID, input.Reason)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to enable maintenance: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: maintenanceEnabledEventDef,
+			Payload: MaintenanceOutput{
+				Input: input,
+				Check: check,
+			},
+		}
+	}
+}
+
+//checkTarget returns the problem with the maintenance target, so that an empty input never targets the node.
+func (i MaintenanceInput) checkTarget() string {
+	switch {
+	case i.Node && "" != i.ServiceID:
+		return "only one of node and serviceId can be set"
+	case !i.Node && "" == i.ServiceID:
+		return "missing serviceId, or node set to true"
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	maintenanceActiveEventDef   = flyte.EventDef{Name: "MaintenanceActive"}
+	maintenanceInactiveEventDef = flyte.EventDef{Name: "MaintenanceInactive"}
+)
+
+//MaintenanceStatus produces the MaintenanceStatus flyte command.
+func MaintenanceStatus(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "MaintenanceStatus",
+		OutputEvents: []flyte.EventDef{
+			maintenanceActiveEventDef,
+			maintenanceInactiveEventDef,
+		},
+		Handler: maintenanceStatusHandler(consulClient),
+	}
+}
+
+func maintenanceStatusHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := MaintenanceInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if problem := input.checkTarget(); "" != problem {
+			return flyte.NewFatalEvent(problem)
+		}
+
+		check, err := consulClient.GetMaintenance(input.ServiceID)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to get maintenance status: %v", err))
+		}
+		if nil == check {
+			return flyte.Event{
+				EventDef: maintenanceInactiveEventDef,
+				Payload: MaintenanceOutput{
+					Input: input,
+				},
+			}
+		}
+
+		return flyte.Event{
+			EventDef: maintenanceActiveEventDef,
+			Payload: MaintenanceOutput{
+				Input: input,
+				Check: check,
+			},
+		}
+	}
+}
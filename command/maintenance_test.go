@@ -0,0 +1,191 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	enable := EnableMaintenance(KVTransactionMockConsul)
+	assert.Equal(t, "EnableMaintenance", enable.Name)
+	require.Equal(t, 1, len(enable.OutputEvents))
+	assert.Equal(t, "MaintenanceEnabled", enable.OutputEvents[0].Name)
+
+	disable := DisableMaintenance(KVTransactionMockConsul)
+	assert.Equal(t, "DisableMaintenance", disable.Name)
+	require.Equal(t, 1, len(disable.OutputEvents))
+	assert.Equal(t, "MaintenanceDisabled", disable.OutputEvents[0].Name)
+
+	status := MaintenanceStatus(KVTransactionMockConsul)
+	assert.Equal(t, "MaintenanceStatus", status.Name)
+	require.Equal(t, 2, len(status.OutputEvents))
+	assert.Equal(t, "MaintenanceActive", status.OutputEvents[0].Name)
+	assert.Equal(t, "MaintenanceInactive", status.OutputEvents[1].Name)
+}
+
+func TestEnableMaintenanceReturnsMaintenanceEnabledEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.EnableMaintenanceFunc = func(serviceID, reason string) (*client.HealthCheck, error) {
+		assert.Equal(t, "web-1", serviceID)
+		assert.Equal(t, "drain", reason)
+		return &client.HealthCheck{CheckID: "_service_maintenance:web-1", Status: "critical", Notes: reason}, nil
+	}
+
+	handler := EnableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"serviceId": "web-1", "reason": "drain"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "MaintenanceEnabled", event.EventDef.Name)
+	output := event.Payload.(MaintenanceOutput)
+	require.NotNil(t, output.Check)
+	assert.Equal(t, "critical", output.Check.Status)
+	assert.Equal(t, "drain", output.Check.Notes)
+}
+
+func TestEnableMaintenanceRequiresTarget(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := EnableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"reason": "incident"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing serviceId, or node set to true", event.Payload)
+
+	event = handler([]byte(`{"node": true, "serviceId": "web-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "only one of node and serviceId can be set", event.Payload)
+}
+
+func TestEnableMaintenanceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.EnableMaintenanceFunc = func(serviceID, reason string) (*client.HealthCheck, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := EnableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": true, "reason": "incident"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to enable maintenance: kablammo", event.Payload)
+}
+
+func TestDisableMaintenanceReturnsMaintenanceDisabledEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DisableMaintenanceFunc = func(serviceID string) error {
+		assert.Equal(t, "", serviceID)
+		return nil
+	}
+
+	handler := DisableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "MaintenanceDisabled", event.EventDef.Name)
+}
+
+func TestDisableMaintenanceRequiresTarget(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := DisableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing serviceId, or node set to true", event.Payload)
+}
+
+func TestDisableMaintenanceRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DisableMaintenanceFunc = func(serviceID string) error {
+		return fmt.Errorf("kablammo")
+	}
+
+	handler := DisableMaintenance(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to disable maintenance: kablammo", event.Payload)
+}
+
+func TestMaintenanceStatusReturnsMaintenanceActiveEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetMaintenanceFunc = func(serviceID string) (*client.HealthCheck, error) {
+		return &client.HealthCheck{CheckID: "_node_maintenance", Status: "critical"}, nil
+	}
+
+	handler := MaintenanceStatus(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "MaintenanceActive", event.EventDef.Name)
+	assert.Equal(t, "_node_maintenance", event.Payload.(MaintenanceOutput).Check.CheckID)
+}
+
+func TestMaintenanceStatusReturnsMaintenanceInactiveEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetMaintenanceFunc = func(serviceID string) (*client.HealthCheck, error) {
+		return nil, nil
+	}
+
+	handler := MaintenanceStatus(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"serviceId": "web-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "MaintenanceInactive", event.EventDef.Name)
+	assert.Nil(t, event.Payload.(MaintenanceOutput).Check)
+}
+
+func TestMaintenanceStatusRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetMaintenanceFunc = func(serviceID string) (*client.HealthCheck, error) {
+		return nil, fmt.Errorf("kablammo")
+	}
+
+	handler := MaintenanceStatus(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": true}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to get maintenance status: kablammo", event.Payload)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+//TokenRegistry maps the token references commands accept to the ACL tokens they stand for,
+//so that tokens never appear in command inputs or events.
+type TokenRegistry map[string]string
+
+//RequestOptions represents the ACL token reference, namespace and partition of a command.
+//Namespace and Partition are Consul Enterprise features.
+type RequestOptions struct {
+	Token     string `json:"token,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Partition string `json:"partition,omitempty"`
+}
+
+//writeOptions resolves the token reference and returns the write options of the request.
+func (o RequestOptions) writeOptions(datacenter string, tokens TokenRegistry) (client.WriteOptions, error) {
+	token, err := tokens.resolve(o.Token)
+	if nil != err {
+		return client.WriteOptions{}, err
+	}
+	return client.WriteOptions{
+		Datacenter: datacenter,
+		Token:      token,
+		Namespace:  o.Namespace,
+		Partition:  o.Partition,
+	}, nil
+}
+
+//queryOptions resolves the token reference and returns the query options of the request.
+func (o RequestOptions) queryOptions(datacenter string, tokens TokenRegistry) (client.QueryOptions, error) {
+	token, err := tokens.resolve(o.Token)
+	if nil != err {
+		return client.QueryOptions{}, err
+	}
+	return client.QueryOptions{
+		Datacenter: datacenter,
+		Token:      token,
+		Namespace:  o.Namespace,
+		Partition:  o.Partition,
+	}, nil
+}
+
+//resolve returns the token of the reference, an empty reference being the token of the pack.
+func (r TokenRegistry) resolve(reference string) (string, error) {
+	if "" == reference {
+		return "", nil
+	}
+	token, ok := r[reference]
+	if !ok {
+		return "", fmt.Errorf("token %v is not registered", reference)
+	}
+	return token, nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestOptionsWriteOptions(t *testing.T) {
+	tokens := TokenRegistry{"deploy": "secret"}
+
+	options, err := RequestOptions{Token: "deploy", Namespace: "team", Partition: "part"}.writeOptions("dc2", tokens)
+	require.Nil(t, err)
+	assert.Equal(t, client.WriteOptions{Datacenter: "dc2", Token: "secret", Namespace: "team", Partition: "part"}, options)
+
+	options, err = RequestOptions{}.writeOptions("", nil)
+	require.Nil(t, err)
+	assert.Equal(t, client.WriteOptions{}, options)
+
+	_, err = RequestOptions{Token: "admin"}.writeOptions("", tokens)
+	assert.EqualError(t, err, "token admin is not registered")
+}
+
+func TestRequestOptionsQueryOptions(t *testing.T) {
+	tokens := TokenRegistry{"deploy": "secret"}
+
+	options, err := RequestOptions{Token: "deploy", Namespace: "team", Partition: "part"}.queryOptions("dc2", tokens)
+	require.Nil(t, err)
+	assert.Equal(t, client.QueryOptions{Datacenter: "dc2", Token: "secret", Namespace: "team", Partition: "part"}, options)
+
+	_, err = RequestOptions{Token: "admin"}.queryOptions("", tokens)
+	assert.EqualError(t, err, "token admin is not registered")
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+)
+
+//Policy rules that can be violated.
+const (
+	ruleAllowedPrefixes = "allowedPrefixes"
+	ruleDeniedPrefixes  = "deniedPrefixes"
+	ruleVerbs           = "verbs"
+	ruleDatacenters     = "datacenters"
+)
+
+//Policy restricts the keys, verbs and datacenters of commands, by command name.
+//Commands without rules are not restricted.
+type Policy map[string]PolicyRules
+
+//PolicyRules are the restrictions of a command, empty lists do not restrict anything.
+//Denied prefixes take precedence over allowed prefixes. The datacenter of the consul agent,
+//used when no datacenter is set, is always allowed.
+type PolicyRules struct {
+	AllowedPrefixes []string `json:"allowedPrefixes,omitempty"`
+	DeniedPrefixes  []string `json:"deniedPrefixes,omitempty"`
+	Verbs           []string `json:"verbs,omitempty"`
+	Datacenters     []string `json:"datacenters,omitempty"`
+}
+
+//policyCommands are the names of the commands enforcing the policy.
+var policyCommands = []string{
+	transactKVCommandName,
+	transactCommandName,
+	getKVCommandName,
+	putKVCommandName,
+	deleteKVCommandName,
+	acquireLockCommandName,
+	releaseLockCommandName,
+	renewLockCommandName,
+	acquireSemaphoreCommandName,
+	releaseSemaphoreCommandName,
+	campaignLeadershipCommandName,
+	resignLeadershipCommandName,
+}
+
+//PolicyViolation names the rule an operation, or the command when Index is not set, violates.
+type PolicyViolation struct {
+	Index *int   `json:"index,omitempty"`
+	Rule  string `json:"rule"`
+	Error string `json:"error"`
+}
+
+//PolicyDeniedOutput represents the payload of a command rejected by the policy.
+type PolicyDeniedOutput struct {
+	Input      interface{}       `json:"input"`
+	Violations []PolicyViolation `json:"violations"`
+}
+
+//Validate returns the command names of the policy that do not enforce it.
+func (p Policy) Validate() []string {
+	errors := []string{}
+	for command := range p {
+		if !contains(policyCommands, command) {
+			errors = append(errors, fmt.Sprintf("%v does not enforce the policy", command))
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+//checkDatacenter returns the violation of the datacenter rule of the command, if any.
+func (p Policy) checkDatacenter(command, datacenter string) *PolicyViolation {
+	rules := p[command]
+	if "" == datacenter || 0 == len(rules.Datacenters) || contains(rules.Datacenters, datacenter) {
+		return nil
+	}
+	return &PolicyViolation{
+		Rule:  ruleDatacenters,
+		Error: fmt.Sprintf("datacenter %v is not allowed", datacenter),
+	}
+}
+
+//checkKV returns the violation of the rules of the command by a key-value operation, if any.
+//Tree operations also violate denied prefixes below their key.
+func (p Policy) checkKV(command string, index int, verb, key string, tree bool) *PolicyViolation {
+	violation := p.checkKey(command, verb, key, tree)
+	if nil != violation {
+		violation.Index = &index
+	}
+	return violation
+}
+
+//checkRequest returns the violations of the rules of the command by a request on a single key or key prefix.
+func (p Policy) checkRequest(command, datacenter, verb, key string, tree bool) []PolicyViolation {
+	violations := []PolicyViolation{}
+	if violation := p.checkDatacenter(command, datacenter); nil != violation {
+		violations = append(violations, *violation)
+	}
+	if violation := p.checkKey(command, verb, key, tree); nil != violation {
+		violations = append(violations, *violation)
+	}
+	return violations
+}
+
+func (p Policy) checkKey(command, verb, key string, tree bool) *PolicyViolation {
+	rules := p[command]
+	violation := func(rule, format string, a ...interface{}) *PolicyViolation {
+		return &PolicyViolation{Rule: rule, Error: fmt.Sprintf(format, a...)}
+	}
+
+	if 0 != len(rules.Verbs) && !contains(rules.Verbs, verb) {
+		return violation(ruleVerbs, "verb %v is not allowed", verb)
+	}
+	for _, prefix := range rules.DeniedPrefixes {
+		if strings.HasPrefix(key, prefix) || (tree && strings.HasPrefix(prefix, key)) {
+			return violation(ruleDeniedPrefixes, "key %v is denied by prefix %v", key, prefix)
+		}
+	}
+	if 0 == len(rules.AllowedPrefixes) {
+		return nil
+	}
+	for _, prefix := range rules.AllowedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return nil
+		}
+	}
+	return violation(ruleAllowedPrefixes, "key %v is not under an allowed prefix", key)
+}
+
+func newPolicyDeniedEvent(eventDef flyte.EventDef, input interface{}, violations []PolicyViolation) flyte.Event {
+	return flyte.Event{
+		EventDef: eventDef,
+		Payload: PolicyDeniedOutput{
+			Input:      input,
+			Violations: violations,
+		},
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheckKV(t *testing.T) {
+	policy := Policy{"TransactKV": {
+		AllowedPrefixes: []string{"config/"},
+		DeniedPrefixes:  []string{"config/secret/"},
+		Verbs:           []string{"get", "set", "delete-tree"},
+	}}
+
+	assert.Nil(t, policy.checkKV("TransactKV", 0, "set", "config/app", false))
+	assert.Nil(t, policy.checkKV("GetKV", 0, "delete", "secret/app", false))
+
+	violation := policy.checkKV("TransactKV", 2, "delete", "config/app", false)
+	require.NotNil(t, violation)
+	assert.Equal(t, 2, *violation.Index)
+	assert.Equal(t, "verbs", violation.Rule)
+	assert.Equal(t, "verb delete is not allowed", violation.Error)
+
+	violation = policy.checkKV("TransactKV", 0, "set", "config/secret/db", false)
+	require.NotNil(t, violation)
+	assert.Equal(t, "deniedPrefixes", violation.Rule)
+	assert.Equal(t, "key config/secret/db is denied by prefix config/secret/", violation.Error)
+
+	violation = policy.checkKV("TransactKV", 0, "delete-tree", "config/", true)
+	require.NotNil(t, violation)
+	assert.Equal(t, "deniedPrefixes", violation.Rule)
+
+	violation = policy.checkKV("TransactKV", 0, "get", "other/app", false)
+	require.NotNil(t, violation)
+	assert.Equal(t, "allowedPrefixes", violation.Rule)
+	assert.Equal(t, "key other/app is not under an allowed prefix", violation.Error)
+}
+
+func TestPolicyCheckDatacenter(t *testing.T) {
+	policy := Policy{"TransactKV": {Datacenters: []string{"dc1"}}}
+
+	assert.Nil(t, policy.checkDatacenter("TransactKV", ""))
+	assert.Nil(t, policy.checkDatacenter("TransactKV", "dc1"))
+	assert.Nil(t, policy.checkDatacenter("GetKV", "dc2"))
+
+	violation := policy.checkDatacenter("TransactKV", "dc2")
+	require.NotNil(t, violation)
+	assert.Nil(t, violation.Index)
+	assert.Equal(t, "datacenters", violation.Rule)
+	assert.Equal(t, "datacenter dc2 is not allowed", violation.Error)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.Empty(t, Policy{"TransactKV": {}, "GetKV": {}, "CampaignLeadership": {}, "ReleaseLock": {}, "ReleaseSemaphore": {}}.Validate())
+	assert.Equal(t, []string{"FireEvent does not enforce the policy", "TransactKv does not enforce the policy"}, Policy{"TransactKv": {}, "FireEvent": {}}.Validate())
+}
@@ -0,0 +1,315 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var redactedValue = json.RawMessage(`"[REDACTED]"`)
+
+//Redaction defines the values removed from the events of commands.
+//Values of keys matching one of KeyPatterns are replaced in the events of TransactKV, Transact, GetKV and PutKV,
+//as are the values of sensitive key-value operations, in the echoed input, the results and the error messages.
+//OmitInputValues removes every value from the echoed TransactKV and Transact input.
+type Redaction struct {
+	KeyPatterns     []*regexp.Regexp
+	OmitInputValues bool
+}
+
+//redactTransactKV wraps the TransactKV handler to redact the events it emits.
+func (r Redaction) redactTransactKV(handler func(json.RawMessage) flyte.Event) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		event := handler(rawInput)
+
+		// the input may not be valid, in which case no operation is known to be sensitive
+		input := TransactKVInput{}
+		json.Unmarshal(rawInput, &input)
+
+		switch payload := event.Payload.(type) {
+		case TransactKVResultOutput:
+			payload.Input = r.input(input)
+			payload.Results = r.results(input, payload.Results)
+			event.Payload = payload
+		case TransactKVErrorOutput:
+			payload.Input = r.input(input)
+			payload.Errors = r.errors(input, payload.Errors)
+			event.Payload = payload
+		case TransactKVPartialOutput:
+			payload.Input = r.input(input)
+			payload.Results = r.results(input, payload.Results)
+			payload.Errors = r.errors(input, payload.Errors)
+			payload.CompensationErrors = r.messages(input, payload.CompensationErrors)
+			event.Payload = payload
+		case TransactKVPlanOutput:
+			payload.Input = r.input(input)
+			payload.Operations = r.plannedOperations(input, payload.Operations)
+			payload.Errors = r.errors(input, payload.Errors)
+			event.Payload = payload
+		case TransactKVDeniedOutput:
+			payload.Input = r.input(input)
+			event.Payload = payload
+		case TransactKVFailureOutput:
+			payload.Input = r.input(input)
+			payload.Error = r.message(input, payload.Error)
+			event.Payload = payload
+		case string:
+			event.Payload = r.message(input, payload)
+		}
+		return event
+	}
+}
+
+//redactTransact wraps the Transact handler to redact the key-value operations the same way as TransactKV.
+func (r Redaction) redactTransact(handler func(json.RawMessage) flyte.Event) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		event := handler(rawInput)
+
+		// the input may not be valid, in which case no operation is known to be sensitive
+		input := TransactInput{}
+		json.Unmarshal(rawInput, &input)
+		kvInput := TransactKVInput{}
+		for _, operation := range input.Operations {
+			if nil != operation.KV {
+				kvInput.Operations = append(kvInput.Operations, *operation.KV)
+			}
+		}
+
+		switch payload := event.Payload.(type) {
+		case TransactResultOutput:
+			payload.Input = r.transactInput(input, kvInput)
+			payload.Results = r.txnResults(kvInput, payload.Results)
+			event.Payload = payload
+		case TransactErrorOutput:
+			payload.Input = r.transactInput(input, kvInput)
+			payload.Errors = r.txnErrors(kvInput, payload.Errors)
+			event.Payload = payload
+		case PolicyDeniedOutput:
+			payload.Input = r.transactInput(input, kvInput)
+			event.Payload = payload
+		case string:
+			event.Payload = r.message(kvInput, payload)
+		}
+		return event
+	}
+}
+
+//redactGetKV wraps the GetKV handler to redact the values of the keys matching a pattern.
+func (r Redaction) redactGetKV(handler func(json.RawMessage) flyte.Event) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		event := handler(rawInput)
+		if payload, ok := event.Payload.(GetKVOutput); ok && 0 != len(payload.Pairs) {
+			pairs := make([]client.KVPair, len(payload.Pairs))
+			for index, pair := range payload.Pairs {
+				if r.matches(pair.Key) {
+					pair = *redactPair(&pair)
+				}
+				pairs[index] = pair
+			}
+			payload.Pairs = pairs
+			event.Payload = payload
+		}
+		return event
+	}
+}
+
+//redactPutKV wraps the PutKV handler to redact the echoed value of a key matching a pattern.
+func (r Redaction) redactPutKV(handler func(json.RawMessage) flyte.Event) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		event := handler(rawInput)
+
+		input := PutKVInput{}
+		json.Unmarshal(rawInput, &input)
+		if 0 == len(input.Value) || !r.matches(input.Key) {
+			return event
+		}
+		redacted := input
+		redacted.Value = redactedValue
+
+		switch payload := event.Payload.(type) {
+		case PutKVOutput:
+			payload.Input = redacted
+			event.Payload = payload
+		case PutKVErrorOutput:
+			payload.Input = redacted
+			event.Payload = payload
+		case PolicyDeniedOutput:
+			payload.Input = redacted
+			event.Payload = payload
+		case string:
+			event.Payload = redactQuoted(payload, input.Value)
+		}
+		return event
+	}
+}
+
+//matches returns whether the key matches one of the patterns.
+func (r Redaction) matches(key string) bool {
+	for _, pattern := range r.KeyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+//isSensitive returns whether the value of the key is redacted: the key matches a pattern,
+//is the key of a sensitive operation or is below the key of a sensitive tree operation.
+func (r Redaction) isSensitive(input TransactKVInput, key string) bool {
+	if r.matches(key) {
+		return true
+	}
+	for _, operation := range input.Operations {
+		tree := "get-tree" == operation.Verb || "delete-tree" == operation.Verb
+		if operation.Sensitive && (key == operation.Key || (tree && strings.HasPrefix(key, operation.Key))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Redaction) input(input TransactKVInput) TransactKVInput {
+	operations := make([]client.KVOperation, len(input.Operations))
+	for index, operation := range input.Operations {
+		if 0 != len(operation.Value) && (r.OmitInputValues || r.isSensitive(input, operation.Key)) {
+			operation.Value = nil
+			if !r.OmitInputValues {
+				operation.Value = redactedValue
+			}
+		}
+		operations[index] = operation
+	}
+	input.Operations = operations
+	return input
+}
+
+func (r Redaction) transactInput(input TransactInput, kvInput TransactKVInput) TransactInput {
+	operations := make([]client.TxnOperation, len(input.Operations))
+	for index, operation := range input.Operations {
+		if nil != operation.KV && 0 != len(operation.KV.Value) && (r.OmitInputValues || r.isSensitive(kvInput, operation.KV.Key)) {
+			kv := *operation.KV
+			kv.Value = nil
+			if !r.OmitInputValues {
+				kv.Value = redactedValue
+			}
+			operation.KV = &kv
+		}
+		operations[index] = operation
+	}
+	input.Operations = operations
+	return input
+}
+
+func (r Redaction) txnResults(input TransactKVInput, results []client.TxnResult) []client.TxnResult {
+	retval := make([]client.TxnResult, len(results))
+	for index, result := range results {
+		if nil != result.KV && r.isSensitive(input, result.KV.Key) {
+			result.KV = redactPair(result.KV)
+		}
+		retval[index] = result
+	}
+	return retval
+}
+
+func (r Redaction) txnErrors(input TransactKVInput, errors []client.TxnError) []client.TxnError {
+	retval := make([]client.TxnError, len(errors))
+	for index, err := range errors {
+		err.Error = r.message(input, err.Error)
+		retval[index] = err
+	}
+	return retval
+}
+
+func (r Redaction) results(input TransactKVInput, results []client.KVTransactionResult) []client.KVTransactionResult {
+	retval := make([]client.KVTransactionResult, len(results))
+	for index, result := range results {
+		if 0 != len(result.Value) && r.isSensitive(input, result.Key) {
+			result.Value = redactedValue
+		}
+		retval[index] = result
+	}
+	return retval
+}
+
+func (r Redaction) plannedOperations(input TransactKVInput, operations []client.KVPlannedOperation) []client.KVPlannedOperation {
+	retval := make([]client.KVPlannedOperation, len(operations))
+	for index, operation := range operations {
+		if r.isSensitive(input, operation.Key) {
+			operation.Before = redactPair(operation.Before)
+			operation.After = redactPair(operation.After)
+		}
+		retval[index] = operation
+	}
+	return retval
+}
+
+func redactPair(pair *client.KVPair) *client.KVPair {
+	if nil == pair || 0 == len(pair.Value) {
+		return pair
+	}
+	retval := *pair
+	retval.Value = redactedValue
+	return &retval
+}
+
+func (r Redaction) errors(input TransactKVInput, errors []client.KVTransactionError) []client.KVTransactionError {
+	retval := make([]client.KVTransactionError, len(errors))
+	for index, err := range errors {
+		err.Error = r.message(input, err.Error)
+		retval[index] = err
+	}
+	return retval
+}
+
+func (r Redaction) messages(input TransactKVInput, messages []string) []string {
+	if nil == messages {
+		return nil
+	}
+	retval := make([]string, len(messages))
+	for index, message := range messages {
+		retval[index] = r.message(input, message)
+	}
+	return retval
+}
+
+//message replaces the redacted input values appearing quoted in the message.
+func (r Redaction) message(input TransactKVInput, message string) string {
+	for _, operation := range input.Operations {
+		if 0 == len(operation.Value) || !(r.OmitInputValues || r.isSensitive(input, operation.Key)) {
+			continue
+		}
+		message = redactQuoted(message, operation.Value)
+	}
+	return message
+}
+
+//redactQuoted replaces the value where it appears as a quoted string, the content of string values
+//and the JSON text of other values, leaving the rest of the message intact when the value is short
+// (e.g. 1 in "current modify index 1 does not match").
+func redactQuoted(message string, value json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(value, &text); nil != err {
+		text = string(value)
+	}
+	if "" == text {
+		return message
+	}
+	quoted, _ := json.Marshal(text)
+	return strings.ReplaceAll(message, string(quoted), string(redactedValue))
+}
@@ -0,0 +1,259 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactKVRedactsSensitiveOperations(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		assert.Equal(t, `"hunter2"`, string(operations[0].Value))
+		return []client.KVTransactionResult{
+			{Index: 0, Key: "app/password", Value: []byte(`"hunter2"`)},
+			{Index: 1, Key: "app/name", Value: []byte(`"joe"`)},
+		}, nil, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [
+		{"verb": "set", "key": "app/password", "value": "hunter2", "sensitive": true},
+		{"verb": "set", "key": "app/name", "value": "joe"}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	output := event.Payload.(TransactKVResultOutput)
+	assert.Equal(t, `"[REDACTED]"`, string(output.Input.Operations[0].Value))
+	assert.Equal(t, `"joe"`, string(output.Input.Operations[1].Value))
+	assert.Equal(t, `"[REDACTED]"`, string(output.Results[0].Value))
+	assert.Equal(t, `"joe"`, string(output.Results[1].Value))
+}
+
+func TestTransactKVRedactsKeyPatterns(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return []client.KVTransactionResult{
+			{Index: 0, Key: "app/secret/db", Value: []byte(`"hunter2"`)},
+			{Index: 0, Key: "app/name", Value: []byte(`"joe"`)},
+		}, nil, nil
+	}
+
+	redaction := Redaction{KeyPatterns: []*regexp.Regexp{regexp.MustCompile(`/secret/`)}}
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, redaction).Handler
+	event := handler([]byte(`{"operations": [{"verb": "get-tree", "key": "app/"}]}`))
+
+	require.NotNil(t, event)
+	output := event.Payload.(TransactKVResultOutput)
+	assert.Equal(t, `"[REDACTED]"`, string(output.Results[0].Value))
+	assert.Equal(t, `"joe"`, string(output.Results[1].Value))
+}
+
+func TestTransactKVOmitsInputValues(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return nil, []client.KVTransactionError{{Index: 0, Error: `failed to set "joe"`}}, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{OmitInputValues: true}).Handler
+	event := handler([]byte(`{"operations": [{"verb": "set", "key": "app/name", "value": "joe"}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	output := event.Payload.(TransactKVErrorOutput)
+	assert.Nil(t, output.Input.Operations[0].Value)
+	assert.Equal(t, `failed to set "[REDACTED]"`, output.Errors[0].Error)
+}
+
+func TestTransactKVRedactsFatalMessages(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return nil, nil, fmt.Errorf(`cannot store "hunter2"`)
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"verb": "set", "key": "app/password", "value": "hunter2", "sensitive": true}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, `failed to make transaction request: cannot store "[REDACTED]"`, event.Payload)
+}
+
+func TestTransactKVRedactsOnlyQuotedValues(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	KVTransactionMockConsul.KVTransactFunc = func(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+		return nil, []client.KVTransactionError{{Index: 0, Error: `current modify index 1 does not match, value "1" rejected`}}, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"verb": "set", "key": "app/pin", "value": 1, "sensitive": true}]}`))
+
+	require.NotNil(t, event)
+	output := event.Payload.(TransactKVErrorOutput)
+	assert.Equal(t, `current modify index 1 does not match, value "[REDACTED]" rejected`, output.Errors[0].Error)
+}
+
+func TestTransactRedactsKVOperations(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		assert.Equal(t, `"hunter2"`, string(operations[0].KV.Value))
+		return []client.TxnResult{
+			{Index: 0, Type: "kv", KV: &client.KVPair{Key: "app/password", Value: []byte(`"hunter2"`)}},
+			{Index: 1, Type: "kv", KV: &client.KVPair{Key: "app/secret/db", Value: []byte(`"s3cr3t"`)}},
+			{Index: 2, Type: "node", Node: &client.Node{Node: "node-1"}},
+		}, nil, nil
+	}
+
+	redaction := Redaction{KeyPatterns: []*regexp.Regexp{regexp.MustCompile(`/secret/`)}}
+	handler := Transact(KVTransactionMockConsul, nil, redaction).Handler
+	event := handler([]byte(`{"operations": [
+		{"kv": {"verb": "set", "key": "app/password", "value": "hunter2", "sensitive": true}},
+		{"kv": {"verb": "get", "key": "app/secret/db"}},
+		{"node": {"verb": "get", "node": "node-1"}}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	output := event.Payload.(TransactResultOutput)
+	assert.Equal(t, `"[REDACTED]"`, string(output.Input.Operations[0].KV.Value))
+	assert.Equal(t, `"[REDACTED]"`, string(output.Results[0].KV.Value))
+	assert.Equal(t, `"[REDACTED]"`, string(output.Results[1].KV.Value))
+	assert.Equal(t, "node-1", output.Results[2].Node.Node)
+}
+
+func TestTransactRedactsRollbackAndDeniedEvents(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		return nil, []client.TxnError{{Index: 0, Error: `cannot store "hunter2"`}}, nil
+	}
+
+	redaction := Redaction{OmitInputValues: true}
+	handler := Transact(KVTransactionMockConsul, nil, redaction).Handler
+	event := handler([]byte(`{"operations": [{"kv": {"verb": "set", "key": "app/name", "value": "hunter2"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	output := event.Payload.(TransactErrorOutput)
+	assert.Nil(t, output.Input.Operations[0].KV.Value)
+	assert.Equal(t, `cannot store "[REDACTED]"`, output.Errors[0].Error)
+
+	policy := Policy{"Transact": {AllowedPrefixes: []string{"config/"}}}
+	handler = Transact(KVTransactionMockConsul, policy, redaction).Handler
+	event = handler([]byte(`{"operations": [{"kv": {"verb": "set", "key": "app/name", "value": "hunter2"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionDenied", event.EventDef.Name)
+	denied := event.Payload.(PolicyDeniedOutput)
+	assert.Nil(t, denied.Input.(TransactInput).Operations[0].KV.Value)
+}
+
+func TestGetKVRedactsMatchingKeys(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListKVFunc = func(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+		return []client.KVPair{
+			{Key: "app/name", Value: []byte(`"joe"`)},
+			{Key: "app/password", Value: []byte(`"hunter2"`)},
+		}, nil
+	}
+
+	redaction := Redaction{KeyPatterns: []*regexp.Regexp{regexp.MustCompile("password$")}}
+	handler := GetKV(KVTransactionMockConsul, nil, redaction).Handler
+	event := handler([]byte(`{"key": "app/", "recurse": true}`))
+
+	require.NotNil(t, event)
+	output := event.Payload.(GetKVOutput)
+	require.Equal(t, 2, len(output.Pairs))
+	assert.Equal(t, `"joe"`, string(output.Pairs[0].Value))
+	assert.Equal(t, `"[REDACTED]"`, string(output.Pairs[1].Value))
+}
+
+func TestPutKVRedactsMatchingKey(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.PutKVFunc = func(put client.KVPut, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, `"hunter2"`, string(put.Value))
+		return false, nil
+	}
+
+	redaction := Redaction{KeyPatterns: []*regexp.Regexp{regexp.MustCompile("password$")}}
+	handler := PutKV(KVTransactionMockConsul, nil, redaction).Handler
+	event := handler([]byte(`{"key": "app/password", "value": "hunter2", "cas": 3}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "KVCASConflict", event.EventDef.Name)
+	assert.Equal(t, `"[REDACTED]"`, string(event.Payload.(PutKVErrorOutput).Input.Value))
+}
+
+func TestTransactKVRedactsPlannedValues(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.IsVerbSupportedFunc = func(verb string) bool {
+		return true
+	}
+	before := &client.KVPair{Key: "app/password", Value: []byte(`"hunter1"`)}
+	KVTransactionMockConsul.KVTransactPlanFunc = func(operations []client.KVOperation, options client.WriteOptions) (*client.KVTransactionPlan, error) {
+		return &client.KVTransactionPlan{
+			Operations: []client.KVPlannedOperation{{Key: "app/password", Before: before, After: &client.KVPair{Key: "app/password", Value: []byte(`"hunter2"`)}}},
+		}, nil
+	}
+
+	handler := TransactKV(KVTransactionMockConsul, client.RetryPolicy{}, nil, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dryRun": true, "operations": [{"verb": "set", "key": "app/password", "value": "hunter2", "sensitive": true}]}`))
+
+	require.NotNil(t, event)
+	output := event.Payload.(TransactKVPlanOutput)
+	assert.Equal(t, `"[REDACTED]"`, string(output.Operations[0].Before.Value))
+	assert.Equal(t, `"[REDACTED]"`, string(output.Operations[0].After.Value))
+	assert.Equal(t, `"hunter1"`, string(before.Value))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	semaphoreAcquiredEventDef = flyte.EventDef{Name: "SemaphoreAcquired"}
+	semaphoreFullEventDef     = flyte.EventDef{Name: "SemaphoreFull"}
+	semaphoreDeniedEventDef   = flyte.EventDef{Name: "SemaphoreDenied"}
+)
+
+const acquireSemaphoreCommandName = "AcquireSemaphore"
+
+//AcquireSemaphoreInput represents the AcquireSemaphore command payload.
+type AcquireSemaphoreInput struct {
+	Datacenter string                `json:"dc"`
+	Prefix     string                `json:"prefix"`
+	Limit      int                   `json:"limit"`
+	Value      json.RawMessage       `json:"value,omitempty"`
+	Session    client.SessionRequest `json:"session"`
+}
+
+//AcquireSemaphoreOutput represents the AcquireSemaphore result payload.
+type AcquireSemaphoreOutput struct {
+	Input     AcquireSemaphoreInput `json:"input"`
+	Semaphore client.Semaphore      `json:"semaphore"`
+}
+
+//AcquireSemaphore produces the AcquireSemaphore flyte command.
+//Semaphores violating the AcquireSemaphore rules of the policy are rejected,
+//checked as the lock verb on the keys under the prefix.
+func AcquireSemaphore(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: acquireSemaphoreCommandName,
+		OutputEvents: []flyte.EventDef{
+			semaphoreAcquiredEventDef,
+			semaphoreFullEventDef,
+			semaphoreDeniedEventDef,
+		},
+		Handler: acquireSemaphoreHandler(consulClient, policy),
+	}
+}
+
+func acquireSemaphoreHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := AcquireSemaphoreInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Prefix {
+			return flyte.NewFatalEvent("missing prefix")
+		}
+		if 0 >= input.Limit {
+			return flyte.NewFatalEvent("limit must be greater than 0")
+		}
+		if errors := input.Session.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("session is not valid: %v", strings.Join(errors, ", ")))
+		}
+		if violations := policy.checkRequest(acquireSemaphoreCommandName, input.Datacenter, "lock", input.Prefix, true); 0 != len(violations) {
+			return newPolicyDeniedEvent(semaphoreDeniedEventDef, input, violations)
+		}
+
+		request := client.SemaphoreRequest{
+			Prefix:  input.Prefix,
+			Limit:   input.Limit,
+			Value:   input.Value,
+			Session: input.Session,
+		}
+		ok, semaphore, err := consulClient.AcquireSemaphore(request, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to acquire semaphore: %v", err))
+		}
+
+		eventDef := semaphoreAcquiredEventDef
+		if !ok {
+			eventDef = semaphoreFullEventDef
+		}
+		return flyte.Event{
+			EventDef: eventDef,
+			Payload: AcquireSemaphoreOutput{
+				Input:     input,
+				Semaphore: semaphore,
+			},
+		}
+	}
+}
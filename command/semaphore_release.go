@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	semaphoreReleasedEventDef = flyte.EventDef{Name: "SemaphoreReleased"}
+	semaphoreNotHeldEventDef  = flyte.EventDef{Name: "SemaphoreNotHeld"}
+)
+
+const releaseSemaphoreCommandName = "ReleaseSemaphore"
+
+//ReleaseSemaphoreInput represents the ReleaseSemaphore command payload.
+type ReleaseSemaphoreInput struct {
+	Datacenter string `json:"dc"`
+	Prefix     string `json:"prefix"`
+	Session    string `json:"session"`
+}
+
+//ReleaseSemaphoreOutput represents the ReleaseSemaphore result payload.
+type ReleaseSemaphoreOutput struct {
+	Input     ReleaseSemaphoreInput `json:"input"`
+	Semaphore client.Semaphore      `json:"semaphore"`
+}
+
+//ReleaseSemaphore produces the ReleaseSemaphore flyte command.
+//Releases violating the ReleaseSemaphore rules of the policy are rejected, checked as the delete verb
+//on the keys under the prefix.
+func ReleaseSemaphore(consulClient client.Consul, policy Policy) flyte.Command {
+	return flyte.Command{
+		Name: releaseSemaphoreCommandName,
+		OutputEvents: []flyte.EventDef{
+			semaphoreReleasedEventDef,
+			semaphoreNotHeldEventDef,
+			semaphoreDeniedEventDef,
+		},
+		Handler: releaseSemaphoreHandler(consulClient, policy),
+	}
+}
+
+func releaseSemaphoreHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ReleaseSemaphoreInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.Prefix {
+			return flyte.NewFatalEvent("missing prefix")
+		}
+		if "" == input.Session {
+			return flyte.NewFatalEvent("missing session")
+		}
+		if violations := policy.checkRequest(releaseSemaphoreCommandName, input.Datacenter, "delete", input.Prefix, true); 0 != len(violations) {
+			return newPolicyDeniedEvent(semaphoreDeniedEventDef, input, violations)
+		}
+
+		ok, semaphore, err := consulClient.ReleaseSemaphore(input.Prefix, input.Session, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to release semaphore: %v", err))
+		}
+
+		eventDef := semaphoreReleasedEventDef
+		if !ok {
+			eventDef = semaphoreNotHeldEventDef
+		}
+		return flyte.Event{
+			EventDef: eventDef,
+			Payload: ReleaseSemaphoreOutput{
+				Input:     input,
+				Semaphore: semaphore,
+			},
+		}
+	}
+}
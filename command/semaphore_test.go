@@ -0,0 +1,184 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphoreCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	acquire := AcquireSemaphore(KVTransactionMockConsul, nil)
+	assert.Equal(t, "AcquireSemaphore", acquire.Name)
+	require.Equal(t, 3, len(acquire.OutputEvents))
+	assert.Equal(t, "SemaphoreAcquired", acquire.OutputEvents[0].Name)
+	assert.Equal(t, "SemaphoreFull", acquire.OutputEvents[1].Name)
+	assert.Equal(t, "SemaphoreDenied", acquire.OutputEvents[2].Name)
+
+	release := ReleaseSemaphore(KVTransactionMockConsul, nil)
+	assert.Equal(t, "ReleaseSemaphore", release.Name)
+	require.Equal(t, 3, len(release.OutputEvents))
+	assert.Equal(t, "SemaphoreReleased", release.OutputEvents[0].Name)
+	assert.Equal(t, "SemaphoreNotHeld", release.OutputEvents[1].Name)
+	assert.Equal(t, "SemaphoreDenied", release.OutputEvents[2].Name)
+}
+
+func TestAcquireSemaphoreReturnsSemaphoreAcquiredEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireSemaphoreFunc = func(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error) {
+		assert.Equal(t, "deploys", request.Prefix)
+		assert.Equal(t, 2, request.Limit)
+		return true, client.Semaphore{Prefix: request.Prefix, Session: "a", Limit: 2, Holders: []string{"a"}}, nil
+	}
+
+	handler := AcquireSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys", "limit": 2}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreAcquired", event.EventDef.Name)
+	assert.Equal(t, "a", event.Payload.(AcquireSemaphoreOutput).Semaphore.Session)
+}
+
+func TestAcquireSemaphoreReturnsSemaphoreFullEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireSemaphoreFunc = func(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error) {
+		return false, client.Semaphore{Prefix: request.Prefix, Limit: 1, Holders: []string{"a"}}, nil
+	}
+
+	handler := AcquireSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys", "limit": 1}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreFull", event.EventDef.Name)
+	assert.Equal(t, []string{"a"}, event.Payload.(AcquireSemaphoreOutput).Semaphore.Holders)
+}
+
+func TestAcquireSemaphoreInvalidLimit(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := AcquireSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "limit must be greater than 0", event.Payload)
+}
+
+func TestAcquireSemaphoreDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"AcquireSemaphore": {DeniedPrefixes: []string{"deploys/prod/"}}}
+	handler := AcquireSemaphore(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"prefix": "deploys/", "limit": 2}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "deniedPrefixes", output.Violations[0].Rule)
+}
+
+func TestAcquireSemaphoreRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.AcquireSemaphoreFunc = func(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error) {
+		return false, client.Semaphore{}, fmt.Errorf("kablammo")
+	}
+
+	handler := AcquireSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys", "limit": 1}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to acquire semaphore: kablammo", event.Payload)
+}
+
+func TestReleaseSemaphoreReturnsSemaphoreReleasedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseSemaphoreFunc = func(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error) {
+		assert.Equal(t, "deploys", prefix)
+		assert.Equal(t, "a", session)
+		return true, client.Semaphore{Prefix: prefix, Limit: 2, Holders: []string{"b"}}, nil
+	}
+
+	handler := ReleaseSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreReleased", event.EventDef.Name)
+	assert.Equal(t, []string{"b"}, event.Payload.(ReleaseSemaphoreOutput).Semaphore.Holders)
+}
+
+func TestReleaseSemaphoreReturnsSemaphoreNotHeldEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseSemaphoreFunc = func(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error) {
+		return false, client.Semaphore{Prefix: prefix, Limit: 2, Holders: []string{"b"}}, nil
+	}
+
+	handler := ReleaseSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "secret", "session": "db-password"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreNotHeld", event.EventDef.Name)
+	assert.Equal(t, []string{"b"}, event.Payload.(ReleaseSemaphoreOutput).Semaphore.Holders)
+}
+
+func TestReleaseSemaphoreDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"ReleaseSemaphore": {AllowedPrefixes: []string{"deploys/"}}}
+	handler := ReleaseSemaphore(KVTransactionMockConsul, policy).Handler
+	event := handler([]byte(`{"prefix": "secret", "session": "db-password"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SemaphoreDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	assert.Equal(t, "key secret is not under an allowed prefix", output.Violations[0].Error)
+}
+
+func TestReleaseSemaphoreRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ReleaseSemaphoreFunc = func(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error) {
+		return false, client.Semaphore{}, fmt.Errorf("kablammo")
+	}
+
+	handler := ReleaseSemaphore(KVTransactionMockConsul, nil).Handler
+	event := handler([]byte(`{"prefix": "deploys", "session": "a"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to release semaphore: kablammo", event.Payload)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var sessionCreatedEventDef = flyte.EventDef{Name: "SessionCreated"}
+
+//CreateSessionInput represents the CreateSession command payload.
+type CreateSessionInput struct {
+	Datacenter string `json:"dc"`
+	client.SessionRequest
+}
+
+//CreateSessionOutput represents the CreateSession result payload.
+type CreateSessionOutput struct {
+	Input CreateSessionInput `json:"input"`
+	ID    string             `json:"id"`
+}
+
+//CreateSession produces the CreateSession flyte command.
+func CreateSession(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "CreateSession",
+		OutputEvents: []flyte.EventDef{
+			sessionCreatedEventDef,
+		},
+		Handler: createSessionHandler(consulClient),
+	}
+}
+
+func createSessionHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := CreateSessionInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if errors := input.Validate(); 0 != len(errors) {
+			return flyte.NewFatalEvent(fmt.Sprintf("session is not valid: %v", strings.Join(errors, ", ")))
+		}
+
+		id, err := consulClient.CreateSession(input.SessionRequest, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to create session: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: sessionCreatedEventDef,
+			Payload: CreateSessionOutput{
+				Input: input,
+				ID:    id,
+			},
+		}
+	}
+}
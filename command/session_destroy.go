@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var sessionDestroyedEventDef = flyte.EventDef{Name: "SessionDestroyed"}
+
+//DestroySession produces the DestroySession flyte command.
+func DestroySession(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "DestroySession",
+		OutputEvents: []flyte.EventDef{
+			sessionDestroyedEventDef,
+			sessionNotFoundEventDef,
+		},
+		Handler: destroySessionHandler(consulClient),
+	}
+}
+
+func destroySessionHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := SessionInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.ID {
+			return flyte.NewFatalEvent("missing id")
+		}
+
+		ok, err := consulClient.DestroySession(input.ID, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to destroy session: %v", err))
+		}
+		if !ok {
+			return newSessionNotFoundEvent(input)
+		}
+
+		return flyte.Event{
+			EventDef: sessionDestroyedEventDef,
+			Payload: SessionOutput{
+				Input: input,
+			},
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var sessionFoundEventDef = flyte.EventDef{Name: "SessionFound"}
+
+//GetSession produces the GetSession flyte command.
+func GetSession(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "GetSession",
+		OutputEvents: []flyte.EventDef{
+			sessionFoundEventDef,
+			sessionNotFoundEventDef,
+		},
+		Handler: getSessionHandler(consulClient),
+	}
+}
+
+func getSessionHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := SessionInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.ID {
+			return flyte.NewFatalEvent("missing id")
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+
+		options := client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+		}
+		session, err := consulClient.GetSession(input.ID, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to get session: %v", err))
+		}
+		if nil == session {
+			return newSessionNotFoundEvent(input)
+		}
+
+		return flyte.Event{
+			EventDef: sessionFoundEventDef,
+			Payload: SessionOutput{
+				Input:   input,
+				Session: session,
+			},
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var sessionsListedEventDef = flyte.EventDef{Name: "SessionsListed"}
+
+//ListSessionsInput represents the ListSessions command payload.
+type ListSessionsInput struct {
+	Datacenter  string `json:"dc"`
+	Node        string `json:"node"`
+	Consistency string `json:"consistency"`
+}
+
+//ListSessionsOutput represents the ListSessions result payload.
+type ListSessionsOutput struct {
+	Input    ListSessionsInput `json:"input"`
+	Sessions []client.Session  `json:"sessions"`
+}
+
+//ListSessions produces the ListSessions flyte command.
+func ListSessions(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "ListSessions",
+		OutputEvents: []flyte.EventDef{
+			sessionsListedEventDef,
+		},
+		Handler: listSessionsHandler(consulClient),
+	}
+}
+
+func listSessionsHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := ListSessionsInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if !client.IsConsistencySupported(input.Consistency) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v consistency mode is not valid", input.Consistency))
+		}
+
+		options := client.QueryOptions{
+			Datacenter:  input.Datacenter,
+			Consistency: input.Consistency,
+		}
+		sessions, err := consulClient.ListSessions(input.Node, options)
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to list sessions: %v", err))
+		}
+
+		return flyte.Event{
+			EventDef: sessionsListedEventDef,
+			Payload: ListSessionsOutput{
+				Input:    input,
+				Sessions: sessions,
+			},
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+var (
+	sessionRenewedEventDef  = flyte.EventDef{Name: "SessionRenewed"}
+	sessionNotFoundEventDef = flyte.EventDef{Name: "SessionNotFound"}
+)
+
+//SessionInput represents the RenewSession, DestroySession and GetSession commands payload.
+//Consistency is only used by GetSession.
+type SessionInput struct {
+	Datacenter  string `json:"dc"`
+	ID          string `json:"id"`
+	Consistency string `json:"consistency"`
+}
+
+//SessionOutput represents the RenewSession, DestroySession and GetSession result payload.
+type SessionOutput struct {
+	Input   SessionInput    `json:"input"`
+	Session *client.Session `json:"session,omitempty"`
+}
+
+//RenewSession produces the RenewSession flyte command.
+func RenewSession(consulClient client.Consul) flyte.Command {
+	return flyte.Command{
+		Name: "RenewSession",
+		OutputEvents: []flyte.EventDef{
+			sessionRenewedEventDef,
+			sessionNotFoundEventDef,
+		},
+		Handler: renewSessionHandler(consulClient),
+	}
+}
+
+func renewSessionHandler(consulClient client.Consul) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := SessionInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if "" == input.ID {
+			return flyte.NewFatalEvent("missing id")
+		}
+
+		session, err := consulClient.RenewSession(input.ID, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to renew session: %v", err))
+		}
+		if nil == session {
+			return newSessionNotFoundEvent(input)
+		}
+
+		return flyte.Event{
+			EventDef: sessionRenewedEventDef,
+			Payload: SessionOutput{
+				Input:   input,
+				Session: session,
+			},
+		}
+	}
+}
+
+func newSessionNotFoundEvent(input SessionInput) flyte.Event {
+	return flyte.Event{
+		EventDef: sessionNotFoundEventDef,
+		Payload: SessionOutput{
+			Input: input,
+		},
+	}
+}
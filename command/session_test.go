@@ -0,0 +1,255 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCommandsArePopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	create := CreateSession(KVTransactionMockConsul)
+	assert.Equal(t, "CreateSession", create.Name)
+	require.Equal(t, 1, len(create.OutputEvents))
+	assert.Equal(t, "SessionCreated", create.OutputEvents[0].Name)
+
+	renew := RenewSession(KVTransactionMockConsul)
+	assert.Equal(t, "RenewSession", renew.Name)
+	require.Equal(t, 2, len(renew.OutputEvents))
+	assert.Equal(t, "SessionRenewed", renew.OutputEvents[0].Name)
+	assert.Equal(t, "SessionNotFound", renew.OutputEvents[1].Name)
+
+	destroy := DestroySession(KVTransactionMockConsul)
+	assert.Equal(t, "DestroySession", destroy.Name)
+	require.Equal(t, 2, len(destroy.OutputEvents))
+	assert.Equal(t, "SessionDestroyed", destroy.OutputEvents[0].Name)
+	assert.Equal(t, "SessionNotFound", destroy.OutputEvents[1].Name)
+
+	get := GetSession(KVTransactionMockConsul)
+	assert.Equal(t, "GetSession", get.Name)
+	require.Equal(t, 2, len(get.OutputEvents))
+	assert.Equal(t, "SessionFound", get.OutputEvents[0].Name)
+	assert.Equal(t, "SessionNotFound", get.OutputEvents[1].Name)
+
+	list := ListSessions(KVTransactionMockConsul)
+	assert.Equal(t, "ListSessions", list.Name)
+	require.Equal(t, 1, len(list.OutputEvents))
+	assert.Equal(t, "SessionsListed", list.OutputEvents[0].Name)
+}
+
+func TestCreateSessionReturnsSessionCreatedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CreateSessionFunc = func(request client.SessionRequest, options client.WriteOptions) (string, error) {
+		assert.Equal(t, "deploy", request.Name)
+		assert.Equal(t, "delete", request.Behavior)
+		assert.Equal(t, []string{"serfHealth", "service:web"}, request.Checks)
+		assert.Equal(t, "dc2", options.Datacenter)
+		return "session-id", nil
+	}
+
+	handler := CreateSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"dc": "dc2", "name": "deploy", "behavior": "delete", "checks": ["serfHealth", "service:web"]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionCreated", event.EventDef.Name)
+	assert.Equal(t, "session-id", event.Payload.(CreateSessionOutput).ID)
+}
+
+func TestCreateSessionInvalidInput(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := CreateSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"ttl": "48h"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "session is not valid: ttl must be between 10s and 24h0m0s", event.Payload)
+}
+
+func TestCreateSessionRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.CreateSessionFunc = func(request client.SessionRequest, options client.WriteOptions) (string, error) {
+		return "", fmt.Errorf("kablammo")
+	}
+
+	handler := CreateSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to create session: kablammo", event.Payload)
+}
+
+func TestRenewSessionReturnsSessionRenewedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RenewSessionFunc = func(id string, options client.WriteOptions) (*client.Session, error) {
+		return &client.Session{ID: id, TTL: "30s"}, nil
+	}
+
+	handler := RenewSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionRenewed", event.EventDef.Name)
+	assert.Equal(t, "30s", event.Payload.(SessionOutput).Session.TTL)
+}
+
+func TestRenewSessionReturnsSessionNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.RenewSessionFunc = func(id string, options client.WriteOptions) (*client.Session, error) {
+		return nil, nil
+	}
+
+	handler := RenewSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionNotFound", event.EventDef.Name)
+}
+
+func TestRenewSessionMissingID(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := RenewSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "missing id", event.Payload)
+}
+
+func TestDestroySessionReturnsSessionDestroyedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DestroySessionFunc = func(id string, options client.WriteOptions) (bool, error) {
+		assert.Equal(t, "session-id", id)
+		return true, nil
+	}
+
+	handler := DestroySession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionDestroyed", event.EventDef.Name)
+}
+
+func TestDestroySessionReturnsSessionNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DestroySessionFunc = func(id string, options client.WriteOptions) (bool, error) {
+		return false, nil
+	}
+
+	handler := DestroySession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionNotFound", event.EventDef.Name)
+}
+
+func TestDestroySessionRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.DestroySessionFunc = func(id string, options client.WriteOptions) (bool, error) {
+		return false, fmt.Errorf("kablammo")
+	}
+
+	handler := DestroySession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to destroy session: kablammo", event.Payload)
+}
+
+func TestGetSessionReturnsSessionFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetSessionFunc = func(id string, options client.QueryOptions) (*client.Session, error) {
+		assert.Equal(t, client.ConsistencyStale, options.Consistency)
+		return &client.Session{ID: id, Node: "node-1"}, nil
+	}
+
+	handler := GetSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id", "consistency": "stale"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionFound", event.EventDef.Name)
+	assert.Equal(t, "node-1", event.Payload.(SessionOutput).Session.Node)
+}
+
+func TestGetSessionReturnsSessionNotFoundEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.GetSessionFunc = func(id string, options client.QueryOptions) (*client.Session, error) {
+		return nil, nil
+	}
+
+	handler := GetSession(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"id": "session-id"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionNotFound", event.EventDef.Name)
+}
+
+func TestListSessionsReturnsSessionsListedEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.ListSessionsFunc = func(node string, options client.QueryOptions) ([]client.Session, error) {
+		assert.Equal(t, "node-1", node)
+		return []client.Session{{ID: "a"}, {ID: "b"}}, nil
+	}
+
+	handler := ListSessions(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"node": "node-1"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "SessionsListed", event.EventDef.Name)
+	assert.Equal(t, 2, len(event.Payload.(ListSessionsOutput).Sessions))
+}
+
+func TestListSessionsInvalidConsistency(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := ListSessions(KVTransactionMockConsul).Handler
+	event := handler([]byte(`{"consistency": "eventual"}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "eventual consistency mode is not valid", event.Payload)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+)
+
+const transactCommandName = "Transact"
+
+//TransactInput represents the Transact command payload.
+//Encoding is the value encoding of the key-value operations that do not define their own.
+type TransactInput struct {
+	Datacenter string                `json:"dc"`
+	Operations []client.TxnOperation `json:"operations"`
+	Encoding   string                `json:"encoding,omitempty"`
+}
+
+//operations returns the operations with the default encoding applied, leaving the input untouched.
+func (i TransactInput) operations() []client.TxnOperation {
+	operations := make([]client.TxnOperation, len(i.Operations))
+	for index, operation := range i.Operations {
+		if nil != operation.KV && "" == operation.KV.Encoding {
+			kv := *operation.KV
+			kv.Encoding = i.Encoding
+			operation.KV = &kv
+		}
+		operations[index] = operation
+	}
+	return operations
+}
+
+//TransactErrorOutput represents the Transact error payload.
+type TransactErrorOutput struct {
+	Input  TransactInput     `json:"input"`
+	Errors []client.TxnError `json:"errors"`
+}
+
+//TransactResultOutput represents the Transact result payload.
+type TransactResultOutput struct {
+	Input   TransactInput      `json:"input"`
+	Results []client.TxnResult `json:"results"`
+}
+
+//Transact produces the Transact flyte command.
+//Transactions with key-value operations violating the Transact rules of the policy are rejected
+//and values are redacted from the events as for TransactKV.
+func Transact(consulClient client.Consul, policy Policy, redaction Redaction) flyte.Command {
+	return flyte.Command{
+		Name: transactCommandName,
+		OutputEvents: []flyte.EventDef{
+			transactionSucceededEventDef,
+			transactionRolledBackEventDef,
+			transactionDeniedEventDef,
+		},
+		Handler: redaction.redactTransact(transactHandler(consulClient, policy)),
+	}
+}
+
+func transactHandler(consulClient client.Consul, policy Policy) func(json.RawMessage) flyte.Event {
+	return func(rawInput json.RawMessage) flyte.Event {
+		input := TransactInput{}
+		if err := json.Unmarshal(rawInput, &input); nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("input is not valid: %v", err))
+		}
+		if 0 == len(input.Operations) {
+			return flyte.NewFatalEvent("missing operations")
+		}
+		if !client.IsEncodingSupported(input.Encoding) {
+			return flyte.NewFatalEvent(fmt.Sprintf("%v encoding is not valid", input.Encoding))
+		}
+		if violations := checkTransactPolicy(policy, input); 0 != len(violations) {
+			return newPolicyDeniedEvent(transactionDeniedEventDef, input, violations)
+		}
+
+		operations := input.operations()
+		errors := []client.TxnError{}
+		for index, operation := range operations {
+			for _, err := range operation.Validate() {
+				errors = append(errors, client.TxnError{
+					Index: index,
+					Error: err,
+				})
+			}
+		}
+		if 0 != len(errors) {
+			return newTransactRolledBackEvent(input, errors)
+		}
+
+		results, rollback, err := consulClient.Transact(operations, client.WriteOptions{Datacenter: input.Datacenter})
+		if nil != err {
+			return flyte.NewFatalEvent(fmt.Sprintf("failed to make transaction request: %v", err))
+		}
+		if 0 < len(rollback) {
+			return newTransactRolledBackEvent(input, rollback)
+		}
+
+		return flyte.Event{
+			EventDef: transactionSucceededEventDef,
+			Payload: TransactResultOutput{
+				Input:   input,
+				Results: results,
+			},
+		}
+	}
+}
+
+func checkTransactPolicy(policy Policy, input TransactInput) []PolicyViolation {
+	violations := []PolicyViolation{}
+	if violation := policy.checkDatacenter(transactCommandName, input.Datacenter); nil != violation {
+		violations = append(violations, *violation)
+	}
+	for index, operation := range input.Operations {
+		if nil == operation.KV {
+			continue
+		}
+		tree := "get-tree" == operation.KV.Verb || "delete-tree" == operation.KV.Verb
+		if violation := policy.checkKV(transactCommandName, index, operation.KV.Verb, operation.KV.Key, tree); nil != violation {
+			violations = append(violations, *violation)
+		}
+	}
+	return violations
+}
+
+func newTransactRolledBackEvent(input TransactInput, errors []client.TxnError) flyte.Event {
+	return flyte.Event{
+		EventDef: transactionRolledBackEventDef,
+		Payload: TransactErrorOutput{
+			Input:  input,
+			Errors: errors,
+		},
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactCommandIsPopulated(t *testing.T) {
+	Before()
+	defer After()
+
+	command := Transact(KVTransactionMockConsul, nil, Redaction{})
+
+	assert.Equal(t, "Transact", command.Name)
+	require.Equal(t, 3, len(command.OutputEvents))
+	assert.Equal(t, "TransactionSucceeded", command.OutputEvents[0].Name)
+	assert.Equal(t, "TransactionRolledBack", command.OutputEvents[1].Name)
+	assert.Equal(t, "TransactionDenied", command.OutputEvents[2].Name)
+}
+
+func TestTransactDeniedByPolicy(t *testing.T) {
+	Before()
+	defer After()
+
+	policy := Policy{"Transact": {DeniedPrefixes: []string{"config/secret/"}}}
+	handler := Transact(KVTransactionMockConsul, policy, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"node": {"verb": "get", "node": "n1"}}, {"kv": {"verb": "get", "key": "config/secret/db"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionDenied", event.EventDef.Name)
+	output := event.Payload.(PolicyDeniedOutput)
+	require.Equal(t, 1, len(output.Violations))
+	require.NotNil(t, output.Violations[0].Index)
+	assert.Equal(t, 1, *output.Violations[0].Index)
+	assert.Equal(t, "deniedPrefixes", output.Violations[0].Rule)
+}
+
+func TestTransactReturnsTransactionSucceededEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		require.Equal(t, 2, len(operations))
+		assert.Equal(t, "joe/mama", operations[0].KV.Key)
+		assert.Equal(t, "web-1", operations[1].Service.ID)
+		assert.Equal(t, "dc2", options.Datacenter)
+		return []client.TxnResult{
+			{Index: 0, Type: "kv", KV: &client.KVPair{Key: "joe/mama"}},
+			{Index: 1, Type: "service", Service: &client.ServiceInstance{ID: "web-1"}},
+		}, nil, nil
+	}
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"dc": "dc2", "operations": [
+		{"kv": {"verb": "set", "key": "joe/mama", "value": "dGVzdA=="}},
+		{"service": {"verb": "set", "node": "node-1", "id": "web-1", "name": "web"}}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	output := event.Payload.(TransactResultOutput)
+	require.Equal(t, 2, len(output.Results))
+	assert.Equal(t, "service", output.Results[1].Type)
+}
+
+func TestTransactInvalidOperations(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [
+		{"kv": {"verb": "set", "key": "joe/mama"}},
+		{"node": {"verb": "cas", "node": "node-1"}},
+		{}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	output := event.Payload.(TransactErrorOutput)
+	assert.Equal(t, []client.TxnError{
+		{Index: 1, Error: "cas verb requires an index"},
+		{Index: 2, Error: "exactly one of kv, node, service, check and session must be set"},
+	}, output.Errors)
+}
+
+func TestTransactAppliesDefaultEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		require.Equal(t, 2, len(operations))
+		assert.Equal(t, "base64", operations[0].KV.Encoding)
+		assert.Nil(t, operations[1].KV)
+		return []client.TxnResult{{Index: 0, Type: "kv"}, {Index: 1, Type: "node"}}, nil, nil
+	}
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"encoding": "base64", "operations": [
+		{"kv": {"verb": "set", "key": "joe/mama", "value": "aGk="}},
+		{"node": {"verb": "get", "node": "node-1"}}
+	]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionSucceeded", event.EventDef.Name)
+	assert.Equal(t, "", event.Payload.(TransactResultOutput).Input.Operations[0].KV.Encoding)
+}
+
+func TestTransactInvalidEncoding(t *testing.T) {
+	Before()
+	defer After()
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"encoding": "xml", "operations": [{"kv": {"verb": "get", "key": "joe/mama"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "xml encoding is not valid", event.Payload)
+}
+
+func TestTransactReturnsTransactionRolledBackEvent(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		return nil, []client.TxnError{{Index: 0, Error: "check not found"}}, nil
+	}
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"check": {"verb": "delete", "node": "node-1", "checkId": "web"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "TransactionRolledBack", event.EventDef.Name)
+	assert.Equal(t, "check not found", event.Payload.(TransactErrorOutput).Errors[0].Error)
+}
+
+func TestTransactRequestFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVTransactionMockConsul.TransactFunc = func(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+		return nil, nil, fmt.Errorf("kablammo")
+	}
+
+	handler := Transact(KVTransactionMockConsul, nil, Redaction{}).Handler
+	event := handler([]byte(`{"operations": [{"kv": {"verb": "get", "key": "joe/mama"}}]}`))
+
+	require.NotNil(t, event)
+	assert.Equal(t, "FATAL", event.EventDef.Name)
+	assert.Equal(t, "failed to make transaction request: kablammo", event.Payload)
+}
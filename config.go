@@ -14,18 +14,46 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/ExpediaGroup/flyte-consul/command"
+	"github.com/ExpediaGroup/flyte-consul/watch"
 	"github.com/HotelsDotCom/go-logger"
 )
 
 const (
-	flyteHostKey = "FLYTE_API"
-	packNameKey  = "PACK_NAME"
+	flyteHostKey       = "FLYTE_API"
+	packNameKey        = "PACK_NAME"
+	watchKVKeysKey     = "WATCH_KV_KEYS"
+	watchKVPrefixesKey = "WATCH_KV_PREFIXES"
+	watchHealthKey     = "WATCH_HEALTH_SERVICES"
+	healthFlapKey      = "WATCH_HEALTH_FLAP_WINDOW"
+	watchLeaderKey     = "WATCH_LEADER_KEYS"
+	watchEventsKey     = "WATCH_EVENTS"
+	retryAttemptsKey   = "RETRY_MAX_ATTEMPTS"
+	retryBackoffKey    = "RETRY_BACKOFF"
+	retryMaxBackoffKey = "RETRY_MAX_BACKOFF"
+	policyKey          = "POLICY"
+	policyFileKey      = "POLICY_FILE"
+	tokenRegistryKey   = "TOKEN_REGISTRY_FILE"
+	redactKeysKey      = "REDACT_KEY_PATTERNS"
+	redactInputKey     = "REDACT_INPUT_VALUES"
+	allServices        = "*"
+	allEvents          = "*"
 )
 
-var lookupEnv = os.LookupEnv
+var (
+	lookupEnv = os.LookupEnv
+	readFile  = ioutil.ReadFile
+)
 
 func flyteAPIHost() *url.URL {
 	hostEnv := getEnv(flyteHostKey, true)
@@ -40,6 +68,150 @@ func packName() string {
 	return getEnv(packNameKey, false)
 }
 
+func kvWatches() []watch.KVWatch {
+	watches := []watch.KVWatch{}
+	for _, key := range getEnvList(watchKVKeysKey) {
+		watches = append(watches, watch.KVWatch{Key: key})
+	}
+	for _, prefix := range getEnvList(watchKVPrefixesKey) {
+		watches = append(watches, watch.KVWatch{Key: prefix, Recurse: true})
+	}
+	return watches
+}
+
+func healthWatches() []string {
+	services := []string{}
+	for _, service := range getEnvList(watchHealthKey) {
+		if service == allServices {
+			return []string{""}
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+func healthFlapWindow() time.Duration {
+	value := getEnv(healthFlapKey, false)
+	if value == "" {
+		return 0
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Fatalf("%s=%s is not a valid duration: %v", healthFlapKey, value, err)
+	}
+	return window
+}
+
+func leaderWatches() []string {
+	return getEnvList(watchLeaderKey)
+}
+
+func eventWatches() []string {
+	names := []string{}
+	for _, name := range getEnvList(watchEventsKey) {
+		if name == allEvents {
+			return []string{""}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func retryPolicy() client.RetryPolicy {
+	override := client.RetryPolicy{
+		Backoff:    getEnv(retryBackoffKey, false),
+		MaxBackoff: getEnv(retryMaxBackoffKey, false),
+	}
+	if value := getEnv(retryAttemptsKey, false); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			logger.Fatalf("%s=%s is not a valid number: %v", retryAttemptsKey, value, err)
+		}
+		override.MaxAttempts = attempts
+	}
+	if errors := override.Validate(); len(errors) != 0 {
+		logger.Fatalf("retry policy is not valid: %s", strings.Join(errors, ", "))
+	}
+	return client.DefaultRetryPolicy().Override(override)
+}
+
+func commandPolicy() command.Policy {
+	value := getEnv(policyKey, false)
+	source := policyKey
+	if file := getEnv(policyFileKey, false); file != "" {
+		if value != "" {
+			logger.Fatalf("only one of %s and %s can be set", policyKey, policyFileKey)
+		}
+		data, err := readFile(file)
+		if err != nil {
+			logger.Fatalf("%s=%s cannot be read: %v", policyFileKey, file, err)
+		}
+		value = string(data)
+		source = policyFileKey
+	}
+	if value == "" {
+		return nil
+	}
+
+	policy := command.Policy{}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		logger.Fatalf("%s is not a valid policy: %v", source, err)
+	}
+	if errors := policy.Validate(); len(errors) != 0 {
+		logger.Fatalf("%s is not a valid policy: %s", source, strings.Join(errors, ", "))
+	}
+	return policy
+}
+
+func tokenRegistry() command.TokenRegistry {
+	file := getEnv(tokenRegistryKey, false)
+	if file == "" {
+		return nil
+	}
+
+	data, err := readFile(file)
+	if err != nil {
+		logger.Fatalf("%s=%s cannot be read: %v", tokenRegistryKey, file, err)
+	}
+	tokens := command.TokenRegistry{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		logger.Fatalf("%s=%s is not a valid token registry: %v", tokenRegistryKey, file, err)
+	}
+	return tokens
+}
+
+func redaction() command.Redaction {
+	redaction := command.Redaction{}
+	for _, pattern := range getEnvList(redactKeysKey) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Fatalf("%s=%s is not a valid regular expression: %v", redactKeysKey, pattern, err)
+		}
+		redaction.KeyPatterns = append(redaction.KeyPatterns, re)
+	}
+	if value := getEnv(redactInputKey, false); value != "" {
+		omit, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Fatalf("%s=%s is not a valid boolean: %v", redactInputKey, value, err)
+		}
+		redaction.OmitInputValues = omit
+	}
+	return redaction
+}
+
+func getEnvList(key string) []string {
+	values := []string{}
+	for _, value := range strings.Split(getEnv(key, false), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnv(key string, required bool) string {
 	if v, _ := lookupEnv(key); v != "" {
 		return v
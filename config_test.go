@@ -14,11 +14,17 @@ limitations under the License.
 package main
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/ExpediaGroup/flyte-consul/command"
+	"github.com/ExpediaGroup/flyte-consul/watch"
 	"github.com/HotelsDotCom/go-logger/loggertest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var TestEnv map[string]string
@@ -70,3 +76,224 @@ func TestPackName(t *testing.T) {
 	TestEnv["PACK_NAME"] = "Consul2"
 	assert.Equal(t, "Consul2", packName())
 }
+
+func TestKVWatches(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["WATCH_KV_KEYS"] = "joe/mama, joe/papa"
+	TestEnv["WATCH_KV_PREFIXES"] = "joe/kids/,"
+
+	watches := kvWatches()
+	require.Equal(t, 3, len(watches))
+	assert.Equal(t, watch.KVWatch{Key: "joe/mama"}, watches[0])
+	assert.Equal(t, watch.KVWatch{Key: "joe/papa"}, watches[1])
+	assert.Equal(t, watch.KVWatch{Key: "joe/kids/", Recurse: true}, watches[2])
+}
+
+func TestKVWatchesNotSet(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Equal(t, 0, len(kvWatches()))
+}
+
+func TestHealthWatches(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["WATCH_HEALTH_SERVICES"] = "web,db"
+	assert.Equal(t, []string{"web", "db"}, healthWatches())
+}
+
+func TestHealthWatchesAllServices(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["WATCH_HEALTH_SERVICES"] = "web,*"
+	assert.Equal(t, []string{""}, healthWatches())
+}
+
+func TestHealthFlapWindow(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Equal(t, time.Duration(0), healthFlapWindow())
+
+	TestEnv["WATCH_HEALTH_FLAP_WINDOW"] = "30s"
+	assert.Equal(t, 30*time.Second, healthFlapWindow())
+}
+
+func TestHealthFlapWindowInvalid(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["WATCH_HEALTH_FLAP_WINDOW"] = "soon"
+	assert.Panics(t, func() { healthFlapWindow() })
+}
+
+func TestLeaderWatches(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Equal(t, 0, len(leaderWatches()))
+
+	TestEnv["WATCH_LEADER_KEYS"] = "service/web/leader, service/db/leader"
+	assert.Equal(t, []string{"service/web/leader", "service/db/leader"}, leaderWatches())
+}
+
+func TestEventWatches(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Equal(t, 0, len(eventWatches()))
+
+	TestEnv["WATCH_EVENTS"] = "deploy, restart"
+	assert.Equal(t, []string{"deploy", "restart"}, eventWatches())
+}
+
+func TestEventWatchesAllEvents(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["WATCH_EVENTS"] = "deploy,*"
+	assert.Equal(t, []string{""}, eventWatches())
+}
+
+func TestRetryPolicy(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["RETRY_MAX_ATTEMPTS"] = "5"
+	TestEnv["RETRY_BACKOFF"] = "1s"
+
+	assert.Equal(t, client.RetryPolicy{MaxAttempts: 5, Backoff: "1s", MaxBackoff: "2s"}, retryPolicy())
+}
+
+func TestRetryPolicyNotSet(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Equal(t, client.DefaultRetryPolicy(), retryPolicy())
+}
+
+func TestRetryPolicyInvalid(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["RETRY_MAX_ATTEMPTS"] = "many"
+	assert.Panics(t, func() { retryPolicy() })
+
+	TestEnv["RETRY_MAX_ATTEMPTS"] = "3"
+	TestEnv["RETRY_MAX_BACKOFF"] = "forever"
+	assert.Panics(t, func() { retryPolicy() })
+}
+
+func TestCommandPolicy(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["POLICY"] = `{"TransactKV": {"allowedPrefixes": ["config/"], "datacenters": ["dc1"]}}`
+
+	assert.Equal(t, command.Policy{"TransactKV": {AllowedPrefixes: []string{"config/"}, Datacenters: []string{"dc1"}}}, commandPolicy())
+}
+
+func TestCommandPolicyFile(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	readFile = func(filename string) ([]byte, error) {
+		assert.Equal(t, "/etc/flyte-consul/policy.json", filename)
+		return []byte(`{"TransactKV": {"verbs": ["get"]}}`), nil
+	}
+	defer func() { readFile = ioutil.ReadFile }()
+	TestEnv["POLICY_FILE"] = "/etc/flyte-consul/policy.json"
+
+	assert.Equal(t, command.Policy{"TransactKV": {Verbs: []string{"get"}}}, commandPolicy())
+}
+
+func TestCommandPolicyNotSet(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Nil(t, commandPolicy())
+}
+
+func TestCommandPolicyInvalid(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["POLICY"] = `{"TransactKV": {"allowedPrefix": ["config/"]}}`
+	assert.Panics(t, func() { commandPolicy() })
+
+	TestEnv["POLICY_FILE"] = "/etc/flyte-consul/policy.json"
+	assert.Panics(t, func() { commandPolicy() })
+}
+
+func TestCommandPolicyUnknownCommand(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["POLICY"] = `{"TransactKv": {"verbs": ["get"]}}`
+	assert.Panics(t, func() { commandPolicy() })
+}
+
+func TestTokenRegistry(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	readFile = func(filename string) ([]byte, error) {
+		assert.Equal(t, "/etc/flyte-consul/tokens.json", filename)
+		return []byte(`{"deploy": "secret"}`), nil
+	}
+	defer func() { readFile = ioutil.ReadFile }()
+	TestEnv["TOKEN_REGISTRY_FILE"] = "/etc/flyte-consul/tokens.json"
+
+	assert.Equal(t, command.TokenRegistry{"deploy": "secret"}, tokenRegistry())
+}
+
+func TestTokenRegistryNotSet(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	assert.Nil(t, tokenRegistry())
+}
+
+func TestTokenRegistryInvalid(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	readFile = func(filename string) ([]byte, error) {
+		return []byte(`["secret"]`), nil
+	}
+	defer func() { readFile = ioutil.ReadFile }()
+	TestEnv["TOKEN_REGISTRY_FILE"] = "/etc/flyte-consul/tokens.json"
+
+	assert.Panics(t, func() { tokenRegistry() })
+}
+
+func TestRedaction(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["REDACT_KEY_PATTERNS"] = "/secret/, password$"
+	TestEnv["REDACT_INPUT_VALUES"] = "true"
+
+	redaction := redaction()
+	require.Equal(t, 2, len(redaction.KeyPatterns))
+	assert.True(t, redaction.KeyPatterns[0].MatchString("app/secret/db"))
+	assert.True(t, redaction.KeyPatterns[1].MatchString("app/password"))
+	assert.True(t, redaction.OmitInputValues)
+}
+
+func TestRedactionInvalid(t *testing.T) {
+	BeforeConfig()
+	defer AfterConfig()
+
+	TestEnv["REDACT_KEY_PATTERNS"] = "(secret"
+	assert.Panics(t, func() { redaction() })
+
+	TestEnv["REDACT_KEY_PATTERNS"] = ""
+	TestEnv["REDACT_INPUT_VALUES"] = "maybe"
+	assert.Panics(t, func() { redaction() })
+}
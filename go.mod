@@ -6,4 +6,6 @@ require (
 	github.com/ExpediaGroup/flyte-client v1.0.1-0.20200825134228-2c12e3094a7c
 	github.com/HotelsDotCom/go-logger v0.0.0-20180518131502-802095993e48
 	github.com/hashicorp/consul/api v1.7.0
+	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.8
 )
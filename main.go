@@ -23,6 +23,7 @@ import (
 	"github.com/ExpediaGroup/flyte-client/flyte"
 	client "github.com/ExpediaGroup/flyte-consul/client"
 	"github.com/ExpediaGroup/flyte-consul/command"
+	"github.com/ExpediaGroup/flyte-consul/watch"
 	"github.com/HotelsDotCom/go-logger"
 )
 
@@ -40,6 +41,10 @@ func main() {
 	packDef := GetPackDef(consulClient)
 	pack := flyte.NewPack(packDef, flyteClient.NewClient(flyteAPIHost(), 10*time.Second))
 	pack.Start()
+	watch.NewKVWatcher(consulClient, kvWatches(), redaction().KeyPatterns).Start(pack)
+	watch.NewHealthWatcher(consulClient, healthWatches(), healthFlapWindow()).Start(pack)
+	watch.NewLeaderWatcher(consulClient, leaderWatches()).Start(pack)
+	watch.NewUserEventWatcher(consulClient, eventWatches()).Start(pack)
 
 	select {}
 }
@@ -55,13 +60,61 @@ func GetPackDef(consul client.Consul) flyte.PackDef {
 	if packName == "" {
 		packName = defaultPackName
 	}
+	tokens := tokenRegistry()
+	policy := commandPolicy()
+	redaction := redaction()
 
 	return flyte.PackDef{
 		Name:    packName,
 		HelpURL: helpURL,
 		Commands: []flyte.Command{
-			command.TransactKV(consul),
+			command.TransactKV(consul, retryPolicy(), policy, tokens, redaction),
+			command.Transact(consul, policy, redaction),
+			command.GetKV(consul, policy, redaction),
+			command.PutKV(consul, policy, redaction),
+			command.DeleteKV(consul, policy),
+			command.ListServices(consul),
+			command.GetService(consul),
+			command.ListNodes(consul),
+			command.GetHealthyService(consul),
+			command.RegisterService(consul),
+			command.DeregisterService(consul),
+			command.RegisterCatalogEntity(consul),
+			command.DeregisterCatalogEntity(consul),
+			command.EnableMaintenance(consul),
+			command.DisableMaintenance(consul),
+			command.MaintenanceStatus(consul),
+			command.CreateSession(consul),
+			command.RenewSession(consul),
+			command.DestroySession(consul),
+			command.GetSession(consul),
+			command.ListSessions(consul),
+			command.AcquireLock(consul, policy),
+			command.ReleaseLock(consul, policy),
+			command.RenewLock(consul, policy),
+			command.AcquireSemaphore(consul, policy),
+			command.ReleaseSemaphore(consul, policy),
+			command.CampaignLeadership(consul, policy),
+			command.ResignLeadership(consul, policy),
+			command.CreateACLToken(consul, tokens),
+			command.ReadACLToken(consul, tokens),
+			command.UpdateACLToken(consul, tokens),
+			command.DeleteACLToken(consul, tokens),
+			command.CloneACLToken(consul, tokens),
+			command.UpsertACLPolicy(consul, tokens),
+			command.DeleteACLPolicy(consul, tokens),
+			command.UpsertACLRole(consul, tokens),
+			command.DeleteACLRole(consul, tokens),
+			command.FireEvent(consul, tokens),
 		},
-		EventDefs: []flyte.EventDef{},
+		EventDefs: eventDefs(),
 	}
 }
+
+func eventDefs() []flyte.EventDef {
+	eventDefs := watch.KVEventDefs()
+	eventDefs = append(eventDefs, watch.HealthEventDefs()...)
+	eventDefs = append(eventDefs, watch.LeaderEventDefs()...)
+	eventDefs = append(eventDefs, watch.UserEventDefs()...)
+	return eventDefs
+}
@@ -27,16 +27,184 @@ func TestPackDefinitionIsPopulated(t *testing.T) {
 	assert.Equal(t, "Consul", packDef.Name)
 	assert.Equal(t, "https://github.com/ExpediaGroup/flyte-consul/blob/master/README.md", packDef.HelpURL.String())
 	require.Equal(t, 0, len(packDef.Labels))
-	require.Equal(t, 1, len(packDef.Commands))
-	require.Equal(t, 0, len(packDef.EventDefs))
+	require.Equal(t, 38, len(packDef.Commands))
+	require.Equal(t, 8, len(packDef.EventDefs))
 }
 
 type DummyConsul struct{}
 
-func (DummyConsul) KVTransact(datacenter string, operations []client.KVOperation) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
+func (DummyConsul) KVTransact(operations []client.KVOperation, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, error) {
 	return nil, nil, nil
 }
 
 func (DummyConsul) IsVerbSupported(verb string) bool {
 	return true
 }
+
+func (DummyConsul) GetKV(key, encoding string, options client.QueryOptions) (*client.KVPair, error) {
+	return nil, nil
+}
+
+func (DummyConsul) ListKV(prefix, encoding string, options client.QueryOptions) ([]client.KVPair, error) {
+	return nil, nil
+}
+
+func (DummyConsul) ListKVKeys(prefix string, options client.QueryOptions) ([]string, error) {
+	return nil, nil
+}
+
+func (DummyConsul) PutKV(put client.KVPut, options client.WriteOptions) (bool, error) {
+	return true, nil
+}
+
+func (DummyConsul) DeleteKV(del client.KVDelete, options client.WriteOptions) (bool, error) {
+	return true, nil
+}
+
+func (DummyConsul) WatchKV(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+	return nil, 0, nil
+}
+
+func (DummyConsul) ListServices(options client.QueryOptions) (map[string][]string, error) {
+	return nil, nil
+}
+
+func (DummyConsul) GetService(name string, tags []string, options client.QueryOptions) ([]client.ServiceInstance, error) {
+	return nil, nil
+}
+
+func (DummyConsul) ListNodes(options client.QueryOptions) ([]client.Node, error) {
+	return nil, nil
+}
+
+func (DummyConsul) GetHealthyService(name string, tags []string, passingOnly bool, options client.QueryOptions) ([]client.ServiceInstanceHealth, error) {
+	return nil, nil
+}
+
+func (DummyConsul) WatchHealthChecks(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+	return nil, 0, nil
+}
+
+func (DummyConsul) RegisterService(service client.ServiceRegistration) error {
+	return nil
+}
+
+func (DummyConsul) DeregisterService(serviceID string) error {
+	return nil
+}
+
+func (DummyConsul) RegisterCatalogEntity(entity client.CatalogEntity, options client.WriteOptions) error {
+	return nil
+}
+
+func (DummyConsul) DeregisterCatalogEntity(node, serviceID string, options client.WriteOptions) error {
+	return nil
+}
+
+func (DummyConsul) EnableMaintenance(serviceID, reason string) (*client.HealthCheck, error) {
+	return nil, nil
+}
+
+func (DummyConsul) DisableMaintenance(serviceID string) error {
+	return nil
+}
+
+func (DummyConsul) GetMaintenance(serviceID string) (*client.HealthCheck, error) {
+	return nil, nil
+}
+
+func (DummyConsul) AcquireLock(request client.LockRequest, options client.WriteOptions) (bool, client.Lock, error) {
+	return false, client.Lock{}, nil
+}
+
+func (DummyConsul) ReleaseLock(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+	return false, client.Lock{}, nil
+}
+
+func (DummyConsul) RenewLock(key, session string, options client.WriteOptions) (bool, client.Lock, error) {
+	return false, client.Lock{}, nil
+}
+
+func (DummyConsul) CreateSession(request client.SessionRequest, options client.WriteOptions) (string, error) {
+	return "", nil
+}
+
+func (DummyConsul) RenewSession(id string, options client.WriteOptions) (*client.Session, error) {
+	return nil, nil
+}
+
+func (DummyConsul) DestroySession(id string, options client.WriteOptions) (bool, error) {
+	return false, nil
+}
+
+func (DummyConsul) GetSession(id string, options client.QueryOptions) (*client.Session, error) {
+	return nil, nil
+}
+
+func (DummyConsul) ListSessions(node string, options client.QueryOptions) ([]client.Session, error) {
+	return nil, nil
+}
+
+func (DummyConsul) AcquireSemaphore(request client.SemaphoreRequest, options client.WriteOptions) (bool, client.Semaphore, error) {
+	return false, client.Semaphore{}, nil
+}
+
+func (DummyConsul) ReleaseSemaphore(prefix, session string, options client.WriteOptions) (bool, client.Semaphore, error) {
+	return false, client.Semaphore{}, nil
+}
+
+func (DummyConsul) Transact(operations []client.TxnOperation, options client.WriteOptions) ([]client.TxnResult, []client.TxnError, error) {
+	return nil, nil, nil
+}
+
+func (DummyConsul) KVTransactBatches(operations []client.KVOperation, compensate bool, options client.WriteOptions) ([]client.KVTransactionResult, []client.KVTransactionError, *client.KVPartialTransaction, error) {
+	return nil, nil, nil, nil
+}
+
+func (DummyConsul) KVTransactPlan(operations []client.KVOperation, options client.WriteOptions) (*client.KVTransactionPlan, error) {
+	return nil, nil
+}
+
+func (DummyConsul) CreateACLToken(request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+	return nil, nil
+}
+
+func (DummyConsul) ReadACLToken(accessorID string, options client.QueryOptions) (*client.ACLToken, error) {
+	return nil, nil
+}
+
+func (DummyConsul) UpdateACLToken(accessorID string, request client.ACLTokenRequest, options client.WriteOptions) (*client.ACLToken, error) {
+	return nil, nil
+}
+
+func (DummyConsul) DeleteACLToken(accessorID string, options client.WriteOptions) (bool, error) {
+	return false, nil
+}
+
+func (DummyConsul) CloneACLToken(accessorID, description string, options client.WriteOptions) (*client.ACLToken, error) {
+	return nil, nil
+}
+
+func (DummyConsul) UpsertACLPolicy(request client.ACLPolicyRequest, options client.WriteOptions) (*client.ACLPolicy, bool, error) {
+	return nil, false, nil
+}
+
+func (DummyConsul) DeleteACLPolicy(name string, options client.WriteOptions) (bool, error) {
+	return false, nil
+}
+
+func (DummyConsul) UpsertACLRole(request client.ACLRoleRequest, options client.WriteOptions) (*client.ACLRole, bool, error) {
+	return nil, false, nil
+}
+
+func (DummyConsul) DeleteACLRole(name string, options client.WriteOptions) (bool, error) {
+	return false, nil
+}
+
+func (DummyConsul) FireEvent(request client.UserEventRequest, options client.WriteOptions) (string, error) {
+	return "", nil
+}
+
+func (DummyConsul) WatchUserEvents(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+	return nil, 0, nil
+}
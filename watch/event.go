@@ -0,0 +1,134 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/HotelsDotCom/go-logger"
+)
+
+var consulUserEventEventDef = flyte.EventDef{Name: "ConsulUserEvent"}
+
+type eventClient interface {
+	WatchUserEvents(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error)
+}
+
+//UserEventOutput represents the payload of a user event.
+//Watch is the watched event name, empty when every event is watched.
+type UserEventOutput struct {
+	Watch string           `json:"watch"`
+	Event client.UserEvent `json:"event"`
+}
+
+//UserEventWatcher emits an event for each new consul user event with a watched name.
+type UserEventWatcher struct {
+	consul    eventClient
+	names     []string
+	waitTime  time.Duration
+	retryWait time.Duration
+}
+
+type userEventWatchState struct {
+	initialized bool
+	index       uint64
+	seen        map[string]bool
+}
+
+//NewUserEventWatcher produces a new user event watcher, an empty name watching every event.
+func NewUserEventWatcher(consul eventClient, names []string) *UserEventWatcher {
+	return &UserEventWatcher{
+		consul:    consul,
+		names:     names,
+		waitTime:  defaultWaitTime,
+		retryWait: defaultRetryWait,
+	}
+}
+
+//UserEventDefs are the events emitted by the user event watcher.
+func UserEventDefs() []flyte.EventDef {
+	return []flyte.EventDef{
+		consulUserEventEventDef,
+	}
+}
+
+//Start watches every configured event name in the background.
+func (w *UserEventWatcher) Start(sender EventSender) {
+	for _, name := range w.names {
+		logger.Infof("watching user events %q", name)
+		go w.run(name, sender)
+	}
+}
+
+func (w *UserEventWatcher) run(name string, sender EventSender) {
+	state := &userEventWatchState{}
+	for {
+		events, err := w.poll(name, state)
+		if nil != err {
+			logger.Errorf("failed to watch user events %q: %v", name, err)
+			time.Sleep(w.retryWait)
+			continue
+		}
+		for _, event := range events {
+			if err := sender.SendEvent(event); nil != err {
+				logger.Errorf("failed to send %v event: %v", event.EventDef.Name, err)
+			}
+		}
+	}
+}
+
+func (w *UserEventWatcher) poll(name string, state *userEventWatchState) ([]flyte.Event, error) {
+	options := client.QueryOptions{
+		WaitIndex: state.index,
+		WaitTime:  w.waitTime,
+	}
+	userEvents, index, err := w.consul.WatchUserEvents(name, options)
+	if nil != err {
+		return nil, err
+	}
+
+	// consul returns the recent events it buffered, an event is new when its ID and LTime have not been seen
+	events := []flyte.Event{}
+	seen := make(map[string]bool, len(userEvents))
+	for _, userEvent := range userEvents {
+		key := fmt.Sprintf("%s/%d", userEvent.ID, userEvent.LTime)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if state.initialized && !state.seen[key] {
+			events = append(events, newUserEvent(name, userEvent))
+		}
+	}
+
+	// unlike other endpoints the index is derived from the ID of the last event and is not ordered,
+	// it is only passed back to block until another event is received
+	state.initialized = true
+	state.index = index
+	state.seen = seen
+	return events, nil
+}
+
+func newUserEvent(watch string, userEvent client.UserEvent) flyte.Event {
+	return flyte.Event{
+		EventDef: consulUserEventEventDef,
+		Payload: UserEventOutput{
+			Watch: watch,
+			Event: userEvent,
+		},
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"testing"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserEventDefs(t *testing.T) {
+	eventDefs := UserEventDefs()
+
+	require.Equal(t, 1, len(eventDefs))
+	assert.Equal(t, "ConsulUserEvent", eventDefs[0].Name)
+}
+
+func TestUserEventWatcherFirstPollEmitsNoEvents(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchUserEventsFunc = func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+		assert.Equal(t, "deploy", name)
+		assert.Equal(t, uint64(0), options.WaitIndex)
+		assert.Equal(t, defaultWaitTime, options.WaitTime)
+		return []client.UserEvent{{ID: "1", Name: "deploy", LTime: 3}}, 10, nil
+	}
+
+	watcher := NewUserEventWatcher(KVWatcherMockConsul, nil)
+	state := &userEventWatchState{}
+	events, err := watcher.poll("deploy", state)
+
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.True(t, state.initialized)
+	assert.Equal(t, uint64(10), state.index)
+	assert.True(t, state.seen["1/3"])
+}
+
+func TestUserEventWatcherEmitsNewEvents(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchUserEventsFunc = func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+		assert.Equal(t, uint64(10), options.WaitIndex)
+		return []client.UserEvent{
+			{ID: "1", Name: "deploy", LTime: 3},
+			{ID: "2", Name: "deploy", Payload: []byte(`"v1.2"`), LTime: 4},
+			{ID: "2", Name: "deploy", Payload: []byte(`"v1.2"`), LTime: 4},
+			{ID: "3", Name: "deploy", LTime: 5},
+		}, 7, nil
+	}
+
+	watcher := NewUserEventWatcher(KVWatcherMockConsul, nil)
+	state := &userEventWatchState{initialized: true, index: 10, seen: map[string]bool{"1/3": true}}
+	events, err := watcher.poll("deploy", state)
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(events))
+	assert.Equal(t, "ConsulUserEvent", events[0].EventDef.Name)
+	output := events[0].Payload.(UserEventOutput)
+	assert.Equal(t, "deploy", output.Watch)
+	assert.Equal(t, "2", output.Event.ID)
+	assert.Equal(t, `"v1.2"`, string(output.Event.Payload))
+	assert.Equal(t, "3", events[1].Payload.(UserEventOutput).Event.ID)
+	assert.Equal(t, uint64(7), state.index)
+	assert.Equal(t, 3, len(state.seen))
+}
+
+func TestUserEventWatcherEmitsEventWithSameIDAndNewLTime(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchUserEventsFunc = func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+		return []client.UserEvent{{ID: "1", LTime: 8}}, 11, nil
+	}
+
+	watcher := NewUserEventWatcher(KVWatcherMockConsul, nil)
+	state := &userEventWatchState{initialized: true, index: 10, seen: map[string]bool{"1/3": true}}
+	events, err := watcher.poll("", state)
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, uint64(8), events[0].Payload.(UserEventOutput).Event.LTime)
+}
+
+func TestUserEventWatcherPollFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchUserEventsFunc = func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+		return nil, 0, errors.New("kablammo")
+	}
+
+	watcher := NewUserEventWatcher(KVWatcherMockConsul, nil)
+	state := &userEventWatchState{initialized: true, index: 10, seen: map[string]bool{"1/3": true}}
+	_, err := watcher.poll("deploy", state)
+
+	require.NotNil(t, err)
+	assert.Equal(t, uint64(10), state.index)
+	assert.True(t, state.seen["1/3"])
+}
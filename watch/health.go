@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"time"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/HotelsDotCom/go-logger"
+)
+
+const (
+	statusPassing = "passing"
+	statusWarning = "warning"
+)
+
+var (
+	serviceHealthDegradedEventDef  = flyte.EventDef{Name: "ServiceHealthDegraded"}
+	serviceHealthRecoveredEventDef = flyte.EventDef{Name: "ServiceHealthRecovered"}
+)
+
+type healthClient interface {
+	WatchHealthChecks(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error)
+}
+
+//HealthChangeOutput represents the payload of a health state transition event.
+type HealthChangeOutput struct {
+	Node           string `json:"node"`
+	ServiceID      string `json:"serviceId"`
+	ServiceName    string `json:"serviceName"`
+	CheckID        string `json:"checkId"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previousStatus"`
+	Output         string `json:"output"`
+}
+
+//HealthWatcher emits events when health checks transition between states.
+//A transition is only reported once the new state has been stable for the flap window.
+type HealthWatcher struct {
+	consul     healthClient
+	services   []string
+	flapWindow time.Duration
+	waitTime   time.Duration
+	retryWait  time.Duration
+	now        func() time.Time
+}
+
+type healthCheckState struct {
+	check    client.HealthCheck
+	reported string
+	since    time.Time
+}
+
+type healthWatchState struct {
+	initialized bool
+	index       uint64
+	checks      map[string]*healthCheckState
+}
+
+//NewHealthWatcher produces a new health watcher. An empty service name watches all checks.
+func NewHealthWatcher(consul healthClient, services []string, flapWindow time.Duration) *HealthWatcher {
+	return &HealthWatcher{
+		consul:     consul,
+		services:   services,
+		flapWindow: flapWindow,
+		waitTime:   defaultWaitTime,
+		retryWait:  defaultRetryWait,
+		now:        time.Now,
+	}
+}
+
+//HealthEventDefs are the events emitted by the health watcher.
+func HealthEventDefs() []flyte.EventDef {
+	return []flyte.EventDef{
+		serviceHealthDegradedEventDef,
+		serviceHealthRecoveredEventDef,
+	}
+}
+
+//Start watches the health of every configured service in the background.
+func (w *HealthWatcher) Start(sender EventSender) {
+	for _, service := range w.services {
+		logger.Infof("watching health of service %q", service)
+		go w.run(service, sender)
+	}
+}
+
+func (w *HealthWatcher) run(service string, sender EventSender) {
+	state := &healthWatchState{}
+	for {
+		events, err := w.poll(service, state)
+		if nil != err {
+			logger.Errorf("failed to watch health of service %q: %v", service, err)
+			time.Sleep(w.retryWait)
+			continue
+		}
+		for _, event := range events {
+			if err := sender.SendEvent(event); nil != err {
+				logger.Errorf("failed to send %v event: %v", event.EventDef.Name, err)
+			}
+		}
+	}
+}
+
+func (w *HealthWatcher) poll(service string, state *healthWatchState) ([]flyte.Event, error) {
+	// pending transitions have to be re-evaluated once the flap window has passed,
+	// even if nothing changes in consul
+	waitTime := w.waitTime
+	if state.hasPending() && w.flapWindow < waitTime {
+		waitTime = w.flapWindow
+	}
+
+	options := client.QueryOptions{
+		WaitIndex: state.index,
+		WaitTime:  waitTime,
+	}
+	checks, index, err := w.consul.WatchHealthChecks(service, options)
+	if nil != err {
+		return nil, err
+	}
+
+	now := w.now()
+	current := make(map[string]*healthCheckState, len(checks))
+	for _, check := range checks {
+		key := check.Node + "/" + check.CheckID
+		checkState, ok := state.checks[key]
+		if !ok {
+			checkState = &healthCheckState{reported: check.Status, since: now}
+		} else if checkState.check.Status != check.Status {
+			checkState.since = now
+		}
+		checkState.check = check
+		current[key] = checkState
+	}
+
+	events := []flyte.Event{}
+	if state.initialized {
+		for _, checkState := range current {
+			if checkState.check.Status == checkState.reported || now.Sub(checkState.since) < w.flapWindow {
+				continue
+			}
+			if event, ok := newHealthChangeEvent(checkState.check, checkState.reported); ok {
+				events = append(events, event)
+			}
+			checkState.reported = checkState.check.Status
+		}
+	}
+
+	if index < state.index {
+		index = 0
+	}
+	state.initialized = true
+	state.index = index
+	state.checks = current
+	return events, nil
+}
+
+func (s *healthWatchState) hasPending() bool {
+	for _, checkState := range s.checks {
+		if checkState.check.Status != checkState.reported {
+			return true
+		}
+	}
+	return false
+}
+
+func newHealthChangeEvent(check client.HealthCheck, previousStatus string) (flyte.Event, bool) {
+	var eventDef flyte.EventDef
+	switch {
+	case statusPassing == check.Status:
+		eventDef = serviceHealthRecoveredEventDef
+	case severity(check.Status) > severity(previousStatus):
+		eventDef = serviceHealthDegradedEventDef
+	default:
+		return flyte.Event{}, false
+	}
+
+	return flyte.Event{
+		EventDef: eventDef,
+		Payload: HealthChangeOutput{
+			Node:           check.Node,
+			ServiceID:      check.ServiceID,
+			ServiceName:    check.ServiceName,
+			CheckID:        check.CheckID,
+			Name:           check.Name,
+			Status:         check.Status,
+			PreviousStatus: previousStatus,
+			Output:         check.Output,
+		},
+	}, true
+}
+
+func severity(status string) int {
+	switch status {
+	case statusPassing:
+		return 0
+	case statusWarning:
+		return 1
+	default:
+		return 2
+	}
+}
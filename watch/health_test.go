@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthEventDefs(t *testing.T) {
+	eventDefs := HealthEventDefs()
+
+	require.Equal(t, 2, len(eventDefs))
+	assert.Equal(t, "ServiceHealthDegraded", eventDefs[0].Name)
+	assert.Equal(t, "ServiceHealthRecovered", eventDefs[1].Name)
+}
+
+func TestHealthWatcherEmitsDegradedAndRecovered(t *testing.T) {
+	Before()
+	defer After()
+
+	status := "passing"
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		assert.Equal(t, "web", service)
+		return []client.HealthCheck{getHealthCheck(status)}, options.WaitIndex + 1, nil
+	}
+
+	watcher := NewHealthWatcher(KVWatcherMockConsul, nil, 0)
+	state := &healthWatchState{}
+
+	events, err := watcher.poll("web", state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+
+	status = "critical"
+	events, err = watcher.poll("web", state)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "ServiceHealthDegraded", events[0].EventDef.Name)
+	assert.Equal(t, HealthChangeOutput{
+		Node:           "node-1",
+		ServiceID:      "web-1",
+		ServiceName:    "web",
+		CheckID:        "web-check",
+		Name:           "web check",
+		Status:         "critical",
+		PreviousStatus: "passing",
+		Output:         "output",
+	}, events[0].Payload)
+
+	status = "passing"
+	events, err = watcher.poll("web", state)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "ServiceHealthRecovered", events[0].EventDef.Name)
+	assert.Equal(t, "critical", events[0].Payload.(HealthChangeOutput).PreviousStatus)
+}
+
+func TestHealthWatcherSuppressesFlapping(t *testing.T) {
+	Before()
+	defer After()
+
+	now := time.Now()
+	status := "passing"
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		return []client.HealthCheck{getHealthCheck(status)}, options.WaitIndex + 1, nil
+	}
+
+	watcher := NewHealthWatcher(KVWatcherMockConsul, nil, time.Minute)
+	watcher.now = func() time.Time { return now }
+	state := &healthWatchState{}
+
+	_, err := watcher.poll("web", state)
+	require.Nil(t, err)
+
+	status = "critical"
+	events, err := watcher.poll("web", state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.True(t, state.hasPending())
+
+	now = now.Add(10 * time.Second)
+	status = "passing"
+	events, err = watcher.poll("web", state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.False(t, state.hasPending())
+}
+
+func TestHealthWatcherEmitsAfterFlapWindow(t *testing.T) {
+	Before()
+	defer After()
+
+	now := time.Now()
+	status := "passing"
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		return []client.HealthCheck{getHealthCheck(status)}, 1, nil
+	}
+
+	watcher := NewHealthWatcher(KVWatcherMockConsul, nil, time.Minute)
+	watcher.now = func() time.Time { return now }
+	state := &healthWatchState{}
+
+	_, err := watcher.poll("web", state)
+	require.Nil(t, err)
+
+	status = "warning"
+	events, err := watcher.poll("web", state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		assert.Equal(t, time.Minute, options.WaitTime)
+		return []client.HealthCheck{getHealthCheck(status)}, 1, nil
+	}
+	now = now.Add(time.Minute)
+	events, err = watcher.poll("web", state)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "ServiceHealthDegraded", events[0].EventDef.Name)
+	assert.Equal(t, "warning", events[0].Payload.(HealthChangeOutput).Status)
+	assert.False(t, state.hasPending())
+}
+
+func TestHealthWatcherIgnoresPartialRecovery(t *testing.T) {
+	Before()
+	defer After()
+
+	status := "critical"
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		return []client.HealthCheck{getHealthCheck(status)}, 1, nil
+	}
+
+	watcher := NewHealthWatcher(KVWatcherMockConsul, nil, 0)
+	state := &healthWatchState{}
+
+	_, err := watcher.poll("", state)
+	require.Nil(t, err)
+
+	status = "warning"
+	events, err := watcher.poll("", state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.False(t, state.hasPending())
+}
+
+func TestHealthWatcherPollFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchHealthChecksFunc = func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+		return nil, 0, errors.New("kablammo")
+	}
+
+	watcher := NewHealthWatcher(KVWatcherMockConsul, nil, 0)
+	state := &healthWatchState{index: 5}
+	events, err := watcher.poll("web", state)
+
+	assert.Nil(t, events)
+	require.NotNil(t, err)
+	assert.Equal(t, uint64(5), state.index)
+}
+
+func getHealthCheck(status string) client.HealthCheck {
+	return client.HealthCheck{
+		Node:        "node-1",
+		CheckID:     "web-check",
+		Name:        "web check",
+		Status:      status,
+		Output:      "output",
+		ServiceID:   "web-1",
+		ServiceName: "web",
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/HotelsDotCom/go-logger"
+)
+
+var redactedValue = json.RawMessage(`"[REDACTED]"`)
+
+var (
+	kvKeyChangedEventDef = flyte.EventDef{Name: "KVKeyChanged"}
+	kvKeyCreatedEventDef = flyte.EventDef{Name: "KVKeyCreated"}
+	kvKeyDeletedEventDef = flyte.EventDef{Name: "KVKeyDeleted"}
+)
+
+type kvClient interface {
+	WatchKV(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error)
+}
+
+//KVWatch represents a watched key or, when Recurse is set, a watched prefix.
+type KVWatch struct {
+	Key     string
+	Recurse bool
+}
+
+//KVChangeOutput represents the payload of a key change event.
+type KVChangeOutput struct {
+	Watch string         `json:"watch"`
+	Key   string         `json:"key"`
+	Old   *client.KVPair `json:"old,omitempty"`
+	New   *client.KVPair `json:"new,omitempty"`
+}
+
+//KVWatcher emits events when watched keys are created, changed or deleted.
+//Values of keys matching one of the redaction patterns are redacted from the events.
+type KVWatcher struct {
+	consul     kvClient
+	watches    []KVWatch
+	redactKeys []*regexp.Regexp
+	waitTime   time.Duration
+	retryWait  time.Duration
+}
+
+type kvWatchState struct {
+	initialized bool
+	index       uint64
+	pairs       map[string]client.KVPair
+}
+
+//NewKVWatcher produces a new key-value watcher.
+func NewKVWatcher(consul kvClient, watches []KVWatch, redactKeys []*regexp.Regexp) *KVWatcher {
+	return &KVWatcher{
+		consul:     consul,
+		watches:    watches,
+		redactKeys: redactKeys,
+		waitTime:   defaultWaitTime,
+		retryWait:  defaultRetryWait,
+	}
+}
+
+//KVEventDefs are the events emitted by the key-value watcher.
+func KVEventDefs() []flyte.EventDef {
+	return []flyte.EventDef{
+		kvKeyChangedEventDef,
+		kvKeyCreatedEventDef,
+		kvKeyDeletedEventDef,
+	}
+}
+
+//Start watches every configured key in the background.
+func (w *KVWatcher) Start(sender EventSender) {
+	for _, watch := range w.watches {
+		logger.Infof("watching key %v (recurse: %v)", watch.Key, watch.Recurse)
+		go w.run(watch, sender)
+	}
+}
+
+func (w *KVWatcher) run(watch KVWatch, sender EventSender) {
+	state := &kvWatchState{}
+	for {
+		events, err := w.poll(watch, state)
+		if nil != err {
+			logger.Errorf("failed to watch key %v: %v", watch.Key, err)
+			time.Sleep(w.retryWait)
+			continue
+		}
+		for _, event := range events {
+			if err := sender.SendEvent(event); nil != err {
+				logger.Errorf("failed to send %v event: %v", event.EventDef.Name, err)
+			}
+		}
+	}
+}
+
+func (w *KVWatcher) poll(watch KVWatch, state *kvWatchState) ([]flyte.Event, error) {
+	options := client.QueryOptions{
+		WaitIndex: state.index,
+		WaitTime:  w.waitTime,
+	}
+	pairs, index, err := w.consul.WatchKV(watch.Key, watch.Recurse, options)
+	if nil != err {
+		return nil, err
+	}
+
+	current := make(map[string]client.KVPair, len(pairs))
+	for _, pair := range pairs {
+		if 0 != len(pair.Value) && w.isRedacted(pair.Key) {
+			pair.Value = redactedValue
+		}
+		current[pair.Key] = pair
+	}
+
+	var events []flyte.Event
+	if state.initialized {
+		events = diffKV(watch.Key, state.pairs, current)
+	}
+
+	// the index can go backwards (e.g. after a snapshot restore), in which case
+	// the next query has to start over
+	if index < state.index {
+		index = 0
+	}
+	state.initialized = true
+	state.index = index
+	state.pairs = current
+	return events, nil
+}
+
+func (w *KVWatcher) isRedacted(key string) bool {
+	for _, pattern := range w.redactKeys {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func diffKV(watch string, previous, current map[string]client.KVPair) []flyte.Event {
+	events := []flyte.Event{}
+	for key, pair := range current {
+		newValue := pair
+		oldValue, ok := previous[key]
+		if !ok {
+			events = append(events, newKVChangeEvent(kvKeyCreatedEventDef, watch, key, nil, &newValue))
+			continue
+		}
+		if oldValue.ModifyIndex != newValue.ModifyIndex {
+			events = append(events, newKVChangeEvent(kvKeyChangedEventDef, watch, key, &oldValue, &newValue))
+		}
+	}
+	for key, pair := range previous {
+		if _, ok := current[key]; !ok {
+			oldValue := pair
+			events = append(events, newKVChangeEvent(kvKeyDeletedEventDef, watch, key, &oldValue, nil))
+		}
+	}
+	return events
+}
+
+func newKVChangeEvent(eventDef flyte.EventDef, watch, key string, oldValue, newValue *client.KVPair) flyte.Event {
+	return flyte.Event{
+		EventDef: eventDef,
+		Payload: KVChangeOutput{
+			Watch: watch,
+			Key:   key,
+			Old:   oldValue,
+			New:   newValue,
+		},
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"testing"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/HotelsDotCom/go-logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var KVWatcherMockConsul *MockConsul
+
+func Before() {
+	KVWatcherMockConsul = &MockConsul{}
+	loggertest.Init("DEBUG")
+}
+
+func After() {
+	loggertest.Reset()
+}
+
+func TestKVEventDefs(t *testing.T) {
+	eventDefs := KVEventDefs()
+
+	require.Equal(t, 3, len(eventDefs))
+	assert.Equal(t, "KVKeyChanged", eventDefs[0].Name)
+	assert.Equal(t, "KVKeyCreated", eventDefs[1].Name)
+	assert.Equal(t, "KVKeyDeleted", eventDefs[2].Name)
+}
+
+func TestKVWatcherFirstPollEmitsNoEvents(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		assert.Equal(t, "joe/", key)
+		assert.True(t, recurse)
+		assert.Equal(t, uint64(0), options.WaitIndex)
+		assert.Equal(t, defaultWaitTime, options.WaitTime)
+		return []client.KVPair{{Key: "joe/mama", ModifyIndex: 1}}, 10, nil
+	}
+
+	watcher := NewKVWatcher(KVWatcherMockConsul, nil, nil)
+	state := &kvWatchState{}
+	events, err := watcher.poll(KVWatch{Key: "joe/", Recurse: true}, state)
+
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	assert.True(t, state.initialized)
+	assert.Equal(t, uint64(10), state.index)
+	assert.Equal(t, 1, len(state.pairs))
+}
+
+func TestKVWatcherEmitsChanges(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		assert.Equal(t, uint64(10), options.WaitIndex)
+		return []client.KVPair{
+			{Key: "joe/mama", Value: []byte(`"new"`), ModifyIndex: 11},
+			{Key: "joe/papa", ModifyIndex: 11},
+			{Key: "joe/sis", ModifyIndex: 3},
+		}, 11, nil
+	}
+
+	watcher := NewKVWatcher(KVWatcherMockConsul, nil, nil)
+	state := &kvWatchState{
+		initialized: true,
+		index:       10,
+		pairs: map[string]client.KVPair{
+			"joe/mama": {Key: "joe/mama", Value: []byte(`"old"`), ModifyIndex: 1},
+			"joe/bro":  {Key: "joe/bro", ModifyIndex: 2},
+			"joe/sis":  {Key: "joe/sis", ModifyIndex: 3},
+		},
+	}
+	events, err := watcher.poll(KVWatch{Key: "joe/", Recurse: true}, state)
+
+	require.Nil(t, err)
+	require.Equal(t, 3, len(events))
+	sort.Slice(events, func(i, j int) bool { return events[i].EventDef.Name < events[j].EventDef.Name })
+
+	changed := events[0].Payload.(KVChangeOutput)
+	assert.Equal(t, "KVKeyChanged", events[0].EventDef.Name)
+	assert.Equal(t, "joe/", changed.Watch)
+	assert.Equal(t, "joe/mama", changed.Key)
+	assert.Equal(t, `"old"`, string(changed.Old.Value))
+	assert.Equal(t, `"new"`, string(changed.New.Value))
+
+	created := events[1].Payload.(KVChangeOutput)
+	assert.Equal(t, "KVKeyCreated", events[1].EventDef.Name)
+	assert.Equal(t, "joe/papa", created.Key)
+	assert.Nil(t, created.Old)
+	assert.NotNil(t, created.New)
+
+	deleted := events[2].Payload.(KVChangeOutput)
+	assert.Equal(t, "KVKeyDeleted", events[2].EventDef.Name)
+	assert.Equal(t, "joe/bro", deleted.Key)
+	assert.NotNil(t, deleted.Old)
+	assert.Nil(t, deleted.New)
+
+	assert.Equal(t, uint64(11), state.index)
+	assert.Equal(t, 3, len(state.pairs))
+}
+
+func TestKVWatcherRedactsMatchingKeys(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return []client.KVPair{
+			{Key: "joe/password", Value: []byte(`"new"`), ModifyIndex: 11},
+			{Key: "joe/name", Value: []byte(`"joe"`), ModifyIndex: 11},
+		}, 11, nil
+	}
+
+	watcher := NewKVWatcher(KVWatcherMockConsul, nil, []*regexp.Regexp{regexp.MustCompile("password$")})
+	state := &kvWatchState{initialized: true, index: 10, pairs: map[string]client.KVPair{}}
+	events, err := watcher.poll(KVWatch{Key: "joe/", Recurse: true}, state)
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(events))
+	values := map[string]string{}
+	for _, event := range events {
+		output := event.Payload.(KVChangeOutput)
+		values[output.Key] = string(output.New.Value)
+	}
+	assert.Equal(t, `"[REDACTED]"`, values["joe/password"])
+	assert.Equal(t, `"joe"`, values["joe/name"])
+}
+
+func TestKVWatcherResetsIndexWhenItGoesBackwards(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return []client.KVPair{}, 5, nil
+	}
+
+	watcher := NewKVWatcher(KVWatcherMockConsul, nil, nil)
+	state := &kvWatchState{initialized: true, index: 10}
+	_, err := watcher.poll(KVWatch{Key: "joe/mama"}, state)
+
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), state.index)
+}
+
+func TestKVWatcherPollFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return nil, 0, errors.New("kablammo")
+	}
+
+	watcher := NewKVWatcher(KVWatcherMockConsul, nil, nil)
+	state := &kvWatchState{initialized: true, index: 10}
+	events, err := watcher.poll(KVWatch{Key: "joe/mama"}, state)
+
+	assert.Nil(t, events)
+	require.NotNil(t, err)
+	assert.Equal(t, "kablammo", err.Error())
+	assert.Equal(t, uint64(10), state.index)
+}
+
+type MockConsul struct {
+	WatchKVFunc           func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error)
+	WatchHealthChecksFunc func(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error)
+	WatchUserEventsFunc   func(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error)
+}
+
+func (m *MockConsul) WatchKV(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+	return m.WatchKVFunc(key, recurse, options)
+}
+
+func (m *MockConsul) WatchHealthChecks(service string, options client.QueryOptions) ([]client.HealthCheck, uint64, error) {
+	return m.WatchHealthChecksFunc(service, options)
+}
+
+func (m *MockConsul) WatchUserEvents(name string, options client.QueryOptions) ([]client.UserEvent, uint64, error) {
+	return m.WatchUserEventsFunc(name, options)
+}
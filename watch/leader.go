@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"time"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/HotelsDotCom/go-logger"
+)
+
+var (
+	leaderElectedEventDef = flyte.EventDef{Name: "LeaderElected"}
+	leaderLostEventDef    = flyte.EventDef{Name: "LeaderLost"}
+)
+
+//LeaderChangeOutput represents the payload of a leadership change event.
+//Leader is the key as locked by the elected, or previously elected, leader's session.
+type LeaderChangeOutput struct {
+	Key    string        `json:"key"`
+	Leader client.KVPair `json:"leader"`
+}
+
+//LeaderWatcher emits events when leadership keys are locked or unlocked.
+type LeaderWatcher struct {
+	consul    kvClient
+	keys      []string
+	waitTime  time.Duration
+	retryWait time.Duration
+}
+
+type leaderWatchState struct {
+	initialized bool
+	index       uint64
+	leader      *client.KVPair
+}
+
+//NewLeaderWatcher produces a new leadership watcher.
+func NewLeaderWatcher(consul kvClient, keys []string) *LeaderWatcher {
+	return &LeaderWatcher{
+		consul:    consul,
+		keys:      keys,
+		waitTime:  defaultWaitTime,
+		retryWait: defaultRetryWait,
+	}
+}
+
+//LeaderEventDefs are the events emitted by the leadership watcher.
+func LeaderEventDefs() []flyte.EventDef {
+	return []flyte.EventDef{
+		leaderElectedEventDef,
+		leaderLostEventDef,
+	}
+}
+
+//Start watches every configured leadership key in the background.
+func (w *LeaderWatcher) Start(sender EventSender) {
+	for _, key := range w.keys {
+		logger.Infof("watching leadership key %v", key)
+		go w.run(key, sender)
+	}
+}
+
+func (w *LeaderWatcher) run(key string, sender EventSender) {
+	state := &leaderWatchState{}
+	for {
+		events, err := w.poll(key, state)
+		if nil != err {
+			logger.Errorf("failed to watch leadership key %v: %v", key, err)
+			time.Sleep(w.retryWait)
+			continue
+		}
+		for _, event := range events {
+			if err := sender.SendEvent(event); nil != err {
+				logger.Errorf("failed to send %v event: %v", event.EventDef.Name, err)
+			}
+		}
+	}
+}
+
+func (w *LeaderWatcher) poll(key string, state *leaderWatchState) ([]flyte.Event, error) {
+	options := client.QueryOptions{
+		WaitIndex: state.index,
+		WaitTime:  w.waitTime,
+	}
+	pairs, index, err := w.consul.WatchKV(key, false, options)
+	if nil != err {
+		return nil, err
+	}
+
+	var leader *client.KVPair
+	for _, pair := range pairs {
+		if key == pair.Key && "" != pair.Session {
+			current := pair
+			leader = &current
+		}
+	}
+
+	events := []flyte.Event{}
+	if state.initialized && !sameLeader(state.leader, leader) {
+		if nil != state.leader {
+			events = append(events, newLeaderChangeEvent(leaderLostEventDef, key, *state.leader))
+		}
+		if nil != leader {
+			events = append(events, newLeaderChangeEvent(leaderElectedEventDef, key, *leader))
+		}
+	}
+
+	// see KVWatcher.poll
+	if index < state.index {
+		index = 0
+	}
+	state.initialized = true
+	state.index = index
+	state.leader = leader
+	return events, nil
+}
+
+//sameLeader reports whether both pairs describe the same leadership term,
+//a session re-acquiring the key after releasing it starts a new term.
+func sameLeader(previous, current *client.KVPair) bool {
+	if nil == previous || nil == current {
+		return previous == current
+	}
+	return previous.Session == current.Session && previous.LockIndex == current.LockIndex
+}
+
+func newLeaderChangeEvent(eventDef flyte.EventDef, key string, leader client.KVPair) flyte.Event {
+	return flyte.Event{
+		EventDef: eventDef,
+		Payload: LeaderChangeOutput{
+			Key:    key,
+			Leader: leader,
+		},
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"testing"
+
+	client "github.com/ExpediaGroup/flyte-consul/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderEventDefs(t *testing.T) {
+	eventDefs := LeaderEventDefs()
+
+	require.Equal(t, 2, len(eventDefs))
+	assert.Equal(t, "LeaderElected", eventDefs[0].Name)
+	assert.Equal(t, "LeaderLost", eventDefs[1].Name)
+}
+
+func TestLeaderWatcherFirstPollEmitsNoEvents(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		assert.Equal(t, "service/web/leader", key)
+		assert.False(t, recurse)
+		return []client.KVPair{{Key: key, Session: "a", LockIndex: 1}}, 10, nil
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{}
+	events, err := watcher.poll("service/web/leader", state)
+
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+	require.NotNil(t, state.leader)
+	assert.Equal(t, "a", state.leader.Session)
+}
+
+func TestLeaderWatcherEmitsLeaderElected(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		assert.Equal(t, uint64(10), options.WaitIndex)
+		return []client.KVPair{{Key: key, Value: []byte(`"node-1"`), Session: "a", LockIndex: 1}}, 11, nil
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{initialized: true, index: 10}
+	events, err := watcher.poll("service/web/leader", state)
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "LeaderElected", events[0].EventDef.Name)
+	output := events[0].Payload.(LeaderChangeOutput)
+	assert.Equal(t, "service/web/leader", output.Key)
+	assert.Equal(t, `"node-1"`, string(output.Leader.Value))
+}
+
+func TestLeaderWatcherEmitsLeaderLost(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return []client.KVPair{{Key: key, LockIndex: 1}}, 11, nil
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{initialized: true, index: 10, leader: &client.KVPair{Key: "service/web/leader", Session: "a", LockIndex: 1}}
+	events, err := watcher.poll("service/web/leader", state)
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "LeaderLost", events[0].EventDef.Name)
+	assert.Equal(t, "a", events[0].Payload.(LeaderChangeOutput).Leader.Session)
+	assert.Nil(t, state.leader)
+}
+
+func TestLeaderWatcherEmitsLeaderLostAndElectedOnHandOver(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return []client.KVPair{{Key: key, Session: "b", LockIndex: 2}}, 11, nil
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{initialized: true, index: 10, leader: &client.KVPair{Key: "service/web/leader", Session: "a", LockIndex: 1}}
+	events, err := watcher.poll("service/web/leader", state)
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(events))
+	assert.Equal(t, "LeaderLost", events[0].EventDef.Name)
+	assert.Equal(t, "a", events[0].Payload.(LeaderChangeOutput).Leader.Session)
+	assert.Equal(t, "LeaderElected", events[1].EventDef.Name)
+	assert.Equal(t, "b", events[1].Payload.(LeaderChangeOutput).Leader.Session)
+}
+
+func TestLeaderWatcherIgnoresValueChanges(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return []client.KVPair{{Key: key, Value: []byte(`"new"`), Session: "a", LockIndex: 1, ModifyIndex: 11}}, 11, nil
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{initialized: true, index: 10, leader: &client.KVPair{Key: "service/web/leader", Session: "a", LockIndex: 1}}
+	events, err := watcher.poll("service/web/leader", state)
+
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestLeaderWatcherPollFailed(t *testing.T) {
+	Before()
+	defer After()
+
+	KVWatcherMockConsul.WatchKVFunc = func(key string, recurse bool, options client.QueryOptions) ([]client.KVPair, uint64, error) {
+		return nil, 0, errors.New("kablammo")
+	}
+
+	watcher := NewLeaderWatcher(KVWatcherMockConsul, nil)
+	state := &leaderWatchState{initialized: true, index: 10}
+	_, err := watcher.poll("service/web/leader", state)
+
+	require.NotNil(t, err)
+	assert.Equal(t, uint64(10), state.index)
+}
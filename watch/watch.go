@@ -0,0 +1,31 @@
+/*
+Copyright (C) 2020 Expedia, Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//Package watch produces flyte events from consul blocking queries.
+package watch
+
+import (
+	"time"
+
+	"github.com/ExpediaGroup/flyte-client/flyte"
+)
+
+const (
+	defaultWaitTime  = 5 * time.Minute
+	defaultRetryWait = 10 * time.Second
+)
+
+//EventSender sends observed events to flyte.
+type EventSender interface {
+	SendEvent(flyte.Event) error
+}